@@ -1,205 +1,138 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"keuangan-pribadi/models"
-	"keuangan-pribadi/utils"
-	"log"
+	"keuangan-pribadi/logging"
+	"log/slog"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-func init() {
-	InitDB()
-}
-
-var (
-	DB *gorm.DB
-	DB_USERNAME string = utils.GetConfig("DB_USERNAME")
-	DB_PASSWORD string = utils.GetConfig("DB_PASSWORD")
-	DB_NAME     string = utils.GetConfig("DB_NAME")
-	DB_HOST     string = utils.GetConfig("DB_HOST")
-	DB_PORT     string = utils.GetConfig("DB_PORT")
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
-// connect to the database
-func InitDB() {
-	var err error
-
-	var dsn string = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		DB_USERNAME,
-		DB_PASSWORD,
-		DB_HOST,
-		DB_PORT,
-		DB_NAME,
-	)
-
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-
-	if err != nil {
-		log.Fatalf("error when creating a connection to the database: %s\n", err)
-	}
-
-	log.Println("connected to the database")
-	InitMigrate()
-}
-
-func InitMigrate() {
-	DB.AutoMigrate(&models.User{}, &models.Category{}, &models.Finance{}, &models.Saving{}, &models.DetailSaving{})
+// InitDB opens the connection to the configured database. Nothing is
+// opened when the package is imported, the caller owns the connection.
+func InitDB(cfg DatabaseConfig) (*gorm.DB, error) {
+	var dsn string
+
+	switch cfg.Driver {
+	case DriverMySQL:
+		dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.Username,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.Name,
+		)
+	case DriverPostgres:
+		dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host,
+			cfg.Port,
+			cfg.Username,
+			cfg.Password,
+			cfg.Name,
+			cfg.SSLMode,
+		)
+	case DriverSQLite:
+		// the name is the path of the database file
+		dsn = cfg.Name
+	}
+
+	return OpenDB(cfg.Driver, dsn, cfg.SlowQueryThreshold)
 }
 
-func SeedUser() (models.User, error) {
-	password, err := bcrypt.GenerateFromPassword([]byte("testsecret"), bcrypt.DefaultCost)
-	if err != nil {
-		return models.User{}, err
-	}
-
-	var user models.User = models.User{
-		Name: "test",
-		Email: "test@gmail.com",
-		Password: string(password),
+// OpenDB opens a connection with the given driver and data source name.
+// Queries slower than slowQueryThreshold are logged as warnings.
+func OpenDB(driver, dsn string, slowQueryThreshold time.Duration) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	// unique violations come back as gorm.ErrDuplicatedKey on every driver
+	var gormConfig *gorm.Config = &gorm.Config{
+		TranslateError: true,
+		Logger:         logging.NewGormLogger(slowQueryThreshold),
 	}
 
-	result := DB.Create(&user)
+	switch driver {
+	case DriverMySQL:
+		dialector = mysql.Open(dsn)
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		dialector = sqliteDialector{sqlite.Open(dsn).(*sqlite.Dialector)}
 
-	if err := result.Error; err != nil {
-		return models.User{}, err
+		// SQLite compares timestamps as text, so they are all stored in UTC
+		gormConfig.NowFunc = func() time.Time {
+			return time.Now().UTC()
+		}
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
 	}
 
-	if err := result.Last(&user).Error; err != nil {
-		return models.User{}, err
-	}
-
-	return user, nil
-}
-
-func SeedCategory() (models.Category, error) {
-	var category models.Category = models.Category{
-		Name: "seederform",
-	}
-
-	result := DB.Create(&category)
-
-	if err := result.Error; err != nil {
-		return models.Category{}, err
-	}
-
-	if err := result.Last(&category).Error; err != nil {
-		return models.Category{}, err
-	}
-
-	return category, nil
-}
-
-func SeedFinance() (models.Finance, error) {
-	user, err := SeedUser()
-	if err != nil {
-		return models.Finance{}, err
-	}
+	db, err := gorm.Open(dialector, gormConfig)
 
-	category, err := SeedCategory()
 	if err != nil {
-		return models.Finance{}, err
+		return nil, fmt.Errorf("error when creating a connection to the database: %w", err)
 	}
 
-	var finance models.Finance = models.Finance{
-		Name:       	"test",
-		Type: 			1,
-		Money: 			10000,
-		UserID:  		user.ID,
-		CategoryID:  	category.ID,
-		User:       	user,
-		Category:       category,
-	}
+	if driver == DriverSQLite {
+		// foreign keys are off by default and writes must not run concurrently
+		if err := db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+			return nil, err
+		}
 
-	result := DB.Create(&finance)
+		database, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
 
-	if err := result.Error; err != nil {
-		return models.Finance{}, err
+		database.SetMaxOpenConns(1)
 	}
 
-	if err := result.Last(&finance).Error; err != nil {
-		return models.Finance{}, err
-	}
+	slog.Info("connected to the database", "driver", driver)
 
-	return finance, nil
+	return db, nil
 }
 
-func SeedSaving() (models.Saving, error) {
-	user, err := SeedUser()
-	if err != nil {
-		return models.Saving{}, err
-	}
-
-	var saving models.Saving = models.Saving{
-		Name:       	"test",
-		Value: 			1,
-		Goal: 			10000,
-		UserID:  		user.ID,
-		User:       	user,
-	}
-
-	result := DB.Create(&saving)
-
-	if err := result.Error; err != nil {
-		return models.Saving{}, err
-	}
-
-	if err := result.Last(&saving).Error; err != nil {
-		return models.Saving{}, err
-	}
-
-	return saving, nil
+// sqliteDialector translates the errors of SQLite itself. The driver looks
+// for a *sqlite3.Error while go-sqlite3 returns the error as a value, so a
+// unique violation would never become gorm.ErrDuplicatedKey.
+type sqliteDialector struct {
+	*sqlite.Dialector
 }
 
-func SeedDetailSaving() (models.DetailSaving, error) {
-	user, err := SeedUser()
-	if err != nil {
-		return models.DetailSaving{}, err
-	}
-
-	saving, err := SeedSaving()
-	if err != nil {
-		return models.DetailSaving{}, err
-	}
-
-	var detailSaving models.DetailSaving = models.DetailSaving{
-		Value: 			1,
-		SavingID: 			saving.ID,
-		UserID:  		user.ID,
-		User:       	user,
-		Saving:       	saving,
-	}
-
-	result := DB.Create(&detailSaving)
-
-	if err := result.Error; err != nil {
-		return models.DetailSaving{}, err
-	}
+func (d sqliteDialector) Translate(err error) error {
+	var sqliteErr sqlite3.Error
 
-	if err := result.Last(&detailSaving).Error; err != nil {
-		return models.DetailSaving{}, err
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return gorm.ErrDuplicatedKey
 	}
 
-	return detailSaving, nil
+	return err
 }
 
-func CloseDB() error {
-	database, err := DB.DB()
+func CloseDB(db *gorm.DB) error {
+	database, err := db.DB()
 
 	if err != nil {
-		log.Printf("error when getting the database instance: %v", err)
+		slog.Error("error when getting the database instance", "error", err)
 		return err
 	}
 
 	if err := database.Close(); err != nil {
-		log.Printf("error when closing the database connection: %v", err)
+		slog.Error("error when closing the database connection", "error", err)
 		return err
 	}
 
-	log.Println("database connection is closed")
+	slog.Info("database connection is closed")
 
 	return nil
 }
\ No newline at end of file
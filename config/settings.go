@@ -0,0 +1,351 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"keuangan-pribadi/marketdata"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/utils"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Config is every setting of the application. It is loaded once at startup
+// and the parts a component needs are passed into it.
+type Config struct {
+	Port int `validate:"min=1,max=65535"`
+	// Currency is the currency of amounts sent without one
+	Currency   string
+	Server     ServerConfig
+	Log        LogConfig
+	Database   DatabaseConfig
+	Auth       AuthConfig
+	Login      LoginConfig
+	Mail       MailConfig
+	Jobs       JobsConfig
+	Webhook    WebhookConfig
+	MarketData MarketDataConfig
+}
+
+type ServerConfig struct {
+	// RequestTimeout is the deadline of a request, the database queries it
+	// runs are cancelled once it passes
+	RequestTimeout time.Duration `validate:"min=1s"`
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// before the server stops
+	ShutdownTimeout time.Duration `validate:"min=1s"`
+	// TrustedProxies are the address ranges of the reverse proxies whose
+	// X-Forwarded-For header is believed, without any the client IP address
+	// is the one of the connection
+	TrustedProxies []string `validate:"dive,cidr"`
+}
+
+type LogConfig struct {
+	Level  string `validate:"oneof=debug info warn error"`
+	Format string `validate:"oneof=json text"`
+}
+
+type DatabaseConfig struct {
+	Driver   string `validate:"oneof=mysql postgres sqlite"`
+	Host     string `validate:"required_unless=Driver sqlite"`
+	Port     string `validate:"required_unless=Driver sqlite"`
+	Username string `validate:"required_unless=Driver sqlite"`
+	Password string
+	// Name is the path of the database file for SQLite
+	Name    string `validate:"required"`
+	SSLMode string
+	// SlowQueryThreshold is the duration after which a query is logged as a
+	// warning, 0 turns it off
+	SlowQueryThreshold time.Duration `validate:"min=0"`
+}
+
+type AuthConfig struct {
+	JWTSecret            string        `validate:"required,min=16"`
+	TokenTTL             time.Duration `validate:"min=1m"`
+	EmailVerificationTTL time.Duration `validate:"min=1m"`
+	InvitationTTL        time.Duration `validate:"min=1m"`
+	AdminEmail           string        `validate:"omitempty,email"`
+}
+
+type LoginConfig struct {
+	// MaxAccountAttempts is the number of failed logins for one account
+	// before it gets locked
+	MaxAccountAttempts int `validate:"min=1"`
+	// MaxIPAttempts is the number of failed logins from one IP address
+	// before it gets locked, across all accounts
+	MaxIPAttempts int `validate:"min=1"`
+	// BaseLockout is the first lockout, it doubles with every further
+	// failure up to MaxLockout
+	BaseLockout time.Duration `validate:"min=1s"`
+	MaxLockout  time.Duration `validate:"gtefield=BaseLockout"`
+	// Window is how long failed attempts are remembered
+	Window time.Duration `validate:"min=1m"`
+}
+
+type MailConfig struct {
+	Host     string
+	Port     string `validate:"required_with=Host"`
+	Username string
+	Password string
+	From     string `validate:"required_with=Host"`
+	// LogBody logs the body of the messages that are not sent because no
+	// host is set, for development only
+	LogBody bool
+}
+
+type JobsConfig struct {
+	BillReminderInterval    time.Duration `validate:"min=1s"`
+	WebhookDeliveryInterval time.Duration `validate:"min=1s"`
+}
+
+type WebhookConfig struct {
+	// AllowPrivateTargets lets webhooks reach loopback, private and
+	// link-local addresses, for a receiver on the same network
+	AllowPrivateTargets bool
+}
+
+const (
+	MarketDataAlphaVantage = "alphavantage"
+	MarketDataFake         = "fake"
+)
+
+type MarketDataConfig struct {
+	// Provider is where the prices come from, fake answers fixed prices
+	// without a network
+	Provider string `validate:"oneof=alphavantage fake"`
+	// AlphaVantageAPIKey is the key of https://www.alphavantage.co
+	AlphaVantageAPIKey string
+	// CacheTTL is how long fetched prices are answered before they are
+	// fetched again
+	CacheTTL time.Duration `validate:"min=1s"`
+	// Timeout is the deadline of a call to the provider
+	Timeout time.Duration `validate:"min=1s"`
+}
+
+// Throttle locks out failed logins with the settings, counting them in store.
+func (lc LoginConfig) Throttle(store middleware.RateLimitStore) *middleware.LoginThrottle {
+	return middleware.NewLoginThrottle(store, middleware.LoginThrottleConfig{
+		MaxAccountAttempts: lc.MaxAccountAttempts,
+		MaxIPAttempts:      lc.MaxIPAttempts,
+		BaseLockout:        lc.BaseLockout,
+		MaxLockout:         lc.MaxLockout,
+		Window:             lc.Window,
+	})
+}
+
+// Mailer sends email with the SMTP settings.
+func (mc MailConfig) Mailer() utils.Mailer {
+	return utils.Mailer{
+		Host:     mc.Host,
+		Port:     mc.Port,
+		Username: mc.Username,
+		Password: mc.Password,
+		From:     mc.From,
+		LogBody:  mc.LogBody,
+	}
+}
+
+// IPExtractor finds the client IP address of a request. Rate limits and the
+// login lockout count per address, so a client must not be able to pick its
+// own by sending an X-Forwarded-For header.
+func (sc ServerConfig) IPExtractor() echo.IPExtractor {
+	if len(sc.TrustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+
+	for _, proxy := range sc.TrustedProxies {
+		// the ranges are validated when the settings are loaded
+		if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+			options = append(options, echo.TrustIPRange(ipNet))
+		}
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// NewProvider is the market-data provider of the settings.
+func (mc MarketDataConfig) NewProvider() marketdata.MarketDataProvider {
+	if mc.Provider == MarketDataFake {
+		return marketdata.NewFake()
+	}
+
+	return marketdata.NewAlphaVantage(mc.AlphaVantageAPIKey, mc.Timeout)
+}
+
+// Load reads the settings from the configuration file, the environment and
+// the command line flags, later sources taking precedence, and validates
+// them. The arguments left after the flags are returned.
+func Load(args []string) (Config, []string, error) {
+	v := viper.New()
+
+	v.SetDefault("PORT", 1323)
+	v.SetDefault("CURRENCY", "IDR")
+	v.SetDefault("REQUEST_TIMEOUT", 30*time.Second)
+	v.SetDefault("SHUTDOWN_TIMEOUT", 30*time.Second)
+	v.SetDefault("LOG_LEVEL", "info")
+	v.SetDefault("LOG_FORMAT", "json")
+	v.SetDefault("DB_DRIVER", DriverMySQL)
+	v.SetDefault("DB_SSLMODE", "disable")
+	v.SetDefault("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond)
+	v.SetDefault("JWT_TTL", time.Hour)
+	v.SetDefault("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	v.SetDefault("INVITATION_TTL", 7*24*time.Hour)
+	v.SetDefault("LOGIN_MAX_ACCOUNT_ATTEMPTS", middleware.DefaultLoginThrottleConfig.MaxAccountAttempts)
+	v.SetDefault("LOGIN_MAX_IP_ATTEMPTS", middleware.DefaultLoginThrottleConfig.MaxIPAttempts)
+	v.SetDefault("LOGIN_BASE_LOCKOUT", middleware.DefaultLoginThrottleConfig.BaseLockout)
+	v.SetDefault("LOGIN_MAX_LOCKOUT", middleware.DefaultLoginThrottleConfig.MaxLockout)
+	v.SetDefault("LOGIN_ATTEMPT_WINDOW", middleware.DefaultLoginThrottleConfig.Window)
+	v.SetDefault("BILL_REMINDER_INTERVAL", time.Hour)
+	v.SetDefault("WEBHOOK_DELIVERY_INTERVAL", 10*time.Second)
+	v.SetDefault("MARKET_DATA_PROVIDER", MarketDataAlphaVantage)
+	v.SetDefault("MARKET_DATA_CACHE_TTL", time.Hour)
+	v.SetDefault("MARKET_DATA_TIMEOUT", 10*time.Second)
+
+	flags := pflag.NewFlagSet("keuangan-pribadi", pflag.ContinueOnError)
+	configFile := flags.String("config", ".env", "path of the configuration file")
+	flags.Int("port", 0, "port the HTTP server listens on")
+	flags.String("db-driver", "", "database driver: mysql, postgres or sqlite")
+	flags.String("db-host", "", "database host")
+	flags.String("db-port", "", "database port")
+	flags.String("db-name", "", "database name, the file path for sqlite")
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	for key, flag := range map[string]string{
+		"PORT":      "port",
+		"DB_DRIVER": "db-driver",
+		"DB_HOST":   "db-host",
+		"DB_PORT":   "db-port",
+		"DB_NAME":   "db-name",
+	} {
+		if err := v.BindPFlag(key, flags.Lookup(flag)); err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	v.SetConfigFile(*configFile)
+	v.SetConfigType("env")
+	v.AutomaticEnv()
+
+	// the file is optional unless it was asked for, containers configure
+	// everything through the environment
+	if err := v.ReadInConfig(); err != nil && !(errors.Is(err, fs.ErrNotExist) && !flags.Changed("config")) {
+		return Config{}, nil, fmt.Errorf("error when reading configuration file: %w", err)
+	}
+
+	cfg := Config{
+		Port:     v.GetInt("PORT"),
+		Currency: v.GetString("CURRENCY"),
+		Server: ServerConfig{
+			RequestTimeout:  v.GetDuration("REQUEST_TIMEOUT"),
+			ShutdownTimeout: v.GetDuration("SHUTDOWN_TIMEOUT"),
+			TrustedProxies:  splitList(v.GetString("TRUSTED_PROXIES")),
+		},
+		Log: LogConfig{
+			Level:  v.GetString("LOG_LEVEL"),
+			Format: v.GetString("LOG_FORMAT"),
+		},
+		Database: DatabaseConfig{
+			Driver:             v.GetString("DB_DRIVER"),
+			Host:               v.GetString("DB_HOST"),
+			Port:               v.GetString("DB_PORT"),
+			Username:           v.GetString("DB_USERNAME"),
+			Password:           v.GetString("DB_PASSWORD"),
+			Name:               v.GetString("DB_NAME"),
+			SSLMode:            v.GetString("DB_SSLMODE"),
+			SlowQueryThreshold: v.GetDuration("DB_SLOW_QUERY_THRESHOLD"),
+		},
+		Auth: AuthConfig{
+			JWTSecret:            v.GetString("JWT_SECRET_KEY"),
+			TokenTTL:             v.GetDuration("JWT_TTL"),
+			EmailVerificationTTL: v.GetDuration("EMAIL_VERIFICATION_TTL"),
+			InvitationTTL:        v.GetDuration("INVITATION_TTL"),
+			AdminEmail:           v.GetString("ADMIN_EMAIL"),
+		},
+		Login: LoginConfig{
+			MaxAccountAttempts: v.GetInt("LOGIN_MAX_ACCOUNT_ATTEMPTS"),
+			MaxIPAttempts:      v.GetInt("LOGIN_MAX_IP_ATTEMPTS"),
+			BaseLockout:        v.GetDuration("LOGIN_BASE_LOCKOUT"),
+			MaxLockout:         v.GetDuration("LOGIN_MAX_LOCKOUT"),
+			Window:             v.GetDuration("LOGIN_ATTEMPT_WINDOW"),
+		},
+		Mail: MailConfig{
+			Host:     v.GetString("SMTP_HOST"),
+			Port:     v.GetString("SMTP_PORT"),
+			Username: v.GetString("SMTP_USERNAME"),
+			Password: v.GetString("SMTP_PASSWORD"),
+			From:     v.GetString("MAIL_FROM"),
+			LogBody:  v.GetBool("MAIL_LOG_BODY"),
+		},
+		Jobs: JobsConfig{
+			BillReminderInterval:    v.GetDuration("BILL_REMINDER_INTERVAL"),
+			WebhookDeliveryInterval: v.GetDuration("WEBHOOK_DELIVERY_INTERVAL"),
+		},
+		Webhook: WebhookConfig{
+			AllowPrivateTargets: v.GetBool("WEBHOOK_ALLOW_PRIVATE_TARGETS"),
+		},
+		MarketData: MarketDataConfig{
+			Provider:           v.GetString("MARKET_DATA_PROVIDER"),
+			AlphaVantageAPIKey: v.GetString("ALPHA_VANTAGE_API_KEY"),
+			CacheTTL:           v.GetDuration("MARKET_DATA_CACHE_TTL"),
+			Timeout:            v.GetDuration("MARKET_DATA_TIMEOUT"),
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, nil, err
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// splitList splits a comma separated setting, leaving out empty items.
+func splitList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var messages []error
+
+	if err := validator.New().Struct(c); err != nil {
+		var errs validator.ValidationErrors
+		if !errors.As(err, &errs) {
+			return err
+		}
+
+		for _, fieldErr := range errs {
+			messages = append(messages, fmt.Errorf("invalid setting %s: failed on %s %s", fieldErr.Namespace(), fieldErr.Tag(), fieldErr.Param()))
+		}
+	}
+
+	if _, err := money.LookupCurrency(c.Currency); err != nil {
+		messages = append(messages, fmt.Errorf("invalid setting Config.Currency: %w", err))
+	}
+
+	return errors.Join(messages...)
+}
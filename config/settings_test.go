@@ -0,0 +1,133 @@
+package config_test
+
+import (
+	"keuangan-pribadi/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setEnv sets the settings without a default to valid values.
+func setEnv(t *testing.T) {
+	t.Setenv("DB_DRIVER", config.DriverSQLite)
+	t.Setenv("DB_NAME", "env.db")
+	t.Setenv("JWT_SECRET_KEY", "a-secret-of-sixteen-or-more")
+}
+
+func TestLoad_Environment(t *testing.T) {
+	setEnv(t)
+	t.Setenv("PORT", "8080")
+	t.Setenv("JWT_TTL", "15m")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, ,192.168.0.0/16")
+
+	cfg, args, err := config.Load(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Empty(t, args)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, config.DriverSQLite, cfg.Database.Driver)
+	assert.Equal(t, "env.db", cfg.Database.Name)
+	assert.Equal(t, 15*time.Minute, cfg.Auth.TokenTTL)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.0.0/16"}, cfg.Server.TrustedProxies)
+
+	// the rest are the defaults
+	assert.Equal(t, "IDR", cfg.Currency)
+	assert.Equal(t, "info", cfg.Log.Level)
+	assert.Equal(t, 30*time.Second, cfg.Server.RequestTimeout)
+}
+
+func TestLoad_Flags(t *testing.T) {
+	setEnv(t)
+	t.Setenv("PORT", "8080")
+
+	cfg, args, err := config.Load([]string{"--port", "9090", "--db-name", "flag.db", "migrate", "up"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// flags take precedence over the environment
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "flag.db", cfg.Database.Name)
+	assert.Equal(t, []string{"migrate", "up"}, args)
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	setEnv(t)
+	t.Setenv("PORT", "8080")
+
+	path := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(path, []byte("PORT=7070\nLOG_LEVEL=debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, err := config.Load([]string{"--config", path})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the environment takes precedence over the file
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "debug", cfg.Log.Level)
+}
+
+func TestLoad_Failed(t *testing.T) {
+	setEnv(t)
+
+	// a file that was asked for must exist
+	_, _, err := config.Load([]string{"--config", filepath.Join(t.TempDir(), "missing.env")})
+	assert.ErrorContains(t, err, "configuration file")
+
+	_, _, err = config.Load([]string{"--unknown"})
+	assert.Error(t, err)
+
+	t.Setenv("JWT_SECRET_KEY", "")
+
+	_, _, err = config.Load(nil)
+	assert.ErrorContains(t, err, "Config.Auth.JWTSecret")
+}
+
+func TestValidate_Failed(t *testing.T) {
+	setEnv(t)
+
+	valid, _, err := config.Load(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testcases := []struct {
+		name     string
+		change   func(cfg *config.Config)
+		expected string
+	}{
+		{"currency", func(cfg *config.Config) { cfg.Currency = "XYZ" }, "Config.Currency"},
+		{"driver", func(cfg *config.Config) { cfg.Database.Driver = "oracle" }, "Config.Database.Driver"},
+		{"port", func(cfg *config.Config) { cfg.Port = 70000 }, "Config.Port"},
+		{"database host", func(cfg *config.Config) { cfg.Database.Driver = config.DriverMySQL }, "Config.Database.Host"},
+		{"trusted proxy", func(cfg *config.Config) { cfg.Server.TrustedProxies = []string{"10.0.0.1"} }, "Config.Server.TrustedProxies[0]"},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			cfg := valid
+			testcase.change(&cfg)
+
+			assert.ErrorContains(t, cfg.Validate(), testcase.expected)
+		})
+	}
+
+	assert.NoError(t, valid.Validate())
+
+	// every invalid setting is reported at once
+	invalid := valid
+	invalid.Currency = "XYZ"
+	invalid.Port = 0
+
+	err = invalid.Validate()
+	assert.ErrorContains(t, err, "Config.Currency")
+	assert.ErrorContains(t, err, "Config.Port")
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"github.com/labstack/echo/v4"
+	m "keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+)
+
+type AuditLogController struct {
+	service services.AuditLogService
+}
+
+func InitAuditLogController(service services.AuditLogService) AuditLogController {
+	return AuditLogController{
+		service: service,
+	}
+}
+
+func (ac *AuditLogController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var filter models.AuditLogFilter
+
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &filter); err != nil {
+		return errInvalidRequest
+	}
+
+	auditLogs, err := ac.service.GetAll(c.Request().Context(), filter, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.AuditLog]{
+		Status:  "success",
+		Message: "all audit logs",
+		Data:    auditLogs,
+	})
+}
+
+// auditMeta collects who is making the request and from where, for the
+// audit log entries written by the repositories.
+func auditMeta(c echo.Context) models.AuditMeta {
+	meta := models.AuditMeta{
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+
+	if principal, ok := m.GetPrincipal(c); ok {
+		meta.ActorID = principal.UserID
+	}
+
+	return meta
+}
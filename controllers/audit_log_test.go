@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCaseAuditLog struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var auditLogController AuditLogController
+
+func InitAuditLogEcho() *echo.Echo {
+	db := initTestDB()
+	auditLogController = InitAuditLogController(services.InitAuditLogService(repositories.InitAuditLogRepository(db)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetAllAuditLogs_Success(t *testing.T) {
+	testcase := testCaseAuditLog{
+		name:                   "success",
+		path:                   "/api/v1/audit?entity_type=finance",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitAuditLogEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, auditLogController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetAllAuditLogs_Failed(t *testing.T) {
+	testcase := testCaseAuditLog{
+		name:                   "failed",
+		path:                   "/api/v1/audit",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitAuditLogEcho()
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", "")
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, auditLogController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	m "keuangan-pribadi/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// currentUserID is the ID of the user authenticated by the Authenticate
+// middleware.
+func currentUserID(c echo.Context) (uint, error) {
+	principal, ok := m.GetPrincipal(c)
+	if !ok {
+		return 0, m.ErrMissingToken
+	}
+
+	return principal.UserID, nil
+}
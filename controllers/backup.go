@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"fmt"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BackupController struct {
+	service services.BackupService
+}
+
+func InitBackupController(service services.BackupService) BackupController {
+	return BackupController{
+		service: service,
+	}
+}
+
+func (bc *BackupController) Create(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	backup, err := bc.service.Create(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("keuangan-pribadi-backup-%s.json", time.Now().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	return c.JSON(http.StatusOK, backup)
+}
+
+func (bc *BackupController) Restore(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = models.RestoreModeMerge
+	}
+
+	var backup models.Backup
+
+	if err := c.Bind(&backup); err != nil {
+		return errInvalidRequest
+	}
+
+	result, err := bc.service.Restore(c.Request().Context(), backup, mode, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.RestoreResult]{
+		Status:  "success",
+		Message: "backup restored",
+		Data:    result,
+	})
+}
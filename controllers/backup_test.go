@@ -0,0 +1,373 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCaseBackup struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var backupController BackupController
+
+func InitBackupEcho() *echo.Echo {
+	db := initTestDB()
+	backupController = InitBackupController(services.InitBackupService(repositories.InitBackupRepository(db)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestCreateBackup_Success(t *testing.T) {
+	testcase := testCaseBackup{
+		name:                   "success",
+		path:                   "/api/v1/backups",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"version\":",
+	}
+
+	e := InitBackupEcho()
+
+	finance, _ := testutil.SeedFinance(testDB)
+	token, _ := middleware.CreateToken(finance.User.ID, finance.User.Name, finance.User.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, backupController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateBackup_Failed(t *testing.T) {
+	testcase := testCaseBackup{
+		name:                   "failed",
+		path:                   "/api/v1/backups",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBackupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", "")
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, backupController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestRestoreBackup_Success(t *testing.T) {
+	testcase := testCaseBackup{
+		name:                   "success",
+		path:                   "/api/v1/backups/restore?mode=replace",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBackupEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	backup := models.Backup{
+		Version:       models.BackupVersion,
+		CreatedAt:     time.Now(),
+		Categories:    []models.BackupCategory{{ID: 10, Name: "seederform"}},
+		Finances:      []models.BackupFinance{{ID: 20, Name: "test", Type: 1, Money: money.New(1000000, "IDR"), CategoryID: 10}},
+		Savings:       []models.BackupSaving{{ID: 30, Name: "test", Value: money.New(100, "IDR"), Goal: money.New(1000000, "IDR")}},
+		DetailSavings: []models.BackupDetailSaving{{ID: 40, SavingID: 30, Value: money.New(100, "IDR"), Status: 1}},
+	}
+
+	jsonBody, _ := json.Marshal(&backup)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, backupController.Restore)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestRestoreBackup_Failed(t *testing.T) {
+	testcase := testCaseBackup{
+		name:                   "failed",
+		path:                   "/api/v1/backups/restore",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBackupEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	backup := models.Backup{
+		Version:  models.BackupVersion + 1,
+		Finances: []models.BackupFinance{{ID: 20, Name: "test", Type: 1, Money: money.New(1000000, "IDR"), CategoryID: 99}},
+	}
+
+	jsonBody, _ := json.Marshal(&backup)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, backupController.Restore)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestRestoreBackup_SharedLedger(t *testing.T) {
+	InitBackupEcho()
+
+	household, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	member := addLedgerMember(t, household.ID, models.LedgerRoleEditor)
+	personal, err := repositories.PersonalLedger(testDB, member.ID)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	seedLedgerFinance(t, member.ID, personal.ID, "salary")
+	seedLedgerFinance(t, member.ID, household.ID, "groceries")
+
+	// a backup holds the personal ledger only
+	recorder := request(t, backupController.Create, http.MethodGet, "/api/v1/backups", "", bearer(member.ID))
+
+	var backup models.Backup
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &backup)) && assert.Len(t, backup.Finances, 1) {
+		assert.Equal(t, "salary", backup.Finances[0].Name)
+	}
+
+	backup.Finances[0].Name = "restored salary"
+	body, _ := json.Marshal(backup)
+
+	recorder = request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore?mode=replace", string(body), bearer(member.ID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// replacing the personal ledger leaves the household alone
+	assert.Equal(t, []string{"restored salary"}, ledgerFinances(t, personal.ID))
+	assert.Equal(t, []string{"groceries"}, ledgerFinances(t, household.ID))
+}
+
+func TestRestoreBackup_DebtRepayments(t *testing.T) {
+	InitBackupEcho()
+	InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	// the repayment is booked as a finance the restore has to replace
+	recorder := request(t, debtController.Repay, http.MethodPost, "/api/v1/debts/:id/repayments", fmt.Sprintf(`{"amount":{"amount":"25000","currency":"IDR"},"category_id":%d}`, category.ID), bearer(debt.UserID), "id", fmt.Sprint(debt.ID))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+	recorder = request(t, backupController.Create, http.MethodGet, "/api/v1/backups", "", bearer(debt.UserID))
+
+	var backup models.Backup
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &backup)) {
+		assert.Len(t, backup.Debts, 1)
+		assert.Len(t, backup.DebtRepayments, 1)
+	}
+
+	recorder = request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore?mode=replace", recorder.Body.String(), bearer(debt.UserID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response models.Response[models.RestoreResult]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, 1, response.Data.Finances)
+		assert.Equal(t, 1, response.Data.Debts)
+		assert.Equal(t, 1, response.Data.DebtRepayments)
+	}
+
+	// the restored repayment points at the restored finance
+	var debts []models.Debt
+	testDB.Preload("Repayments.Finance").Where("ledger_id = ?", debt.LedgerID).Find(&debts)
+
+	if assert.Len(t, debts, 1) && assert.Len(t, debts[0].Repayments, 1) {
+		assert.Equal(t, "25000.00", debts[0].Paid.Decimal())
+		assert.Equal(t, "25000.00", debts[0].Repayments[0].Finance.Money.Decimal())
+		assert.Equal(t, *debt.LedgerID, *debts[0].Repayments[0].Finance.LedgerID)
+	}
+}
+
+func TestRestoreBackup_Bills(t *testing.T) {
+	InitBackupEcho()
+
+	bill, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	testDB.Model(&bill).Update("active", false)
+
+	recorder := request(t, backupController.Create, http.MethodGet, "/api/v1/backups", "", bearer(bill.UserID))
+
+	var backup models.Backup
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &backup)) && assert.Len(t, backup.Bills, 1) {
+		assert.Equal(t, "electricity", backup.Bills[0].Name)
+		assert.False(t, backup.Bills[0].Active)
+		assert.Equal(t, bill.CategoryID, backup.Bills[0].CategoryID)
+		assert.Len(t, backup.Categories, 1)
+	}
+
+	ledgerBills := func() []models.Bill {
+		var bills []models.Bill
+		testDB.Preload("Category").Where("ledger_id = ?", bill.LedgerID).Order("id").Find(&bills)
+
+		return bills
+	}
+
+	// replace swaps the bill for the restored one
+	recorder = request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore?mode=replace", recorder.Body.String(), bearer(bill.UserID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response models.Response[models.RestoreResult]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, 1, response.Data.Bills)
+	}
+
+	if bills := ledgerBills(); assert.Len(t, bills, 1) {
+		assert.Equal(t, "electricity", bills[0].Name)
+		assert.Equal(t, "350000.00", bills[0].Amount.Decimal())
+		assert.Equal(t, backup.Categories[0].Name, bills[0].Category.Name)
+		assert.False(t, bills[0].Active)
+	}
+
+	// merge keeps it and adds the backup on top
+	body, _ := json.Marshal(backup)
+	recorder = request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore?mode=merge", string(body), bearer(bill.UserID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Len(t, ledgerBills(), 2)
+}
+
+func TestRestoreBackup_GoldSavings(t *testing.T) {
+	InitBackupEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	recorder := request(t, backupController.Create, http.MethodGet, "/api/v1/backups", "", bearer(goldSaving.UserID))
+
+	var backup models.Backup
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &backup)) && assert.Len(t, backup.GoldSavings, 1) && assert.Len(t, backup.GoldDeposits, 1) {
+		assert.Equal(t, "emas", backup.GoldSavings[0].Name)
+		assert.Equal(t, backup.GoldSavings[0].ID, backup.GoldDeposits[0].GoldSavingID)
+		assert.Equal(t, "10.0000", backup.GoldDeposits[0].Grams.String())
+	}
+
+	ledgerGoldSavings := func() []models.GoldSaving {
+		var goldSavings []models.GoldSaving
+		testDB.Preload("Deposits").Where("ledger_id = ?", goldSaving.LedgerID).Order("id").Find(&goldSavings)
+
+		return goldSavings
+	}
+
+	recorder = request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore?mode=replace", recorder.Body.String(), bearer(goldSaving.UserID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response models.Response[models.RestoreResult]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, 1, response.Data.GoldSavings)
+		assert.Equal(t, 1, response.Data.GoldDeposits)
+	}
+
+	// the totals are added up from the restored deposits
+	if goldSavings := ledgerGoldSavings(); assert.Len(t, goldSavings, 1) && assert.Len(t, goldSavings[0].Deposits, 1) {
+		assert.Equal(t, "10.0000", goldSavings[0].Grams.String())
+		assert.Equal(t, "12000000.00", goldSavings[0].Cost.Decimal())
+		assert.Equal(t, "IDR", goldSavings[0].Cost.Currency)
+		assert.Equal(t, "100.0000", goldSavings[0].Goal.String())
+	}
+
+	body, _ := json.Marshal(backup)
+	recorder = request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore?mode=merge", string(body), bearer(goldSaving.UserID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Len(t, ledgerGoldSavings(), 2)
+
+	// deposits of one saving are bought in one currency
+	backup.GoldDeposits = append(backup.GoldDeposits, backup.GoldDeposits[0])
+	backup.GoldDeposits[1].ID++
+	backup.GoldDeposits[1].Price = money.New(80000, "USD")
+	body, _ = json.Marshal(backup)
+
+	recorder = request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore?mode=merge", string(body), bearer(goldSaving.UserID))
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\"invalid_backup\""))
+	assert.Len(t, ledgerGoldSavings(), 2)
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"github.com/labstack/echo/v4"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+)
+
+type BillController struct {
+	service services.BillService
+}
+
+func InitBillController(service services.BillService) BillController {
+	return BillController{
+		service: service,
+	}
+}
+
+func (bc *BillController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	bills, err := bc.service.GetAll(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.Bill]{
+		Status:  "success",
+		Message: "all bills",
+		Data:    bills,
+	})
+}
+
+func (bc *BillController) GetByID(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var billID string = c.Param("id")
+
+	bill, err := bc.service.GetByID(c.Request().Context(), billID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Bill]{
+		Status:  "success",
+		Message: "bill found",
+		Data:    bill,
+	})
+}
+
+func (bc *BillController) Create(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var billInput models.BillInput
+
+	if err := c.Bind(&billInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(billInput); err != nil {
+		return validationError(err)
+	}
+
+	bill, err := bc.service.Create(c.Request().Context(), billInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.Bill]{
+		Status:  "success",
+		Message: "bill created",
+		Data:    bill,
+	})
+}
+
+func (bc *BillController) Update(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var billInput models.BillInput
+
+	if err := c.Bind(&billInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(billInput); err != nil {
+		return validationError(err)
+	}
+
+	var billID string = c.Param("id")
+
+	bill, err := bc.service.Update(c.Request().Context(), billInput, billID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Bill]{
+		Status:  "success",
+		Message: "bill updated",
+		Data:    bill,
+	})
+}
+
+func (bc *BillController) Delete(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var billID string = c.Param("id")
+
+	err = bc.service.Delete(c.Request().Context(), billID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "bill deleted",
+	})
+}
+
+func (bc *BillController) Pay(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var paymentInput models.BillPayment
+
+	if err := c.Bind(&paymentInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(paymentInput); err != nil {
+		return validationError(err)
+	}
+
+	var billID string = c.Param("id")
+
+	finance, err := bc.service.Pay(c.Request().Context(), paymentInput, billID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.Finance]{
+		Status:  "success",
+		Message: "bill paid",
+		Data:    finance,
+	})
+}
@@ -0,0 +1,606 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testCaseBill struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var billController BillController
+
+func InitBillEcho() *echo.Echo {
+	db := initTestDB()
+	billController = InitBillController(services.InitBillService(repositories.InitBillRepository(db)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetAllBills_Success(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "success",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, billController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetAllBills_Failed(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "failed",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, billController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetBill_Success(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "success",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	bill, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(bill.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(bill.ID)))
+
+	if assert.NoError(t, handle(ctx, billController.GetByID)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetBill_Failed(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "failed",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, billController.GetByID)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateBill_Success(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "success",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	var billInput models.BillInput = models.BillInput{
+		Name:       "bpjs",
+		Amount:     money.New(15000000, "IDR"),
+		DueDay:     10,
+		Recurrence: models.BillRecurrenceMonthly,
+		LeadDays:   5,
+		CategoryID: category.ID,
+	}
+
+	jsonBody, _ := json.Marshal(&billInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, billController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateBill_Failed(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "failed",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	var billInput models.BillInput = models.BillInput{
+		Name:       "bpjs",
+		Amount:     money.New(15000000, "IDR"),
+		DueDay:     32,
+		Recurrence: models.BillRecurrenceMonthly,
+		CategoryID: category.ID,
+	}
+
+	jsonBody, _ := json.Marshal(&billInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, billController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateBill_Success(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "success",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	bill, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(bill.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	var billInput models.BillInput = models.BillInput{
+		Name:       "bpjs",
+		Amount:     money.New(15000000, "IDR"),
+		DueDay:     10,
+		Recurrence: models.BillRecurrenceMonthly,
+		LeadDays:   5,
+		CategoryID: category.ID,
+	}
+
+	jsonBody, _ := json.Marshal(&billInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(bill.ID)))
+
+	if assert.NoError(t, handle(ctx, billController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateBill_Failed(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "failed",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	bill, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(bill.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	var billInput models.BillInput = models.BillInput{
+		Name:       "bpjs",
+		Amount:     money.New(15000000, "IDR"),
+		DueDay:     32,
+		Recurrence: models.BillRecurrenceMonthly,
+		CategoryID: category.ID,
+	}
+
+	jsonBody, _ := json.Marshal(&billInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(bill.ID)))
+
+	if assert.NoError(t, handle(ctx, billController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteBill_Success(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "success",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	bill, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(bill.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(bill.ID)))
+
+	if assert.NoError(t, handle(ctx, billController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteBill_Failed(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "failed",
+		path:                   "/api/v1/bills",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, billController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestPayBill_Success(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "success",
+		path:                   "/api/v1/bills/pay",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	bill, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(bill.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var paymentInput models.BillPayment = models.BillPayment{
+		Money: money.New(36250000, "IDR"),
+	}
+
+	jsonBody, _ := json.Marshal(&paymentInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(bill.ID)))
+
+	if assert.NoError(t, handle(ctx, billController.Pay)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestPayBill_Failed(t *testing.T) {
+	testcase := testCaseBill{
+		name:                   "failed",
+		path:                   "/api/v1/bills/pay",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitBillEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var paymentInput models.BillPayment = models.BillPayment{}
+
+	jsonBody, _ := json.Marshal(&paymentInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, billController.Pay)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGenerateBillReminders_Once(t *testing.T) {
+	InitBillEcho()
+
+	shared, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	// a bill from before ledgers existed
+	unshared, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	member := addLedgerMember(t, *shared.LedgerID, models.LedgerRoleViewer)
+
+	now := time.Now()
+	testDB.Model(&models.Bill{}).Where("id IN ?", []uint{shared.ID, unshared.ID}).Update("next_due_date", now.AddDate(0, 0, 1).UTC())
+	testDB.Model(&unshared).Update("ledger_id", nil)
+
+	reminders := func(userID, billID uint) int64 {
+		var count int64
+		testDB.Model(&models.Notification{}).Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, "bill", billID).Count(&count)
+
+		return count
+	}
+
+	repository := repositories.InitBillRepository(testDB)
+
+	reminded, err := repository.GenerateReminders(context.Background(), now)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, reminded, 2)
+
+	// every member of the ledger hears about the shared bill, the user of
+	// the bill without a ledger about that one
+	assert.Equal(t, int64(1), reminders(shared.UserID, shared.ID))
+	assert.Equal(t, int64(1), reminders(member.ID, shared.ID))
+	assert.Equal(t, int64(1), reminders(unshared.UserID, unshared.ID))
+
+	// nothing is sent twice for the same due date
+	reminded, err = repository.GenerateReminders(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, reminded)
+
+	assert.Equal(t, int64(1), reminders(shared.UserID, shared.ID))
+	assert.Equal(t, int64(1), reminders(unshared.UserID, unshared.ID))
+}
+
+func TestGenerateBillReminders_Concurrent(t *testing.T) {
+	InitBillEcho()
+
+	bill, err := testutil.SeedBill(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	now := time.Now()
+	testDB.Model(&bill).Update("next_due_date", now.AddDate(0, 0, 1).UTC())
+
+	// another instance sends the reminder right after this one read the bills
+	var once sync.Once
+	err = testDB.Callback().Query().After("gorm:query").Register("test:remind_concurrently", func(db *gorm.DB) {
+		if db.Statement.Table == "bills" {
+			once.Do(func() {
+				testDB.Exec("UPDATE bills SET reminded_for = next_due_date WHERE id = ?", bill.ID)
+			})
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = testDB.Callback().Query().Remove("test:remind_concurrently")
+	})
+
+	_, err = repositories.InitBillRepository(testDB).GenerateReminders(context.Background(), now)
+	assert.NoError(t, err)
+
+	var reminders int64
+	testDB.Model(&models.Notification{}).Where("entity_type = ? AND entity_id = ?", "bill", bill.ID).Count(&reminders)
+
+	assert.Equal(t, int64(0), reminders)
+}
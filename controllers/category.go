@@ -5,7 +5,6 @@ import (
 	"keuangan-pribadi/services"
 	"net/http"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
@@ -13,20 +12,17 @@ type CategoryController struct {
 	service services.CategoryService
 }
 
-func InitCategoryController() CategoryController {
+func InitCategoryController(service services.CategoryService) CategoryController {
 	return CategoryController{
-		service: services.InitCategoryService(),
+		service: service,
 	}
 }
 
 func (cc *CategoryController) GetAll(c echo.Context) error {
-	categories, err := cc.service.GetAll()
+	categories, err := cc.service.GetAll(c.Request().Context())
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.Response[string]{
-			Status:  "failed",
-			Message: "failed to fetch categories data",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[[]models.Category]{
@@ -39,13 +35,10 @@ func (cc *CategoryController) GetAll(c echo.Context) error {
 func (cc *CategoryController) GetByID(c echo.Context) error {
 	var categoryID string = c.Param("id")
 
-	category, err := cc.service.GetByID(categoryID)
+	category, err := cc.service.GetByID(c.Request().Context(), categoryID)
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "category not found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.Category]{
@@ -59,27 +52,18 @@ func (cc *CategoryController) Create(c echo.Context) error {
 	var categoryInput models.CategoryInput
 
 	if err := c.Bind(&categoryInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(categoryInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	category, err := cc.service.Create(categoryInput)
+	category, err := cc.service.Create(c.Request().Context(), categoryInput, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, models.Response[models.Category]{
@@ -95,27 +79,18 @@ func (cc *CategoryController) Update(c echo.Context) error {
 	var categoryInput models.CategoryInput
 
 	if err := c.Bind(&categoryInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(categoryInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	category, err := cc.service.Update(categoryInput, categoryID)
+	category, err := cc.service.Update(c.Request().Context(), categoryInput, categoryID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.Category]{
@@ -128,13 +103,10 @@ func (cc *CategoryController) Update(c echo.Context) error {
 func (cc *CategoryController) Delete(c echo.Context) error {
 	var categoryID string = c.Param("id")
 
-	err := cc.service.Delete(categoryID)
+	err := cc.service.Delete(c.Request().Context(), categoryID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "Not Found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[string]{
@@ -3,8 +3,10 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
-	"keuangan-pribadi/config"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -22,10 +24,11 @@ type testCaseCategory struct {
 	expectedBodyStartsWith string
 }
 
-var categoryController CategoryController = InitCategoryController()
+var categoryController CategoryController
 
 func InitCategoryEcho() *echo.Echo {
-	config.InitDB()
+	db := initTestDB()
+	categoryController = InitCategoryController(services.InitCategoryService(repositories.InitCategoryRepository(db)))
 
 	e := echo.New()
 
@@ -50,7 +53,7 @@ func TestGetAllCategories_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, categoryController.GetAll(ctx)) {
+	if assert.NoError(t, handle(ctx, categoryController.GetAll)) {
 		assert.Equal(t, http.StatusOK, recorder.Code)
 
 		body := recorder.Body.String()
@@ -91,7 +94,7 @@ func TestCreateCategory_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, categoryController.Create(ctx)) {
+	if assert.NoError(t, handle(ctx, categoryController.Create)) {
 		assert.Equal(t, http.StatusCreated, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -124,7 +127,7 @@ func TestCreateCategory_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, categoryController.Create(ctx)) {
+	if assert.NoError(t, handle(ctx, categoryController.Create)) {
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 		body := recorder.Body.String()
 
@@ -142,7 +145,7 @@ func TestGetCategoryByID_Success(t *testing.T) {
 
 	e := InitCategoryEcho()
 
-	category, err := config.SeedCategory()
+	category, err := testutil.SeedCategory(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -161,7 +164,7 @@ func TestGetCategoryByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(categoryID)
 
-	if assert.NoError(t, categoryController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, categoryController.GetByID)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -188,7 +191,7 @@ func TestGetCategoryByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues("-1")
 
-	if assert.NoError(t, categoryController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, categoryController.GetByID)) {
 		assert.Equal(t, http.StatusNotFound, rec.Code)
 		body := rec.Body.String()
 
@@ -206,7 +209,7 @@ func TestUpdateCategoryByID_Success(t *testing.T) {
 
 	e := InitCategoryEcho()
 
-	category, _ := config.SeedCategory()
+	category, _ := testutil.SeedCategory(testDB)
 
 	categoryInput := models.CategoryInput{
 		Name:      "updated",
@@ -228,7 +231,7 @@ func TestUpdateCategoryByID_Success(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues(categoryID)
 
-	if assert.NoError(t, categoryController.Update(c)) {
+	if assert.NoError(t, handle(c, categoryController.Update)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		body := rec.Body.String()
 
@@ -246,7 +249,7 @@ func TestUpdateCategoryByID_Failed(t *testing.T) {
 
 	e := InitCategoryEcho()
 
-	category, _ := config.SeedCategory()
+	category, _ := testutil.SeedCategory(testDB)
 
 	categoryInput := models.CategoryInput{}
 
@@ -266,7 +269,7 @@ func TestUpdateCategoryByID_Failed(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues(categoryID)
 
-	if assert.NoError(t, categoryController.Update(c)) {
+	if assert.NoError(t, handle(c, categoryController.Update)) {
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 		body := rec.Body.String()
 
@@ -284,7 +287,7 @@ func TestDeleteCategoryByID_Success(t *testing.T) {
 
 	e := InitCategoryEcho()
 
-	category, err := config.SeedCategory()
+	category, err := testutil.SeedCategory(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -303,7 +306,7 @@ func TestDeleteCategoryByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(categoryID)
 
-	if assert.NoError(t, categoryController.Delete(ctx)) {
+	if assert.NoError(t, handle(ctx, categoryController.Delete)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -330,7 +333,7 @@ func TestDeleteCategoryByID_Failed(t *testing.T) {
 	c.SetParamNames("id")
 	c.SetParamValues("-1")
 
-	if assert.NoError(t, categoryController.Delete(c)) {
+	if assert.NoError(t, handle(c, categoryController.Delete)) {
 		assert.Equal(t, http.StatusNotFound, rec.Code)
 		body := rec.Body.String()
 
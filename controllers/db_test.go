@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"fmt"
+	"keuangan-pribadi/config"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/migrations"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/testutil"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var (
+	testDB     *gorm.DB
+	testDBOnce sync.Once
+)
+
+// testConfig is the configuration of the tests, email is only logged.
+var testConfig config.Config = config.Config{
+	Auth: config.AuthConfig{
+		JWTSecret:            "testsecret-testsecret",
+		TokenTTL:             time.Hour,
+		EmailVerificationTTL: 24 * time.Hour,
+		InvitationTTL:        7 * 24 * time.Hour,
+	},
+	Login: config.LoginConfig{
+		MaxAccountAttempts: 5,
+		MaxIPAttempts:      20,
+		BaseLockout:        time.Minute,
+		MaxLockout:         time.Hour,
+		Window:             24 * time.Hour,
+	},
+}
+
+// initTestDB opens an in-memory SQLite database and migrates it once for the
+// whole package, so the tests do not need a database server.
+func initTestDB() *gorm.DB {
+	testDBOnce.Do(func() {
+		middleware.ConfigureJWT(middleware.JWTConfig{SecretKey: testConfig.Auth.JWTSecret, TTL: testConfig.Auth.TokenTTL})
+
+		db, err := config.OpenDB(config.DriverSQLite, "file::memory:?cache=shared", 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if _, err := migrations.Up(db); err != nil {
+			log.Fatal(err)
+		}
+
+		testDB = db
+	})
+
+	return testDB
+}
+
+// handle runs the handler like echo does, a returned error is written by
+// the central error handler. Requests with an Authorization header go through
+// Authenticate like on the protected routes.
+func handle(ctx echo.Context, handler echo.HandlerFunc) error {
+	if ctx.Request().Header.Get("Authorization") != "" {
+		handler = middleware.Authenticate(testDB)(handler)
+	}
+
+	if err := handler(ctx); err != nil {
+		HTTPErrorHandler(err, ctx)
+	}
+
+	return nil
+}
+
+// bearer is the Authorization header of a user signed in with a JWT.
+func bearer(userID uint) string {
+	token, _ := middleware.CreateToken(userID, "test", models.RoleUser)
+
+	return fmt.Sprintf("Bearer %s", token)
+}
+
+// request runs handler for a JSON request sent with the authorization header,
+// params are the names and values of the path parameters.
+func request(t *testing.T, handler echo.HandlerFunc, method, path, body, authorization string, params ...string) *httptest.ResponseRecorder {
+	e := echo.New()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", authorization)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	var names, values []string
+	for i := 0; i+1 < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+
+	ctx.SetPath(path)
+	ctx.SetParamNames(names...)
+	ctx.SetParamValues(values...)
+
+	assert.NoError(t, handle(ctx, handler))
+
+	return recorder
+}
+
+// seedLedgerFinance books an income of the user in the ledger.
+func seedLedgerFinance(t *testing.T, userID, ledgerID uint, name string) models.Finance {
+	category, err := testutil.SeedCategory(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	finance := models.Finance{Name: name, Type: 1, Money: money.New(1000000, "IDR"), UserID: userID, CategoryID: category.ID, LedgerID: &ledgerID}
+	if err := testDB.Create(&finance).Error; err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	return finance
+}
+
+// ledgerFinances are the names of the finances in the ledger.
+func ledgerFinances(t *testing.T, ledgerID uint) []string {
+	var names []string
+	if err := testDB.Model(&models.Finance{}).Where("ledger_id = ?", ledgerID).Order("id").Pluck("name", &names).Error; err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	return names
+}
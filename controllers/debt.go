@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"github.com/labstack/echo/v4"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+)
+
+type DebtController struct {
+	service services.DebtService
+}
+
+func InitDebtController(service services.DebtService) DebtController {
+	return DebtController{
+		service: service,
+	}
+}
+
+func (dc *DebtController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	debts, err := dc.service.GetAll(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.Debt]{
+		Status:  "success",
+		Message: "all debts",
+		Data:    debts,
+	})
+}
+
+func (dc *DebtController) GetOverdue(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	debts, err := dc.service.GetOverdue(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.Debt]{
+		Status:  "success",
+		Message: "overdue debts",
+		Data:    debts,
+	})
+}
+
+func (dc *DebtController) GetSummary(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	summary, err := dc.service.GetSummary(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.DebtSummary]{
+		Status:  "success",
+		Message: "debts summary",
+		Data:    summary,
+	})
+}
+
+func (dc *DebtController) GetByID(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var debtID string = c.Param("id")
+
+	debt, err := dc.service.GetByID(c.Request().Context(), debtID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Debt]{
+		Status:  "success",
+		Message: "debt found",
+		Data:    debt,
+	})
+}
+
+func (dc *DebtController) Create(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var debtInput models.DebtInput
+
+	if err := c.Bind(&debtInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(debtInput); err != nil {
+		return validationError(err)
+	}
+
+	debt, err := dc.service.Create(c.Request().Context(), debtInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.Debt]{
+		Status:  "success",
+		Message: "debt created",
+		Data:    debt,
+	})
+}
+
+func (dc *DebtController) Update(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var debtInput models.DebtInput
+
+	if err := c.Bind(&debtInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(debtInput); err != nil {
+		return validationError(err)
+	}
+
+	var debtID string = c.Param("id")
+
+	debt, err := dc.service.Update(c.Request().Context(), debtInput, debtID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Debt]{
+		Status:  "success",
+		Message: "debt updated",
+		Data:    debt,
+	})
+}
+
+func (dc *DebtController) Delete(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var debtID string = c.Param("id")
+
+	err = dc.service.Delete(c.Request().Context(), debtID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "debt deleted",
+	})
+}
+
+func (dc *DebtController) Repay(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var repaymentInput models.DebtRepaymentInput
+
+	if err := c.Bind(&repaymentInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(repaymentInput); err != nil {
+		return validationError(err)
+	}
+
+	var debtID string = c.Param("id")
+
+	repayment, err := dc.service.Repay(c.Request().Context(), repaymentInput, debtID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.DebtRepayment]{
+		Status:  "success",
+		Message: "repayment recorded",
+		Data:    repayment,
+	})
+}
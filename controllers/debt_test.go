@@ -0,0 +1,742 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCaseDebt struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var debtController DebtController
+
+func InitDebtEcho() *echo.Echo {
+	db := initTestDB()
+	debtController = InitDebtController(services.InitDebtService(repositories.InitDebtRepository(db)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetAllDebts_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetAllDebts_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetOverdueDebts_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts/overdue",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(debt.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.GetOverdue)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetOverdueDebts_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts/overdue",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.GetOverdue)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetDebtSummary_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts/summary",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(debt.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.GetSummary)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetDebtSummary_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts/summary",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.GetSummary)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetDebt_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(debt.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(debt.ID)))
+
+	if assert.NoError(t, handle(ctx, debtController.GetByID)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetDebt_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, debtController.GetByID)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateDebt_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var debtInput models.DebtInput = models.DebtInput{
+		Counterparty: "budi",
+		Direction:    models.DebtDirectionPayable,
+		Principal:    money.New(50000000, "IDR"),
+	}
+
+	jsonBody, _ := json.Marshal(&debtInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateDebt_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var debtInput models.DebtInput = models.DebtInput{
+		Counterparty: "budi",
+		Direction:    "gift",
+		Principal:    money.New(50000000, "IDR"),
+	}
+
+	jsonBody, _ := json.Marshal(&debtInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, debtController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateDebt_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(debt.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var debtInput models.DebtInput = models.DebtInput{
+		Counterparty: "budi",
+		Direction:    models.DebtDirectionPayable,
+		Principal:    money.New(50000000, "IDR"),
+	}
+
+	jsonBody, _ := json.Marshal(&debtInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(debt.ID)))
+
+	if assert.NoError(t, handle(ctx, debtController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateDebt_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var debtInput models.DebtInput = models.DebtInput{
+		Counterparty: "budi",
+		Direction:    models.DebtDirectionPayable,
+		Principal:    money.New(50000000, "IDR"),
+	}
+
+	jsonBody, _ := json.Marshal(&debtInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, debtController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteDebt_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(debt.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(debt.ID)))
+
+	if assert.NoError(t, handle(ctx, debtController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteDebt_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, debtController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestRepayDebt_Success(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "success",
+		path:                   "/api/v1/debts/repayments",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(debt.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	var repaymentInput models.DebtRepaymentInput = models.DebtRepaymentInput{
+		Amount:     money.New(2500000, "IDR"),
+		CategoryID: category.ID,
+	}
+
+	jsonBody, _ := json.Marshal(&repaymentInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(debt.ID)))
+
+	if assert.NoError(t, handle(ctx, debtController.Repay)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestRepayDebt_Failed(t *testing.T) {
+	testcase := testCaseDebt{
+		name:                   "failed",
+		path:                   "/api/v1/debts/repayments",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(debt.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	var repaymentInput models.DebtRepaymentInput = models.DebtRepaymentInput{
+		Amount:     money.New(debt.Principal.Minor+1, debt.Principal.Currency),
+		CategoryID: category.ID,
+	}
+
+	jsonBody, _ := json.Marshal(&repaymentInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(debt.ID)))
+
+	if assert.NoError(t, handle(ctx, debtController.Repay)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+// seedLedgerDebt adds a debt to the ledger of debt, due days from now.
+func seedLedgerDebt(t *testing.T, debt models.Debt, counterparty, direction string, principal, paid int64, days int) models.Debt {
+	dueDate := time.Now().AddDate(0, 0, days)
+
+	seeded := models.Debt{
+		Counterparty: counterparty,
+		Direction:    direction,
+		Principal:    money.New(principal, "IDR"),
+		Paid:         money.New(paid, "IDR"),
+		DueDate:      &dueDate,
+		UserID:       debt.UserID,
+		LedgerID:     debt.LedgerID,
+	}
+
+	if paid == principal {
+		seeded.SettledAt = &dueDate
+	}
+
+	if err := testDB.Create(&seeded).Error; err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	return seeded
+}
+
+func TestGetOverdueDebts_Totals(t *testing.T) {
+	InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	partlyRepaid := seedLedgerDebt(t, debt, "ani", models.DebtDirectionPayable, 4000000, 1500000, -3)
+	seedLedgerDebt(t, debt, "budi", models.DebtDirectionReceivable, 3000000, 0, 1)
+	seedLedgerDebt(t, debt, "citra", models.DebtDirectionPayable, 1000000, 1000000, -5)
+
+	recorder := request(t, debtController.GetOverdue, http.MethodGet, "/api/v1/debts/overdue", "", bearer(debt.UserID))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// settled debts and those not due yet are left out, the oldest comes first
+	var overdue models.Response[[]models.Debt]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &overdue)) && assert.Len(t, overdue.Data, 2) {
+		assert.Equal(t, partlyRepaid.ID, overdue.Data[0].ID)
+		assert.Equal(t, money.New(2500000, "IDR"), overdue.Data[0].Outstanding)
+		assert.Equal(t, debt.ID, overdue.Data[1].ID)
+		assert.Equal(t, money.New(10000000, "IDR"), overdue.Data[1].Outstanding)
+	}
+
+	recorder = request(t, debtController.GetSummary, http.MethodGet, "/api/v1/debts/summary", "", bearer(debt.UserID))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var summary models.Response[models.DebtSummary]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &summary)) {
+		assert.Equal(t, money.Totals{money.New(2500000, "IDR")}, summary.Data.Payable)
+		assert.Equal(t, money.Totals{money.New(13000000, "IDR")}, summary.Data.Receivable)
+		assert.Equal(t, money.Totals{money.New(10500000, "IDR")}, summary.Data.Net)
+
+		if assert.Len(t, summary.Data.Counterparties, 2) {
+			assert.Equal(t, "ani", summary.Data.Counterparties[0].Counterparty)
+			assert.Equal(t, money.Totals{money.New(-2500000, "IDR")}, summary.Data.Counterparties[0].Net)
+			assert.Equal(t, "budi", summary.Data.Counterparties[1].Counterparty)
+			assert.Equal(t, money.Totals{money.New(13000000, "IDR")}, summary.Data.Counterparties[1].Net)
+		}
+	}
+}
+
+func TestRepayDebt_Settled(t *testing.T) {
+	InitDebtEcho()
+
+	debt, err := testutil.SeedDebt(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	category, err := testutil.SeedCategory(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	body := fmt.Sprintf(`{"amount":{"amount":"100000.00","currency":"IDR"},"category_id":%d}`, category.ID)
+	recorder := request(t, debtController.Repay, http.MethodPost, "/api/v1/debts/repayments", body, bearer(debt.UserID), "id", strconv.Itoa(int(debt.ID)))
+	assert.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+	var repaid models.Debt
+	if assert.NoError(t, testDB.Preload("Repayments.Finance").First(&repaid, debt.ID).Error) {
+		assert.Equal(t, money.New(10000000, "IDR"), repaid.Paid)
+		assert.True(t, repaid.Outstanding.IsZero())
+		assert.NotNil(t, repaid.SettledAt)
+
+		// the money lent coming back is income
+		if assert.Len(t, repaid.Repayments, 1) {
+			assert.Equal(t, 1, repaid.Repayments[0].Finance.Type)
+			assert.Equal(t, money.New(10000000, "IDR"), repaid.Repayments[0].Finance.Money)
+		}
+	}
+
+	// a settled debt is no longer overdue
+	recorder = request(t, debtController.GetOverdue, http.MethodGet, "/api/v1/debts/overdue", "", bearer(debt.UserID))
+
+	var overdue models.Response[[]models.Debt]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &overdue)) {
+		assert.Empty(t, overdue.Data)
+	}
+}
@@ -4,9 +4,6 @@ import (
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/services"
 	"net/http"
-	"strings"
-
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
@@ -14,29 +11,22 @@ type DetailSavingController struct {
 	service services.DetailSavingService
 }
 
-func InitDetailSavingController() DetailSavingController {
+func InitDetailSavingController(service services.DetailSavingService) DetailSavingController {
 	return DetailSavingController{
-		service: services.InitDetailSavingService(),
+		service: service,
 	}
 }
 
 func (fc *DetailSavingController) GetAll(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
-	detailSavings, err := fc.service.GetAll(token)
+	detailSavings, err := fc.service.GetAll(c.Request().Context(), userID)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.Response[string]{
-			Status:  "failed",
-			Message: "failed to fetch detailSavings data",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[[]models.DetailSaving]{
@@ -47,24 +37,17 @@ func (fc *DetailSavingController) GetAll(c echo.Context) error {
 }
 
 func (fc *DetailSavingController) GetByID(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var detailSavingID string = c.Param("id")
 
-	detailSaving, err := fc.service.GetByID(detailSavingID, token)
+	detailSaving, err := fc.service.GetByID(c.Request().Context(), detailSavingID, userID)
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "detail saving not found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.DetailSaving]{
@@ -75,39 +58,26 @@ func (fc *DetailSavingController) GetByID(c echo.Context) error {
 }
 
 func (fc *DetailSavingController) Create(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var detailSavingInput models.DetailSavingInput
 
 	if err := c.Bind(&detailSavingInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(detailSavingInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	detailSaving, err := fc.service.Create(detailSavingInput, token)
+	detailSaving, err := fc.service.Create(c.Request().Context(), detailSavingInput, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, models.Response[models.DetailSaving]{
@@ -118,41 +88,28 @@ func (fc *DetailSavingController) Create(c echo.Context) error {
 }
 
 func (fc *DetailSavingController) Update(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var detailSavingID string = c.Param("id")
 
 	var detailSavingInput models.DetailSavingInput
 
 	if err := c.Bind(&detailSavingInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(detailSavingInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	detailSaving, err := fc.service.Update(detailSavingInput, detailSavingID, token)
+	detailSaving, err := fc.service.Update(c.Request().Context(), detailSavingInput, detailSavingID, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.DetailSaving]{
@@ -163,24 +120,17 @@ func (fc *DetailSavingController) Update(c echo.Context) error {
 }
 
 func (fc *DetailSavingController) Delete(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var detailSavingID string = c.Param("id")
 
-	err := fc.service.Delete(detailSavingID, token)
+	err = fc.service.Delete(c.Request().Context(), detailSavingID, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "Not Found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[string]{
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"keuangan-pribadi/config"
 	"keuangan-pribadi/middleware"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -24,10 +27,11 @@ type testCaseDetailSaving struct {
 	expectedBodyStartsWith string
 }
 
-var detailSavingController DetailSavingController = InitDetailSavingController()
+var detailSavingController DetailSavingController
 
 func InitDetailSavingEcho() *echo.Echo {
-	config.InitDB()
+	db := initTestDB()
+	detailSavingController = InitDetailSavingController(services.InitDetailSavingService(repositories.InitDetailSavingRepository(db)))
 
 	e := echo.New()
 
@@ -44,8 +48,8 @@ func TestGetAllDetailSavings_Success(t *testing.T) {
 
 	e := InitDetailSavingEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -56,7 +60,7 @@ func TestGetAllDetailSavings_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, detailSavingController.GetAll(ctx)) {
+	if assert.NoError(t, handle(ctx, detailSavingController.GetAll)) {
 		assert.Equal(t, http.StatusOK, recorder.Code)
 
 		body := recorder.Body.String()
@@ -69,7 +73,7 @@ func TestGetAllDetailSavings_Failed(t *testing.T) {
 	testcase := testCaseDetailSaving{
 		name:                   "failed",
 		path:                   "/api/v1/detail-savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -83,8 +87,8 @@ func TestGetAllDetailSavings_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, detailSavingController.GetAll(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	if assert.NoError(t, handle(ctx, detailSavingController.GetAll)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 
 		body := recorder.Body.String()
 
@@ -102,18 +106,18 @@ func TestCreateDetailSaving_Success(t *testing.T) {
 
 	e := InitDetailSavingEcho()
 
-	user, err := config.SeedUser()
+	user, err := testutil.SeedUser(testDB)
 	if err != nil {
 		t.Errorf("error: %v\n", err)
 	}
 
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
-	saving, _ := config.SeedSaving()
+	saving, _ := testutil.SeedSaving(testDB)
 
 	var savingInput models.DetailSavingInput = models.DetailSavingInput{
-		Value: 			1,
+		Value: 			money.New(100, "IDR"),
 		SavingID: 			saving.ID,
 		UserID: 			user.ID,
 	}
@@ -137,7 +141,7 @@ func TestCreateDetailSaving_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, detailSavingController.Create(ctx)) {
+	if assert.NoError(t, handle(ctx, detailSavingController.Create)) {
 		assert.Equal(t, http.StatusCreated, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -150,7 +154,7 @@ func TestCreateDetailSaving_Failed(t *testing.T) {
 	testcase := testCaseDetailSaving {
 		name:                   "failed",
 		path:                   "/api/v1/detail-savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -170,8 +174,8 @@ func TestCreateDetailSaving_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, detailSavingController.Create(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	if assert.NoError(t, handle(ctx, detailSavingController.Create)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 		body := recorder.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -188,7 +192,7 @@ func TestGetDetailSavingByID_Success(t *testing.T) {
 
 	e := InitDetailSavingEcho()
 
-	detailSaving, err := config.SeedDetailSaving()
+	detailSaving, err := testutil.SeedDetailSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -196,8 +200,8 @@ func TestGetDetailSavingByID_Success(t *testing.T) {
 
 	detailSavingID := strconv.Itoa(int(detailSaving.ID))
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	request := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -211,7 +215,7 @@ func TestGetDetailSavingByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(detailSavingID)
 
-	if assert.NoError(t, detailSavingController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, detailSavingController.GetByID)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -230,7 +234,7 @@ func TestGetDetailSavingByID_Failed(t *testing.T) {
 
 	e := InitDetailSavingEcho()
 
-	detailSaving, err := config.SeedDetailSaving()
+	detailSaving, err := testutil.SeedDetailSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -249,7 +253,7 @@ func TestGetDetailSavingByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(detailSavingID)
 
-	if assert.NoError(t, detailSavingController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, detailSavingController.GetByID)) {
 		assert.Equal(t, http.StatusBadRequest, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -262,20 +266,20 @@ func TestUpdateDetailSavingByID_Failed(t *testing.T) {
 	testcase := testCaseDetailSaving{
 		name:                   "failed",
 		path:                   "/api/v1/detail-savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusNotFound,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
 	e := InitDetailSavingEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
-	saving, _ := config.SeedSaving()
-	detailSaving, _ := config.SeedDetailSaving()
+	saving, _ := testutil.SeedSaving(testDB)
+	detailSaving, _ := testutil.SeedDetailSaving(testDB)
 
 	detailSavingInput := models.DetailSavingInput{
-		Value: 			1,
+		Value: 			money.New(100, "IDR"),
 		SavingID:  		saving.ID,
 		UserID:  		user.ID,
 	}
@@ -297,8 +301,8 @@ func TestUpdateDetailSavingByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(detailSavingID)
 
-	if assert.NoError(t, detailSavingController.Update(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	if assert.NoError(t, handle(ctx, detailSavingController.Update)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
 		body := rec.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -309,18 +313,18 @@ func TestUpdateDetailSavingByID_TokenFailed(t *testing.T) {
 	testcase := testCaseDetailSaving{
 		name:                   "failed",
 		path:                   "/api/v1/detail-savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
 	e := InitDetailSavingEcho()
 
-	user, _ := config.SeedUser()
-	saving, _ := config.SeedSaving()
-	detailSaving, _ := config.SeedDetailSaving()
+	user, _ := testutil.SeedUser(testDB)
+	saving, _ := testutil.SeedSaving(testDB)
+	detailSaving, _ := testutil.SeedDetailSaving(testDB)
 
 	detailSavingInput := models.DetailSavingInput{
-		Value: 			1,
+		Value: 			money.New(100, "IDR"),
 		SavingID:  		saving.ID,
 		UserID:  		user.ID,
 	}
@@ -342,8 +346,8 @@ func TestUpdateDetailSavingByID_TokenFailed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(detailSavingID)
 
-	if assert.NoError(t, detailSavingController.Update(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	if assert.NoError(t, handle(ctx, detailSavingController.Update)) {
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 		body := rec.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -360,14 +364,14 @@ func TestDeleteDetailSavingByID_Success(t *testing.T) {
 
 	e := InitDetailSavingEcho()
 
-	detailSaving, err := config.SeedDetailSaving()
+	detailSaving, err := testutil.SeedDetailSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
 	}
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	detailSavingID := strconv.Itoa(int(detailSaving.ID))
@@ -383,7 +387,7 @@ func TestDeleteDetailSavingByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(detailSavingID)
 
-	if assert.NoError(t, detailSavingController.Delete(ctx)) {
+	if assert.NoError(t, handle(ctx, detailSavingController.Delete)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -402,7 +406,7 @@ func TestDeleteDetailSavingByID_Failed(t *testing.T) {
 
 	e := InitDetailSavingEcho()
 
-	detailSaving, err := config.SeedDetailSaving()
+	detailSaving, err := testutil.SeedDetailSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -421,7 +425,7 @@ func TestDeleteDetailSavingByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(detailSavingID)
 
-	if assert.NoError(t, detailSavingController.Delete(ctx)) {
+	if assert.NoError(t, handle(ctx, detailSavingController.Delete)) {
 		assert.Equal(t, http.StatusBadRequest, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"keuangan-pribadi/docs"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// swaggerUI renders the OpenAPI document with Swagger UI.
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Keuangan Pribadi API</title>
+	<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+	<script>
+		window.onload = function () {
+			window.ui = SwaggerUIBundle({
+				url: "/api/docs/openapi.json",
+				dom_id: "#swagger-ui",
+				persistAuthorization: true
+			});
+		};
+	</script>
+</body>
+</html>
+`
+
+func GetOpenAPI(c echo.Context) error {
+	spec, err := docs.JSON()
+	if err != nil {
+		return err
+	}
+
+	return c.JSONBlob(http.StatusOK, spec)
+}
+
+func GetDocs(c echo.Context) error {
+	return c.HTML(http.StatusOK, swaggerUI)
+}
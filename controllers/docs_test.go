@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOpenAPI_Success(t *testing.T) {
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/docs/openapi.json", nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, GetOpenAPI)) {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var spec struct {
+			OpenAPI    string                     `json:"openapi"`
+			Paths      map[string]json.RawMessage `json:"paths"`
+			Components struct {
+				Schemas map[string]json.RawMessage `json:"schemas"`
+			} `json:"components"`
+		}
+
+		if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &spec)) {
+			assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."))
+			assert.Contains(t, spec.Paths, "/api/v1/finances/{id}")
+			assert.Contains(t, spec.Components.Schemas, "FinanceInput")
+			assert.Contains(t, spec.Components.Schemas, "Error")
+		}
+	}
+}
+
+func TestGetDocs_Success(t *testing.T) {
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/docs", nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, GetDocs)) {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, strings.HasPrefix(recorder.Header().Get(echo.HeaderContentType), echo.MIMETextHTML))
+		assert.True(t, strings.Contains(recorder.Body.String(), "/api/docs/openapi.json"))
+	}
+}
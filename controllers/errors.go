@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var errorStatuses = map[models.ErrorKind]int{
+	models.ErrorKindNotFound:     http.StatusNotFound,
+	models.ErrorKindForbidden:    http.StatusForbidden,
+	models.ErrorKindValidation:   http.StatusBadRequest,
+	models.ErrorKindConflict:     http.StatusConflict,
+	models.ErrorKindUnauthorized: http.StatusUnauthorized,
+	models.ErrorKindUnavailable:  http.StatusServiceUnavailable,
+}
+
+// errInvalidRequest is returned when the request body cannot be read.
+var errInvalidRequest = models.ValidationError("invalid_request", "invalid request")
+
+// HTTPErrorHandler writes the errors returned by the handlers and the
+// middleware as a failed response. Domain errors keep their status and code,
+// any other error is logged and answered with a generic message so nothing
+// like a SQL statement reaches the client.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, response := errorResponse(err)
+
+	if status == http.StatusInternalServerError {
+		slog.ErrorContext(c.Request().Context(), "error when handling the request", "method", c.Request().Method, "route", c.Path(), "error", err)
+	}
+
+	var writeErr error
+	if c.Request().Method == http.MethodHead {
+		writeErr = c.NoContent(status)
+	} else {
+		writeErr = c.JSON(status, response)
+	}
+
+	if writeErr != nil {
+		slog.ErrorContext(c.Request().Context(), "error when writing the error response", "error", writeErr)
+	}
+}
+
+func errorResponse(err error) (int, models.Response[string]) {
+	var domainErr *models.Error
+	var httpErr *echo.HTTPError
+
+	switch {
+	case errors.As(err, &domainErr):
+		return errorStatuses[domainErr.Kind], failed(domainErr.Code, domainErr.Message)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, failed(string(models.ErrorKindNotFound), "Not Found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict, failed(string(models.ErrorKindConflict), "the record already exists")
+	case errors.Is(err, money.ErrCurrencyMismatch), errors.Is(err, money.ErrOverflow),
+		errors.Is(err, money.ErrInvalidAmount), errors.Is(err, money.ErrUnknownCurrency):
+		return http.StatusBadRequest, failed("invalid_amount", err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable, failed("timeout", "the request took too long")
+	case errors.Is(err, context.Canceled):
+		// the client is gone, the response is not read by anyone
+		return http.StatusServiceUnavailable, failed("request_canceled", "the request was canceled")
+	case errors.As(err, &httpErr):
+		// errors of echo itself, like an unknown route
+		message, ok := httpErr.Message.(string)
+		if !ok {
+			message = http.StatusText(httpErr.Code)
+		}
+
+		code := strings.ReplaceAll(strings.ToLower(http.StatusText(httpErr.Code)), " ", "_")
+
+		return httpErr.Code, failed(code, message)
+	default:
+		return http.StatusInternalServerError, failed("internal_error", "internal server error")
+	}
+}
+
+func failed(code, message string) models.Response[string] {
+	return models.Response[string]{
+		Status:  "failed",
+		Message: message,
+		Code:    code,
+	}
+}
+
+// validationError is the error of an input failing its validate tags.
+func validationError(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	return models.ValidationError(string(models.ErrorKindValidation), err.Error())
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"keuangan-pribadi/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPErrorHandler_Success(t *testing.T) {
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/finances/1", nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	HTTPErrorHandler(models.NotFoundError("finance_not_found", "finance not found"), ctx)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	var response models.Response[string]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, "failed", response.Status)
+		assert.Equal(t, "finance_not_found", response.Code)
+		assert.Equal(t, "finance not found", response.Message)
+	}
+}
+
+func TestHTTPErrorHandler_Failed(t *testing.T) {
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/finances", nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	HTTPErrorHandler(errors.New("Error 1054: Unknown column 'finances.secret' in 'where clause'"), ctx)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	body := recorder.Body.String()
+
+	assert.True(t, strings.Contains(body, "\"code\":\"internal_error\""))
+	assert.False(t, strings.Contains(body, "Unknown column"))
+}
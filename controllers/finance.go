@@ -4,10 +4,8 @@ import (
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/services"
 	"net/http"
-	"strings"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
@@ -15,29 +13,22 @@ type FinanceController struct {
 	service services.FinanceService
 }
 
-func InitFinanceController() FinanceController {
+func InitFinanceController(service services.FinanceService) FinanceController {
 	return FinanceController{
-		service: services.InitFinanceService(),
+		service: service,
 	}
 }
 
 func (fc *FinanceController) GetAll(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
-	finances, err := fc.service.GetAll(token)
+	finances, err := fc.service.GetAll(c.Request().Context(), userID)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.Response[string]{
-			Status:  "failed",
-			Message: "failed to fetch finances data",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[[]models.Finance]{
@@ -48,24 +39,17 @@ func (fc *FinanceController) GetAll(c echo.Context) error {
 }
 
 func (fc *FinanceController) GetByID(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var financeID string = c.Param("id")
 
-	finance, err := fc.service.GetByID(financeID, token)
+	finance, err := fc.service.GetByID(c.Request().Context(), financeID, userID)
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "finance not found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.Finance]{
@@ -76,38 +60,25 @@ func (fc *FinanceController) GetByID(c echo.Context) error {
 }
 
 func (fc *FinanceController) Search(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	from, err := time.Parse(time.DateOnly, c.QueryParam("from"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid from date",
-		})
+		return models.ValidationError("invalid_date", "invalid from date")
 	}
 	
 	to, err := time.Parse(time.DateOnly, c.QueryParam("to"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid to date",
-		})
+		return models.ValidationError("invalid_date", "invalid to date")
 	}
 
-	finances, err := fc.service.Search(from, to, token)
+	finances, err := fc.service.Search(c.Request().Context(), from, to, userID)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.Response[string]{
-			Status:  "failed",
-			Message: "failed to fetch finances data",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[[]models.Finance]{
@@ -118,39 +89,26 @@ func (fc *FinanceController) Search(c echo.Context) error {
 }
 
 func (fc *FinanceController) Create(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var financeInput models.FinanceInput
 
 	if err := c.Bind(&financeInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(financeInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	finance, err := fc.service.Create(financeInput, token)
+	finance, err := fc.service.Create(c.Request().Context(), financeInput, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, models.Response[models.Finance]{
@@ -161,41 +119,28 @@ func (fc *FinanceController) Create(c echo.Context) error {
 }
 
 func (fc *FinanceController) Update(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var financeID string = c.Param("id")
 
 	var financeInput models.FinanceInput
 
 	if err := c.Bind(&financeInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(financeInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	finance, err := fc.service.Update(financeInput, financeID, token)
+	finance, err := fc.service.Update(c.Request().Context(), financeInput, financeID, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.Finance]{
@@ -206,24 +151,17 @@ func (fc *FinanceController) Update(c echo.Context) error {
 }
 
 func (fc *FinanceController) Delete(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var financeID string = c.Param("id")
 
-	err := fc.service.Delete(financeID, token)
+	err = fc.service.Delete(c.Request().Context(), financeID, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "Not Found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[string]{
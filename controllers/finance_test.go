@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"keuangan-pribadi/config"
 	"keuangan-pribadi/middleware"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -24,10 +27,11 @@ type testCaseFinance struct {
 	expectedBodyStartsWith string
 }
 
-var financeController FinanceController = InitFinanceController()
+var financeController FinanceController
 
 func InitFinanceEcho() *echo.Echo {
-	config.InitDB()
+	db := initTestDB()
+	financeController = InitFinanceController(services.InitFinanceService(repositories.InitFinanceRepository(db)))
 
 	e := echo.New()
 
@@ -44,8 +48,8 @@ func TestGetAllFinances_Success(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -56,7 +60,7 @@ func TestGetAllFinances_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.GetAll(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.GetAll)) {
 		assert.Equal(t, http.StatusOK, recorder.Code)
 
 		body := recorder.Body.String()
@@ -65,11 +69,51 @@ func TestGetAllFinances_Success(t *testing.T) {
 	}
 }
 
+func TestGetAllFinances_SharedLedger(t *testing.T) {
+	InitFinanceEcho()
+
+	household, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	member := addLedgerMember(t, household.ID, models.LedgerRoleViewer)
+
+	category, _ := testutil.SeedCategory(testDB)
+
+	var finance models.Finance = models.Finance{
+		Name:       "groceries",
+		Type:       2,
+		Money:      money.New(5000000, "IDR"),
+		UserID:     household.Members[0].UserID,
+		CategoryID: category.ID,
+		LedgerID:   &household.ID,
+	}
+
+	if err := testDB.Create(&finance).Error; err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	// members see each other's finances but never each other's password
+	for _, recorder := range []*httptest.ResponseRecorder{
+		request(t, financeController.GetAll, http.MethodGet, "/api/v1/finances", "", bearer(member.ID)),
+		request(t, financeController.GetByID, http.MethodGet, "/api/v1/finances/:id", "", bearer(member.ID), "id", fmt.Sprint(finance.ID)),
+	} {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.Contains(body, "\"name\":\"groceries\""))
+		assert.False(t, strings.Contains(body, "password"))
+		assert.False(t, strings.Contains(body, "$2a$"))
+	}
+}
+
 func TestGetAllFinances_Failed(t *testing.T) {
 	testcase := testCaseFinance{
 		name:                   "failed",
 		path:                   "/api/v1/finances",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -83,8 +127,8 @@ func TestGetAllFinances_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.GetAll(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	if assert.NoError(t, handle(ctx, financeController.GetAll)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 
 		body := recorder.Body.String()
 
@@ -102,15 +146,15 @@ func TestCreateFinance_Success(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	user, err := config.SeedUser()
+	user, err := testutil.SeedUser(testDB)
 	if err != nil {
 		t.Errorf("error: %v\n", err)
 	}
 
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
-	category, err := config.SeedCategory()
+	category, err := testutil.SeedCategory(testDB)
 	if err != nil {
 		t.Errorf("error: %v\n", err)
 	}
@@ -118,7 +162,7 @@ func TestCreateFinance_Success(t *testing.T) {
 	var financeInput models.FinanceInput = models.FinanceInput{
 		Name:      		"test",
 		Type: 			1,
-		Money: 			25000,
+		Money: 			money.New(2500000, "IDR"),
 		UserID:  		user.ID,
 		CategoryID:  	category.ID,
 	}
@@ -142,7 +186,7 @@ func TestCreateFinance_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.Create(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.Create)) {
 		assert.Equal(t, http.StatusCreated, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -155,7 +199,7 @@ func TestCreateFinance_Failed(t *testing.T) {
 	testcase := testCaseFinance {
 		name:                   "failed",
 		path:                   "/api/v1/finances",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -175,8 +219,8 @@ func TestCreateFinance_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.Create(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	if assert.NoError(t, handle(ctx, financeController.Create)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 		body := recorder.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -193,7 +237,7 @@ func TestGetFinanceByID_Success(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	finance, err := config.SeedFinance()
+	finance, err := testutil.SeedFinance(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -201,8 +245,8 @@ func TestGetFinanceByID_Success(t *testing.T) {
 
 	financeID := strconv.Itoa(int(finance.ID))
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	request := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -216,7 +260,7 @@ func TestGetFinanceByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(financeID)
 
-	if assert.NoError(t, financeController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.GetByID)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -235,7 +279,7 @@ func TestGetFinanceByID_Failed(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	finance, err := config.SeedFinance()
+	finance, err := testutil.SeedFinance(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -254,7 +298,7 @@ func TestGetFinanceByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(financeID)
 
-	if assert.NoError(t, financeController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.GetByID)) {
 		assert.Equal(t, http.StatusBadRequest, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -273,8 +317,8 @@ func TestSearch_Success(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -289,7 +333,7 @@ func TestSearch_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.Search(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.Search)) {
 		assert.Equal(t, http.StatusOK, recorder.Code)
 
 		body := recorder.Body.String()
@@ -302,7 +346,7 @@ func TestSearch_TokenFailed(t *testing.T) {
 	testcase := testCaseFinance{
 		name:                   "failed",
 		path:                   "/api/v1/finances/search",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -320,8 +364,8 @@ func TestSearch_TokenFailed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.Search(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	if assert.NoError(t, handle(ctx, financeController.Search)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 
 		body := recorder.Body.String()
 
@@ -339,8 +383,8 @@ func TestSearch_FromFailed(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -355,7 +399,7 @@ func TestSearch_FromFailed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.Search(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.Search)) {
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 
 		body := recorder.Body.String()
@@ -374,8 +418,8 @@ func TestSearch_ToFailed(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -390,7 +434,7 @@ func TestSearch_ToFailed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, financeController.Search(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.Search)) {
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 
 		body := recorder.Body.String()
@@ -403,22 +447,22 @@ func TestUpdateFinanceByID_Failed(t *testing.T) {
 	testcase := testCaseFinance{
 		name:                   "failed",
 		path:                   "/api/v1/finances",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusNotFound,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
 	e := InitFinanceEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
-	category, _ := config.SeedCategory()
-	finance, _ := config.SeedFinance()
+	category, _ := testutil.SeedCategory(testDB)
+	finance, _ := testutil.SeedFinance(testDB)
 
 	financeInput := models.FinanceInput{
 		Name:      		"testupdate",
 		Type: 			1,
-		Money: 			25000,
+		Money: 			money.New(2500000, "IDR"),
 		UserID:  		user.ID,
 		CategoryID:  	category.ID,
 	}
@@ -440,8 +484,8 @@ func TestUpdateFinanceByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(financeID)
 
-	if assert.NoError(t, financeController.Update(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	if assert.NoError(t, handle(ctx, financeController.Update)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
 		body := rec.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -452,20 +496,20 @@ func TestUpdateFinanceByID_TokenFailed(t *testing.T) {
 	testcase := testCaseFinance{
 		name:                   "failed",
 		path:                   "/api/v1/finances",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
 	e := InitFinanceEcho()
 
-	user, _ := config.SeedUser()
-	category, _ := config.SeedCategory()
-	finance, _ := config.SeedFinance()
+	user, _ := testutil.SeedUser(testDB)
+	category, _ := testutil.SeedCategory(testDB)
+	finance, _ := testutil.SeedFinance(testDB)
 
 	financeInput := models.FinanceInput{
 		Name:      		"testupdate",
 		Type: 			1,
-		Money: 			25000,
+		Money: 			money.New(2500000, "IDR"),
 		UserID:  		user.ID,
 		CategoryID:  	category.ID,
 	}
@@ -487,8 +531,8 @@ func TestUpdateFinanceByID_TokenFailed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(financeID)
 
-	if assert.NoError(t, financeController.Update(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	if assert.NoError(t, handle(ctx, financeController.Update)) {
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 		body := rec.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -505,14 +549,14 @@ func TestDeleteFinanceByID_Success(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	finance, err := config.SeedFinance()
+	finance, err := testutil.SeedFinance(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
 	}
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	financeID := strconv.Itoa(int(finance.ID))
@@ -528,7 +572,7 @@ func TestDeleteFinanceByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(financeID)
 
-	if assert.NoError(t, financeController.Delete(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.Delete)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -547,7 +591,7 @@ func TestDeleteFinanceByID_Failed(t *testing.T) {
 
 	e := InitFinanceEcho()
 
-	finance, err := config.SeedFinance()
+	finance, err := testutil.SeedFinance(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -566,7 +610,7 @@ func TestDeleteFinanceByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(financeID)
 
-	if assert.NoError(t, financeController.Delete(ctx)) {
+	if assert.NoError(t, handle(ctx, financeController.Delete)) {
 		assert.Equal(t, http.StatusBadRequest, testcase.expectedStatus)
 
 		body := recorder.Body.String()
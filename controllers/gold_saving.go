@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type GoldSavingController struct {
+	service services.GoldSavingService
+}
+
+func InitGoldSavingController(service services.GoldSavingService) GoldSavingController {
+	return GoldSavingController{
+		service: service,
+	}
+}
+
+func (gc *GoldSavingController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	goldSavings, err := gc.service.GetAll(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.GoldSaving]{
+		Status:  "success",
+		Message: "all gold savings",
+		Data:    goldSavings,
+	})
+}
+
+func (gc *GoldSavingController) GetByID(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var goldSavingID string = c.Param("id")
+
+	goldSaving, err := gc.service.GetByID(c.Request().Context(), goldSavingID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.GoldSaving]{
+		Status:  "success",
+		Message: "gold saving found",
+		Data:    goldSaving,
+	})
+}
+
+func (gc *GoldSavingController) Create(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var goldSavingInput models.GoldSavingInput
+
+	if err := c.Bind(&goldSavingInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(goldSavingInput); err != nil {
+		return validationError(err)
+	}
+
+	goldSaving, err := gc.service.Create(c.Request().Context(), goldSavingInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.GoldSaving]{
+		Status:  "success",
+		Message: "gold saving created",
+		Data:    goldSaving,
+	})
+}
+
+func (gc *GoldSavingController) Update(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var goldSavingID string = c.Param("id")
+
+	var goldSavingUpdate models.GoldSavingUpdate
+
+	if err := c.Bind(&goldSavingUpdate); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(goldSavingUpdate); err != nil {
+		return validationError(err)
+	}
+
+	goldSaving, err := gc.service.Update(c.Request().Context(), goldSavingUpdate, goldSavingID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.GoldSaving]{
+		Status:  "success",
+		Message: "gold saving updated",
+		Data:    goldSaving,
+	})
+}
+
+func (gc *GoldSavingController) Delete(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var goldSavingID string = c.Param("id")
+
+	err = gc.service.Delete(c.Request().Context(), goldSavingID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "gold saving deleted",
+	})
+}
+
+func (gc *GoldSavingController) Deposit(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var goldSavingID string = c.Param("id")
+
+	var depositInput models.GoldDepositInput
+
+	if err := c.Bind(&depositInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(depositInput); err != nil {
+		return validationError(err)
+	}
+
+	deposit, err := gc.service.Deposit(c.Request().Context(), depositInput, goldSavingID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.GoldDeposit]{
+		Status:  "success",
+		Message: "gold deposited",
+		Data:    deposit,
+	})
+}
+
+func (gc *GoldSavingController) DeleteDeposit(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	err = gc.service.DeleteDeposit(c.Request().Context(), c.Param("id"), c.Param("deposit_id"), userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "gold deposit deleted",
+	})
+}
+
+func (gc *GoldSavingController) GetValuation(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	valuation, err := gc.service.Valuation(c.Request().Context(), c.Param("id"), userID, c.QueryParam("locale"))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.GoldValuation]{
+		Status:  "success",
+		Message: "gold saving valuation",
+		Data:    valuation,
+	})
+}
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"keuangan-pribadi/marketdata"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var goldSavingController GoldSavingController
+
+// goldPrices is the provider behind the gold price of goldSavingController.
+var goldPrices *marketdata.Fake
+
+func InitGoldSavingEcho() *echo.Echo {
+	db := initTestDB()
+	goldPrices = marketdata.NewFake()
+	marketData := marketdata.NewCache(goldPrices, repositories.InitPriceRepository(db), time.Hour)
+	goldSavingController = InitGoldSavingController(services.InitGoldSavingService(repositories.InitGoldSavingRepository(db), marketData))
+
+	e := echo.New()
+
+	return e
+}
+
+// goldSavingRequest calls handler as userID, params are the names and values
+// of the path parameters.
+func goldSavingRequest(t *testing.T, e *echo.Echo, handler echo.HandlerFunc, method, path, body string, userID uint, params ...string) *httptest.ResponseRecorder {
+	token, _ := middleware.CreateToken(userID, "test", models.RoleUser)
+
+	req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	var names, values []string
+	for i := 0; i+1 < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+
+	ctx.SetPath(path)
+	ctx.SetParamNames(names...)
+	ctx.SetParamValues(values...)
+
+	assert.NoError(t, handle(ctx, handler))
+
+	return recorder
+}
+
+func TestCreateGoldSaving_Success(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	user, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	recorder := goldSavingRequest(t, e, goldSavingController.Create, http.MethodPost, "/api/v1/gold-savings", `{"name":"emas","goal":"25.5"}`, user.ID)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	var response models.Response[models.GoldSaving]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, models.Grams(255000), response.Data.Goal)
+		assert.True(t, strings.Contains(recorder.Body.String(), "\"goal\":\"25.5000\""))
+	}
+}
+
+func TestCreateGoldSaving_Failed(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	user, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	for _, body := range []string{
+		`{"name":"emas"}`,
+		`{"name":"emas","goal":"1.23456"}`,
+		`{"name":"emas","goal":"-1"}`,
+	} {
+		recorder := goldSavingRequest(t, e, goldSavingController.Create, http.MethodPost, "/api/v1/gold-savings", body, user.ID)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, body)
+	}
+}
+
+func TestDepositGold_Success(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	id := strconv.Itoa(int(goldSaving.ID))
+
+	recorder := goldSavingRequest(t, e, goldSavingController.Deposit, http.MethodPost, "/api/v1/gold-savings/:id/deposits", `{"grams":"2.5","price":{"amount":"3000000","currency":"IDR"}}`, goldSaving.UserID, "id", id)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	// the saving adds up its deposits
+	recorder = goldSavingRequest(t, e, goldSavingController.GetByID, http.MethodGet, "/api/v1/gold-savings/:id", "", goldSaving.UserID, "id", id)
+
+	var response models.Response[models.GoldSaving]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, "12.5000", response.Data.Grams.String())
+		assert.Equal(t, "15000000.00", response.Data.Cost.Decimal())
+		assert.Len(t, response.Data.Deposits, 2)
+	}
+}
+
+func TestDepositGold_Concurrent(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	id := strconv.Itoa(int(goldSaving.ID))
+
+	// a deposit committed by another request after this one read the saving
+	// and before it wrote the totals
+	concurrent := models.GoldDeposit{GoldSavingID: goldSaving.ID, Grams: 10000, Price: money.New(120000000, "IDR"), PurchasedAt: time.Now(), UserID: goldSaving.UserID}
+	testDB.Create(&concurrent)
+
+	recorder := goldSavingRequest(t, e, goldSavingController.Deposit, http.MethodPost, "/api/v1/gold-savings/:id/deposits", `{"grams":"2.5","price":{"amount":"3000000","currency":"IDR"}}`, goldSaving.UserID, "id", id)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	var stored models.GoldSaving
+	testDB.First(&stored, goldSaving.ID)
+
+	assert.Equal(t, "13.5000", stored.Grams.String())
+	assert.Equal(t, "16200000.00", stored.Cost.Decimal())
+
+	// removing a deposit keeps the concurrent one counted as well
+	recorder = goldSavingRequest(t, e, goldSavingController.DeleteDeposit, http.MethodDelete, "/api/v1/gold-savings/:id/deposits/:deposit_id", "", goldSaving.UserID, "id", id, "deposit_id", strconv.Itoa(int(goldSaving.Deposits[0].ID)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	testDB.First(&stored, goldSaving.ID)
+
+	assert.Equal(t, "3.5000", stored.Grams.String())
+	assert.Equal(t, "4200000.00", stored.Cost.Decimal())
+}
+
+func TestDepositGold_Failed(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	id := strconv.Itoa(int(goldSaving.ID))
+
+	// the gold of the saving was bought in rupiah
+	recorder := goldSavingRequest(t, e, goldSavingController.Deposit, http.MethodPost, "/api/v1/gold-savings/:id/deposits", `{"grams":"1","price":{"amount":"80","currency":"USD"}}`, goldSaving.UserID, "id", id)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = goldSavingRequest(t, e, goldSavingController.Deposit, http.MethodPost, "/api/v1/gold-savings/:id/deposits", `{"grams":"1","price":"1200000"}`, goldSaving.UserID, "id", "0")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestDeleteGoldDeposit_Success(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	id := strconv.Itoa(int(goldSaving.ID))
+
+	recorder := goldSavingRequest(t, e, goldSavingController.DeleteDeposit, http.MethodDelete, "/api/v1/gold-savings/:id/deposits/:deposit_id", "", goldSaving.UserID, "id", id, "deposit_id", strconv.Itoa(int(goldSaving.Deposits[0].ID)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = goldSavingRequest(t, e, goldSavingController.GetByID, http.MethodGet, "/api/v1/gold-savings/:id", "", goldSaving.UserID, "id", id)
+
+	var response models.Response[models.GoldSaving]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, models.Grams(0), response.Data.Grams)
+		assert.Empty(t, response.Data.Deposits)
+	}
+}
+
+func TestDeleteGoldDeposit_Failed(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	other, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	// a deposit of another saving
+	recorder := goldSavingRequest(t, e, goldSavingController.DeleteDeposit, http.MethodDelete, "/api/v1/gold-savings/:id/deposits/:deposit_id", "", goldSaving.UserID, "id", strconv.Itoa(int(goldSaving.ID)), "deposit_id", strconv.Itoa(int(other.Deposits[0].ID)))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestGetGoldValuation_Success(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	recorder := goldSavingRequest(t, e, goldSavingController.GetValuation, http.MethodGet, "/api/v1/gold-savings/:id/valuation?locale=en-US", "", goldSaving.UserID, "id", strconv.Itoa(int(goldSaving.ID)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response models.Response[models.GoldValuation]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		valuation := response.Data
+
+		// 38.400.000 rupiah a troy ounce of 31.1034768 g
+		assert.Equal(t, "1234588.67", valuation.PricePerGram.Decimal())
+		assert.Equal(t, "12345886.68", valuation.Value.Decimal())
+		assert.Equal(t, "12000000.00", valuation.Cost.Decimal())
+		assert.Equal(t, "345886.68", valuation.UnrealizedGain.Decimal())
+		assert.Equal(t, "IDR", valuation.Value.Currency)
+		assert.Equal(t, 2.88, valuation.UnrealizedGainPercent)
+		assert.Equal(t, 10.0, valuation.GoalPercent)
+		assert.True(t, strings.Contains(valuation.Formatted.Value, "12,345,886.68"))
+		assert.False(t, valuation.Stale)
+	}
+}
+
+func TestGetGoldValuation_Failed(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	// no gold price in singapore dollars was ever fetched
+	testDB.Model(&models.GoldSaving{}).Where("id = ?", goldSaving.ID).Update("cost_currency", "SGD")
+
+	goldPrices.SetErr(errors.New("network is unreachable"))
+
+	recorder := goldSavingRequest(t, e, goldSavingController.GetValuation, http.MethodGet, "/api/v1/gold-savings/:id/valuation", "", goldSaving.UserID, "id", strconv.Itoa(int(goldSaving.ID)))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\"market_data_unavailable\""))
+
+	recorder = goldSavingRequest(t, e, goldSavingController.GetValuation, http.MethodGet, "/api/v1/gold-savings/:id/valuation", "", goldSaving.UserID, "id", "0")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HealthController struct {
+	service services.HealthService
+}
+
+func InitHealthController(service services.HealthService) HealthController {
+	return HealthController{
+		service: service,
+	}
+}
+
+// Live answers as long as the process serves requests, it checks nothing
+// else so a slow database does not get the process restarted.
+func (hc *HealthController) Live(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "alive",
+	})
+}
+
+// Ready tells if the instance can take traffic.
+func (hc *HealthController) Ready(c echo.Context) error {
+	health, err := hc.service.Ready(c.Request().Context())
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Health]{
+		Status:  "success",
+		Message: "ready",
+		Data:    health,
+	})
+}
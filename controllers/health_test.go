@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/config"
+	"keuangan-pribadi/metrics"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/migrations"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func initHealthController(t *testing.T, migrated bool) (HealthController, *gorm.DB) {
+	db := initTestDB()
+
+	if !migrated {
+		// a database nobody has migrated yet
+		fresh, err := config.OpenDB(config.DriverSQLite, "file::memory:", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		db = fresh
+	}
+
+	return InitHealthController(services.InitHealthService(repositories.InitHealthRepository(db))), db
+}
+
+func TestGetLiveness_Success(t *testing.T) {
+	healthController, _ := initHealthController(t, true)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, healthController.Live)) {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+}
+
+func TestGetReadiness_Success(t *testing.T) {
+	healthController, _ := initHealthController(t, true)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, healthController.Ready)) {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response models.Response[models.Health]
+		if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+			assert.Equal(t, "up", response.Data.Database)
+			assert.NotZero(t, response.Data.SchemaVersion)
+		}
+	}
+}
+
+func TestGetReadiness_Failed(t *testing.T) {
+	healthController, db := initHealthController(t, false)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	if assert.NoError(t, handle(ctx, healthController.Ready)) {
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\"migrations_pending\""))
+		assert.True(t, strings.Contains(recorder.Body.String(), fmt.Sprintf("%d migrations are not applied yet", migrations.Latest())))
+	}
+
+	// the probe only reads, the database is left for the migrations
+	assert.False(t, db.Migrator().HasTable("schema_migrations"))
+}
+
+func TestGetMetrics_Success(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.Use(middleware.Metrics())
+	e.GET("/api/v1/finances/:id", func(c echo.Context) error {
+		return models.NotFoundError("finance_not_found", "finance not found")
+	})
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/finances/42", nil))
+
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+
+	// the route pattern and the status written by the error handler are used
+	assert.True(t, strings.Contains(body, `keuangan_http_requests_total{method="GET",route="/api/v1/finances/:id",status="404"}`))
+	assert.True(t, strings.Contains(body, `keuangan_http_request_duration_seconds_bucket{method="GET",route="/api/v1/finances/:id",status="404"`))
+	assert.True(t, strings.Contains(body, `keuangan_business_events_total{event="saving.goal_reached"}`))
+}
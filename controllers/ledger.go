@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"github.com/labstack/echo/v4"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+)
+
+type LedgerController struct {
+	service services.LedgerService
+}
+
+func InitLedgerController(service services.LedgerService) LedgerController {
+	return LedgerController{
+		service: service,
+	}
+}
+
+func (lc *LedgerController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ledgers, err := lc.service.GetAll(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.Ledger]{
+		Status:  "success",
+		Message: "all ledgers",
+		Data:    ledgers,
+	})
+}
+
+func (lc *LedgerController) GetByID(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var ledgerID string = c.Param("id")
+
+	ledger, err := lc.service.GetByID(c.Request().Context(), ledgerID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Ledger]{
+		Status:  "success",
+		Message: "ledger found",
+		Data:    ledger,
+	})
+}
+
+func (lc *LedgerController) Create(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var ledgerInput models.LedgerInput
+
+	if err := c.Bind(&ledgerInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(ledgerInput); err != nil {
+		return validationError(err)
+	}
+
+	ledger, err := lc.service.Create(c.Request().Context(), ledgerInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.Ledger]{
+		Status:  "success",
+		Message: "ledger created",
+		Data:    ledger,
+	})
+}
+
+func (lc *LedgerController) Update(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var ledgerInput models.LedgerInput
+
+	if err := c.Bind(&ledgerInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(ledgerInput); err != nil {
+		return validationError(err)
+	}
+
+	var ledgerID string = c.Param("id")
+
+	ledger, err := lc.service.Update(c.Request().Context(), ledgerInput, ledgerID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Ledger]{
+		Status:  "success",
+		Message: "ledger updated",
+		Data:    ledger,
+	})
+}
+
+func (lc *LedgerController) Delete(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var ledgerID string = c.Param("id")
+
+	err = lc.service.Delete(c.Request().Context(), ledgerID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "ledger deleted",
+	})
+}
+
+func (lc *LedgerController) UpdateMember(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var memberInput models.LedgerMemberInput
+
+	if err := c.Bind(&memberInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(memberInput); err != nil {
+		return validationError(err)
+	}
+
+	var ledgerID string = c.Param("id")
+	var memberID string = c.Param("user_id")
+
+	member, err := lc.service.UpdateMember(c.Request().Context(), memberInput, ledgerID, memberID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.LedgerMember]{
+		Status:  "success",
+		Message: "member updated",
+		Data:    member,
+	})
+}
+
+func (lc *LedgerController) RemoveMember(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var ledgerID string = c.Param("id")
+	var memberID string = c.Param("user_id")
+
+	err = lc.service.RemoveMember(c.Request().Context(), ledgerID, memberID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "member removed",
+	})
+}
+
+func (lc *LedgerController) Invite(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var invitationInput models.LedgerInvitationInput
+
+	if err := c.Bind(&invitationInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(invitationInput); err != nil {
+		return validationError(err)
+	}
+
+	var ledgerID string = c.Param("id")
+
+	invitation, err := lc.service.Invite(c.Request().Context(), invitationInput, ledgerID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.LedgerInvitation]{
+		Status:  "success",
+		Message: "invitation sent",
+		Data:    invitation,
+	})
+}
+
+func (lc *LedgerController) AcceptInvitation(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var acceptInput models.LedgerInvitationAccept
+
+	if err := c.Bind(&acceptInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(acceptInput); err != nil {
+		return validationError(err)
+	}
+
+	member, err := lc.service.AcceptInvitation(c.Request().Context(), acceptInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.LedgerMember]{
+		Status:  "success",
+		Message: "invitation accepted",
+		Data:    member,
+	})
+}
+
+func (lc *LedgerController) GetCategories(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var ledgerID string = c.Param("id")
+
+	categories, err := lc.service.GetCategories(c.Request().Context(), ledgerID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.Category]{
+		Status:  "success",
+		Message: "all categories of the ledger",
+		Data:    categories,
+	})
+}
+
+func (lc *LedgerController) CreateCategory(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var categoryInput models.CategoryInput
+
+	if err := c.Bind(&categoryInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(categoryInput); err != nil {
+		return validationError(err)
+	}
+
+	var ledgerID string = c.Param("id")
+
+	category, err := lc.service.CreateCategory(c.Request().Context(), categoryInput, ledgerID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.Category]{
+		Status:  "success",
+		Message: "category created",
+		Data:    category,
+	})
+}
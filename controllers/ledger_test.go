@@ -0,0 +1,835 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCaseLedger struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var ledgerController LedgerController
+
+func InitLedgerEcho() *echo.Echo {
+	db := initTestDB()
+	ledgerController = InitLedgerController(services.InitLedgerService(repositories.InitLedgerRepository(db, testConfig.Mail.Mailer(), testConfig.Auth)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetAllLedgers_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, ledgerController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetAllLedgers_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, ledgerController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetLedger_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(ledger.Members[0].UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.GetByID)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetLedger_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, ledgerController.GetByID)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateLedger_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var ledgerInput models.LedgerInput = models.LedgerInput{
+		Name: "household",
+	}
+
+	jsonBody, _ := json.Marshal(&ledgerInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, ledgerController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateLedger_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var ledgerInput models.LedgerInput = models.LedgerInput{}
+
+	jsonBody, _ := json.Marshal(&ledgerInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, ledgerController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateLedger_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(ledger.Members[0].UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var ledgerInput models.LedgerInput = models.LedgerInput{
+		Name: "family",
+	}
+
+	jsonBody, _ := json.Marshal(&ledgerInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateLedger_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var ledgerInput models.LedgerInput = models.LedgerInput{
+		Name: "family",
+	}
+
+	jsonBody, _ := json.Marshal(&ledgerInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, ledgerController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteLedger_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(ledger.Members[0].UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteLedger_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers",
+		expectedStatus:         http.StatusForbidden,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	ledger, _ := repositories.PersonalLedger(testDB, user.ID)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestInviteLedgerMember_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers/invitations",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(ledger.Members[0].UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var invitationInput models.LedgerInvitationInput = models.LedgerInvitationInput{
+		Email: "partner@gmail.com",
+		Role:  models.LedgerRoleEditor,
+	}
+
+	jsonBody, _ := json.Marshal(&invitationInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.Invite)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestInviteLedgerMember_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers/invitations",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(ledger.Members[0].UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var invitationInput models.LedgerInvitationInput = models.LedgerInvitationInput{
+		Email: "partner@gmail.com",
+		Role:  models.LedgerRoleOwner,
+	}
+
+	jsonBody, _ := json.Marshal(&invitationInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.Invite)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestAcceptLedgerInvitation_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers/invitations/accept",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var acceptInput models.LedgerInvitationAccept = models.LedgerInvitationAccept{
+		Token: "invalid",
+	}
+
+	jsonBody, _ := json.Marshal(&acceptInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, ledgerController.AcceptInvitation)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetLedgerCategories_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers/categories",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(ledger.Members[0].UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.GetCategories)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetLedgerCategories_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers/categories",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, ledgerController.GetCategories)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateLedgerCategory_Success(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "success",
+		path:                   "/api/v1/ledgers/categories",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(ledger.Members[0].UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var categoryInput models.CategoryInput = models.CategoryInput{
+		Name: "groceries",
+	}
+
+	jsonBody, _ := json.Marshal(&categoryInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(ledger.ID)))
+
+	if assert.NoError(t, handle(ctx, ledgerController.CreateCategory)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateLedgerCategory_Failed(t *testing.T) {
+	testcase := testCaseLedger{
+		name:                   "failed",
+		path:                   "/api/v1/ledgers/categories",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitLedgerEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var categoryInput models.CategoryInput = models.CategoryInput{
+		Name: "groceries",
+	}
+
+	jsonBody, _ := json.Marshal(&categoryInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, ledgerController.CreateCategory)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+// addLedgerMember seeds a user who joined the ledger with the role.
+func addLedgerMember(t *testing.T, ledgerID uint, role string) models.User {
+	user, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	if err := testDB.Create(&models.LedgerMember{LedgerID: ledgerID, UserID: user.ID, Role: role}).Error; err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	return user
+}
+
+// ledgerRoles maps the members of a ledger to their role.
+func ledgerRoles(t *testing.T, ledgerID uint) map[uint]string {
+	var members []models.LedgerMember
+	if err := testDB.Where("ledger_id = ?", ledgerID).Find(&members).Error; err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	roles := map[uint]string{}
+	for _, member := range members {
+		roles[member.UserID] = member.Role
+	}
+
+	return roles
+}
+
+func TestUpdateLedgerMember_Success(t *testing.T) {
+	InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	owner := ledger.Members[0].UserID
+	member := addLedgerMember(t, ledger.ID, models.LedgerRoleViewer)
+
+	recorder := request(t, ledgerController.UpdateMember, http.MethodPut, "/api/v1/ledgers/:id/members/:user_id", `{"role":"editor"}`, bearer(owner), "id", strconv.Itoa(int(ledger.ID)), "user_id", strconv.Itoa(int(member.ID)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response models.Response[models.LedgerMember]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, member.ID, response.Data.UserID)
+		assert.Equal(t, models.LedgerRoleEditor, response.Data.Role)
+	}
+
+	assert.Equal(t, map[uint]string{owner: models.LedgerRoleOwner, member.ID: models.LedgerRoleEditor}, ledgerRoles(t, ledger.ID))
+}
+
+func TestUpdateLedgerMember_Failed(t *testing.T) {
+	InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	owner := ledger.Members[0].UserID
+	editor := addLedgerMember(t, ledger.ID, models.LedgerRoleEditor)
+	viewer := addLedgerMember(t, ledger.ID, models.LedgerRoleViewer)
+	id := strconv.Itoa(int(ledger.ID))
+
+	for _, tc := range []struct {
+		caller uint
+		target uint
+		status int
+		code   string
+	}{
+		// only the owner manages the members
+		{editor.ID, viewer.ID, http.StatusForbidden, "not_ledger_owner"},
+		{viewer.ID, viewer.ID, http.StatusForbidden, "not_ledger_owner"},
+		{owner, owner, http.StatusForbidden, "owner_role"},
+		{owner, 0, http.StatusNotFound, "ledger_member_not_found"},
+	} {
+		recorder := request(t, ledgerController.UpdateMember, http.MethodPut, "/api/v1/ledgers/:id/members/:user_id", `{"role":"editor"}`, bearer(tc.caller), "id", id, "user_id", strconv.Itoa(int(tc.target)))
+
+		assert.Equal(t, tc.status, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "\"code\":\""+tc.code+"\"")
+	}
+
+	assert.Equal(t, map[uint]string{owner: models.LedgerRoleOwner, editor.ID: models.LedgerRoleEditor, viewer.ID: models.LedgerRoleViewer}, ledgerRoles(t, ledger.ID))
+}
+
+func TestRemoveLedgerMember_Success(t *testing.T) {
+	InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	owner := ledger.Members[0].UserID
+	removed := addLedgerMember(t, ledger.ID, models.LedgerRoleEditor)
+	leaving := addLedgerMember(t, ledger.ID, models.LedgerRoleViewer)
+	id := strconv.Itoa(int(ledger.ID))
+
+	// the owner removes a member
+	recorder := request(t, ledgerController.RemoveMember, http.MethodDelete, "/api/v1/ledgers/:id/members/:user_id", "", bearer(owner), "id", id, "user_id", strconv.Itoa(int(removed.ID)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, map[uint]string{owner: models.LedgerRoleOwner, leaving.ID: models.LedgerRoleViewer}, ledgerRoles(t, ledger.ID))
+
+	// a member leaves on their own
+	recorder = request(t, ledgerController.RemoveMember, http.MethodDelete, "/api/v1/ledgers/:id/members/:user_id", "", bearer(leaving.ID), "id", id, "user_id", strconv.Itoa(int(leaving.ID)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, map[uint]string{owner: models.LedgerRoleOwner}, ledgerRoles(t, ledger.ID))
+}
+
+func TestRemoveLedgerMember_Failed(t *testing.T) {
+	InitLedgerEcho()
+
+	ledger, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	owner := ledger.Members[0].UserID
+	editor := addLedgerMember(t, ledger.ID, models.LedgerRoleEditor)
+	viewer := addLedgerMember(t, ledger.ID, models.LedgerRoleViewer)
+	id := strconv.Itoa(int(ledger.ID))
+
+	for _, tc := range []struct {
+		caller uint
+		target uint
+		status int
+		code   string
+	}{
+		// a member cannot remove somebody else, nor the owner leave
+		{editor.ID, viewer.ID, http.StatusForbidden, "not_ledger_owner"},
+		{viewer.ID, owner, http.StatusForbidden, "not_ledger_owner"},
+		{owner, owner, http.StatusConflict, "owner_cannot_leave"},
+	} {
+		recorder := request(t, ledgerController.RemoveMember, http.MethodDelete, "/api/v1/ledgers/:id/members/:user_id", "", bearer(tc.caller), "id", id, "user_id", strconv.Itoa(int(tc.target)))
+
+		assert.Equal(t, tc.status, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "\"code\":\""+tc.code+"\"")
+	}
+
+	assert.Equal(t, map[uint]string{owner: models.LedgerRoleOwner, editor.ID: models.LedgerRoleEditor, viewer.ID: models.LedgerRoleViewer}, ledgerRoles(t, ledger.ID))
+}
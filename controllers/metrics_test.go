@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"keuangan-pribadi/metrics"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// scrapeMetrics is what Prometheus reads from /metrics.
+func scrapeMetrics(t *testing.T) string {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	recorder := httptest.NewRecorder()
+
+	metrics.Handler().ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("scraping the metrics: %d", recorder.Code)
+	}
+
+	return recorder.Body.String()
+}
+
+func TestMetrics_Success(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.Use(middleware.Metrics())
+
+	e.GET("/metrics-test/finances/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, models.Response[string]{Status: "success", Message: "ok"})
+	})
+	e.GET("/metrics-test/finances/:id/failed", func(c echo.Context) error {
+		return models.NotFoundError("finance_not_found", "finance not found")
+	})
+
+	for _, path := range []string{"/metrics-test/finances/1", "/metrics-test/finances/2", "/metrics-test/finances/1/failed"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	scraped := scrapeMetrics(t)
+
+	// one series per route pattern, and the status of a returned error
+	assert.Contains(t, scraped, `keuangan_http_requests_total{method="GET",route="/metrics-test/finances/:id",status="200"} 2`)
+	assert.Contains(t, scraped, `keuangan_http_requests_total{method="GET",route="/metrics-test/finances/:id/failed",status="404"} 1`)
+	assert.Contains(t, scraped, `keuangan_http_request_duration_seconds_count{method="GET",route="/metrics-test/finances/:id",status="200"} 2`)
+	assert.NotContains(t, scraped, `route="/metrics-test/finances/1"`)
+
+	assert.Contains(t, scraped, "go_goroutines")
+}
+
+func TestMetrics_Events(t *testing.T) {
+	before := scrapeMetrics(t)
+
+	// every event is there before it happens
+	for _, event := range models.Events {
+		assert.Contains(t, before, `keuangan_business_events_total{event="`+event+`"}`)
+	}
+
+	metrics.RecordEvent("metrics_test.recorded")
+	metrics.RecordEvent("metrics_test.recorded")
+
+	assert.Contains(t, scrapeMetrics(t), `keuangan_business_events_total{event="metrics_test.recorded"} 2`)
+}
+
+// eventCount is how often the event happened so far.
+func eventCount(t *testing.T, event string) int {
+	prefix := `keuangan_business_events_total{event="` + event + `"} `
+
+	for _, line := range strings.Split(scrapeMetrics(t), "\n") {
+		if count, ok := strings.CutPrefix(line, prefix); ok {
+			n, _ := strconv.Atoi(count)
+			return n
+		}
+	}
+
+	return 0
+}
+
+func TestMetrics_EventsWithoutWebhook(t *testing.T) {
+	e := InitGoldSavingEcho()
+
+	goldSaving, err := testutil.SeedGoldSaving(testDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reached := eventCount(t, models.EventGoldSavingGoalReached)
+	id := strconv.Itoa(int(goldSaving.ID))
+
+	// 10 g of 100 g, the second deposit reaches the goal and the third one is past it
+	for _, grams := range []string{"40", "50", "5"} {
+		recorder := goldSavingRequest(t, e, goldSavingController.Deposit, http.MethodPost, "/api/v1/gold-savings/:id/deposits", `{"grams":"`+grams+`","price":{"amount":"1000000","currency":"IDR"}}`, goldSaving.UserID, "id", id)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	}
+
+	assert.Equal(t, reached+1, eventCount(t, models.EventGoldSavingGoalReached))
+
+	InitBackupEcho()
+
+	restored := eventCount(t, models.EventBackupRestored)
+
+	body := `{"version":` + strconv.Itoa(models.BackupVersion) + `}`
+	recorder := request(t, backupController.Restore, http.MethodPost, "/api/v1/backups/restore", body, bearer(goldSaving.UserID))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, restored+1, eventCount(t, models.EventBackupRestored))
+}
+
+func TestMetrics_RegisterDB(t *testing.T) {
+	sqlDB, err := initTestDB().DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.NoError(t, metrics.RegisterDB(sqlDB, "metrics_test")) {
+		assert.Contains(t, scrapeMetrics(t), `go_sql_open_connections{db_name="metrics_test"}`)
+	}
+
+	// the same pool cannot be exported twice under one name
+	assert.Error(t, metrics.RegisterDB(sqlDB, "metrics_test"))
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"github.com/labstack/echo/v4"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+)
+
+type NotificationController struct {
+	service services.NotificationService
+}
+
+func InitNotificationController(service services.NotificationService) NotificationController {
+	return NotificationController{
+		service: service,
+	}
+}
+
+func (nc *NotificationController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var filter models.NotificationFilter
+
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &filter); err != nil {
+		return errInvalidRequest
+	}
+
+	notifications, err := nc.service.GetAll(c.Request().Context(), filter, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.Notification]{
+		Status:  "success",
+		Message: "all notifications",
+		Data:    notifications,
+	})
+}
+
+func (nc *NotificationController) MarkRead(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var notificationID string = c.Param("id")
+
+	notification, err := nc.service.MarkRead(c.Request().Context(), notificationID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Notification]{
+		Status:  "success",
+		Message: "notification marked as read",
+		Data:    notification,
+	})
+}
+
+func (nc *NotificationController) MarkAllRead(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	err = nc.service.MarkAllRead(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "all notifications marked as read",
+	})
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCaseNotification struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var notificationController NotificationController
+
+func InitNotificationEcho() *echo.Echo {
+	db := initTestDB()
+	notificationController = InitNotificationController(services.InitNotificationService(repositories.InitNotificationRepository(db)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetAllNotifications_Success(t *testing.T) {
+	testcase := testCaseNotification{
+		name:                   "success",
+		path:                   "/api/v1/notifications?unread=true",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitNotificationEcho()
+
+	notification, err := testutil.SeedNotification(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(notification.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, notificationController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetAllNotifications_Failed(t *testing.T) {
+	testcase := testCaseNotification{
+		name:                   "failed",
+		path:                   "/api/v1/notifications",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitNotificationEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, notificationController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestMarkNotificationRead_Success(t *testing.T) {
+	testcase := testCaseNotification{
+		name:                   "success",
+		path:                   "/api/v1/notifications/read",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitNotificationEcho()
+
+	notification, err := testutil.SeedNotification(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(notification.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(notification.ID)))
+
+	if assert.NoError(t, handle(ctx, notificationController.MarkRead)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestMarkNotificationRead_Failed(t *testing.T) {
+	testcase := testCaseNotification{
+		name:                   "failed",
+		path:                   "/api/v1/notifications/read",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitNotificationEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, notificationController.MarkRead)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestMarkAllNotificationsRead_Success(t *testing.T) {
+	testcase := testCaseNotification{
+		name:                   "success",
+		path:                   "/api/v1/notifications/read",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitNotificationEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, notificationController.MarkAllRead)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestMarkAllNotificationsRead_Failed(t *testing.T) {
+	testcase := testCaseNotification{
+		name:                   "failed",
+		path:                   "/api/v1/notifications/read",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitNotificationEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, notificationController.MarkAllRead)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
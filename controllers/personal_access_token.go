@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"github.com/labstack/echo/v4"
+	m "keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+)
+
+type PersonalAccessTokenController struct {
+	service services.PersonalAccessTokenService
+}
+
+func InitPersonalAccessTokenController(service services.PersonalAccessTokenService) PersonalAccessTokenController {
+	return PersonalAccessTokenController{
+		service: service,
+	}
+}
+
+func (pc *PersonalAccessTokenController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := pc.service.GetAll(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.PersonalAccessToken]{
+		Status:  "success",
+		Message: "all tokens",
+		Data:    tokens,
+	})
+}
+
+func (pc *PersonalAccessTokenController) Create(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var tokenInput models.PersonalAccessTokenInput
+
+	if err := c.Bind(&tokenInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(tokenInput); err != nil {
+		return validationError(err)
+	}
+
+	// a token can only hand out the scopes it holds itself
+	if principal, ok := m.GetPrincipal(c); ok && principal.PersonalAccessToken != nil {
+		for _, scope := range tokenInput.Scopes {
+			if !principal.PersonalAccessToken.HasScope(scope) {
+				return models.ForbiddenError("scope_not_held", "token cannot grant the "+scope+" scope it does not hold")
+			}
+		}
+	}
+
+	createdToken, err := pc.service.Create(c.Request().Context(), tokenInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.PersonalAccessTokenResponse]{
+		Status:  "success",
+		Message: "token created, copy it now as it will not be shown again",
+		Data:    createdToken,
+	})
+}
+
+func (pc *PersonalAccessTokenController) Delete(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var tokenID string = c.Param("id")
+
+	err = pc.service.Delete(c.Request().Context(), tokenID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "token revoked",
+	})
+}
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCasePersonalAccessToken struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var personalAccessTokenController PersonalAccessTokenController
+
+func InitPersonalAccessTokenEcho() *echo.Echo {
+	db := initTestDB()
+	personalAccessTokenController = InitPersonalAccessTokenController(services.InitPersonalAccessTokenService(repositories.InitPersonalAccessTokenRepository(db)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetAllPersonalAccessTokens_Success(t *testing.T) {
+	testcase := testCasePersonalAccessToken{
+		name:                   "success",
+		path:                   "/api/v1/tokens",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitPersonalAccessTokenEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, personalAccessTokenController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetAllPersonalAccessTokens_Failed(t *testing.T) {
+	testcase := testCasePersonalAccessToken{
+		name:                   "failed",
+		path:                   "/api/v1/tokens",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitPersonalAccessTokenEcho()
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", "")
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, personalAccessTokenController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreatePersonalAccessToken_Success(t *testing.T) {
+	testcase := testCasePersonalAccessToken{
+		name:                   "success",
+		path:                   "/api/v1/tokens",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitPersonalAccessTokenEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var tokenInput models.PersonalAccessTokenInput = models.PersonalAccessTokenInput{
+		Name:          "spreadsheet",
+		Scopes:        []string{"finances:read"},
+		ExpiresInDays: 30,
+	}
+
+	jsonBody, _ := json.Marshal(&tokenInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	request := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	recorder := httptest.NewRecorder()
+
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Authorization", tokenString)
+
+	ctx := e.NewContext(request, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, personalAccessTokenController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreatePersonalAccessToken_ScopeNotHeld(t *testing.T) {
+	InitPersonalAccessTokenEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+
+	recorder := request(t, personalAccessTokenController.Create, http.MethodPost, "/api/v1/tokens", `{"name":"token manager","scopes":["tokens:write"]}`, bearer(user.ID))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	var created models.Response[models.PersonalAccessTokenResponse]
+	if !assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created)) {
+		return
+	}
+
+	pat := "Bearer " + created.Data.Token
+
+	// a token cannot grant more than it holds
+	recorder = request(t, personalAccessTokenController.Create, http.MethodPost, "/api/v1/tokens", `{"name":"escalated","scopes":["tokens:read","finances:write"]}`, pat)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\"scope_not_held\""))
+
+	var count int64
+	testDB.Model(&models.PersonalAccessToken{}).Where("user_id = ? AND name = ?", user.ID, "escalated").Count(&count)
+
+	assert.Equal(t, int64(0), count)
+
+	// the write scope holds the read scope
+	recorder = request(t, personalAccessTokenController.Create, http.MethodPost, "/api/v1/tokens", `{"name":"reader","scopes":["tokens:read"]}`, pat)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	var stored models.PersonalAccessToken
+	if assert.NoError(t, testDB.Where("user_id = ? AND name = ?", user.ID, "reader").First(&stored).Error) {
+		assert.Equal(t, []string{"tokens:read"}, stored.Scopes)
+	}
+}
+
+func TestCreatePersonalAccessToken_Failed(t *testing.T) {
+	testcase := testCasePersonalAccessToken{
+		name:                   "failed",
+		path:                   "/api/v1/tokens",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitPersonalAccessTokenEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var tokenInput models.PersonalAccessTokenInput = models.PersonalAccessTokenInput{
+		Name:   "spreadsheet",
+		Scopes: []string{"finances:delete"},
+	}
+
+	jsonBody, _ := json.Marshal(&tokenInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	request := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	recorder := httptest.NewRecorder()
+
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Authorization", tokenString)
+
+	ctx := e.NewContext(request, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, personalAccessTokenController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeletePersonalAccessToken_Success(t *testing.T) {
+	testcase := testCasePersonalAccessToken{
+		name:                   "success",
+		path:                   "/api/v1/tokens",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitPersonalAccessTokenEcho()
+
+	pat, err := testutil.SeedPersonalAccessToken(testDB)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(pat.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(pat.ID)))
+
+	if assert.NoError(t, handle(ctx, personalAccessTokenController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeletePersonalAccessToken_Failed(t *testing.T) {
+	testcase := testCasePersonalAccessToken{
+		name:                   "failed",
+		path:                   "/api/v1/tokens",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitPersonalAccessTokenEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, personalAccessTokenController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
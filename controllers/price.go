@@ -1,73 +1,56 @@
 package controllers
 
 import (
-	"encoding/json"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
 	"net/http"
-	"strconv"
 
 	"github.com/labstack/echo/v4"
-	"github.com/leekchan/accounting"
 )
 
-type CurrencyRatesResponse struct {
-    RealtimeCurrencyExchangeRate struct {
-        FromCurrencyCode string `json:"1. From_Currency Code"`
-        FromCurrencyName string `json:"2. From_Currency Name"`
-        ToCurrencyCode   string `json:"3. To_Currency Code"`
-        ToCurrencyName   string `json:"4. To_Currency Name"`
-        ExchangeRate     string `json:"5. Exchange Rate"`
-        LastRefreshed    string `json:"6. Last Refreshed"`
-        TimeZone         string `json:"7. Time Zone"`
-        BidPrice         string `json:"8. Bid Price"`
-        AskPrice         string `json:"9. Ask Price"`
-    } `json:"Realtime Currency Exchange Rate"`
+type PriceController struct {
+	service services.PriceService
 }
 
-func GetCoffeePrice(c echo.Context) error {
-    resp, err := http.Get("https://www.alphavantage.co/query?function=COFFEE&interval=monthly&apikey=R5KS7LRJO82IIQ55")
-    if err != nil {
-        return c.JSON(http.StatusInternalServerError, models.CoffeePriceResponse{})
-    }
-    defer resp.Body.Close()
+func InitPriceController(service services.PriceService) PriceController {
+	return PriceController{
+		service: service,
+	}
+}
+
+// GetCoffeePrice answers the monthly coffee prices in the currency of the
+// query, the default currency without one.
+func (pc *PriceController) GetCoffeePrice(c echo.Context) error {
+	prices, err := pc.service.Coffee(c.Request().Context(), c.QueryParam("currency"), c.QueryParam("locale"))
 
-    var data struct {
-        Name     string          `json:"name"`
-        Interval string          `json:"interval"`
-        Unit     string          `json:"unit"`
-        Data     []json.RawMessage `json:"data"`
-    }
-    if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-        return c.JSON(http.StatusInternalServerError, models.CoffeePriceResponse{})
-    }
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, prices)
+}
 
-	rateResp, _ := http.Get("https://www.alphavantage.co/query?function=CURRENCY_EXCHANGE_RATE&from_currency=GBP&to_currency=IDR&apikey=R5KS7LRJO82IIQ55")
-	var rpData CurrencyRatesResponse
-    if err := json.NewDecoder(rateResp.Body).Decode(&rpData); err != nil {
-        return c.JSON(http.StatusInternalServerError, nil)
-    }
+func (pc *PriceController) GetCommodity(c echo.Context) error {
+	var filter models.CommodityPriceFilter
 
-    var coffeePrices []models.CoffeePrice
-    for _, elem := range data.Data {
-        var coffeePrice models.CoffeePrice
-        if err := json.Unmarshal(elem, &coffeePrice); err != nil {
-            return c.JSON(http.StatusInternalServerError, models.CoffeePriceResponse{})
-        }
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &filter); err != nil {
+		return errInvalidRequest
+	}
 
-		priceValueFloat, _ := strconv.ParseFloat(coffeePrice.Value, 64)
-		rp, _ := strconv.ParseFloat(rpData.RealtimeCurrencyExchangeRate.ExchangeRate, 64)
-		res := priceValueFloat * rp
-		formatRp := accounting.Accounting{Symbol: "Rp. ", Precision: 2, Thousand: ".", Decimal: ","}
-		
-		coffeePrice.Value = formatRp.FormatMoney(res)
-        coffeePrices = append(coffeePrices, coffeePrice)
-    }
+	validate := newValidator()
+	if err := validate.Struct(filter); err != nil {
+		return validationError(err)
+	}
 
-    response := models.CoffeePriceResponse{
-        Name:     data.Name,
-        Interval: data.Interval,
-        Unit:     data.Unit,
-        Data:     coffeePrices,
-    }
-    return c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+	prices, err := pc.service.Commodity(c.Request().Context(), c.Param("symbol"), filter)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.CommodityPrices]{
+		Status:  "success",
+		Message: "commodity prices",
+		Data:    prices,
+	})
+}
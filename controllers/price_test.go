@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"keuangan-pribadi/marketdata"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// initPriceController caches the prices of the provider like the server
+// does, a new controller is like a restart with an empty cache.
+func initPriceController(provider marketdata.MarketDataProvider) PriceController {
+	history := repositories.InitPriceRepository(initTestDB())
+
+	return InitPriceController(services.InitPriceService(marketdata.NewCache(provider, history, time.Hour)))
+}
+
+func getCoffeePrice(t *testing.T, priceController PriceController, query string) (*httptest.ResponseRecorder, models.CoffeePriceResponse) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/coffee?"+query, nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	var response models.CoffeePriceResponse
+
+	if assert.NoError(t, handle(ctx, priceController.GetCoffeePrice)) && recorder.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	}
+
+	return recorder, response
+}
+
+func TestGetCoffeePrice_Success(t *testing.T) {
+	provider := marketdata.NewFake()
+	priceController := initPriceController(provider)
+
+	recorder, response := getCoffeePrice(t, priceController, "currency=IDR&locale=en-US")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "IDR", response.Currency)
+	assert.False(t, response.Stale)
+	if assert.Len(t, response.Data, 24) {
+		// 3 dollars a metric ton at 16000 rupiah a dollar
+		assert.Equal(t, "2022-01-01", response.Data[0].Date)
+		assert.True(t, strings.Contains(response.Data[0].Value, "48,000"))
+	}
+
+	// the second request is answered from the cache
+	calls := provider.Calls()
+	getCoffeePrice(t, priceController, "currency=IDR")
+	assert.Equal(t, calls, provider.Calls())
+
+	// after a restart with the provider down the stored prices are answered
+	provider.SetErr(errors.New("network is unreachable"))
+
+	recorder, response = getCoffeePrice(t, initPriceController(provider), "currency=IDR")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, response.Stale)
+	assert.Len(t, response.Data, 24)
+}
+
+func TestGetCoffeePrice_Failed(t *testing.T) {
+	provider := marketdata.NewFake()
+	provider.SetErr(errors.New("network is unreachable"))
+	priceController := initPriceController(provider)
+
+	// no rate to singapore dollars was ever fetched
+	recorder, _ := getCoffeePrice(t, priceController, "currency=SGD")
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\"market_data_unavailable\""))
+
+	recorder, _ = getCoffeePrice(t, priceController, "currency=XYZ")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func getCommodity(t *testing.T, priceController PriceController, symbol, query string) (*httptest.ResponseRecorder, models.CommodityPrices) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/commodities/"+symbol+"?"+query, nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+	ctx.SetPath("/api/v1/commodities/:symbol")
+	ctx.SetParamNames("symbol")
+	ctx.SetParamValues(symbol)
+
+	var response models.Response[models.CommodityPrices]
+
+	if assert.NoError(t, handle(ctx, priceController.GetCommodity)) && recorder.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	}
+
+	return recorder, response.Data
+}
+
+func TestGetCommodity_Success(t *testing.T) {
+	priceController := initPriceController(marketdata.NewFake())
+
+	// 5 dollars rising by 10 cents a quarter, at 0.9 euro a dollar
+	recorder, prices := getCommodity(t, priceController, "corn", "interval=quarterly&currency=eur&from=2022-04-01&to=2022-12-31")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "CORN", prices.Symbol)
+	assert.Equal(t, "quarterly", prices.Interval)
+	assert.Equal(t, "EUR", prices.Currency)
+	if assert.Len(t, prices.Data, 3) && assert.NotNil(t, prices.Stats) {
+		assert.Equal(t, "2022-04-01", prices.Data[0].Date)
+		assert.Equal(t, 4.59, prices.Data[0].Value)
+		assert.NotEmpty(t, prices.Data[0].Formatted)
+
+		assert.Equal(t, 4.59, prices.Stats.Min.Value)
+		assert.Equal(t, "2022-10-01", prices.Stats.Max.Date)
+		assert.Equal(t, 4.77, prices.Stats.Max.Value)
+		assert.Equal(t, 4.68, prices.Stats.Average.Value)
+		assert.Equal(t, 3.92, prices.Stats.PercentChange)
+	}
+
+	// a window without prices has no statistics
+	recorder, prices = getCommodity(t, priceController, "sugar", "from=2030-01-01")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, prices.Data)
+	assert.Nil(t, prices.Stats)
+}
+
+func TestGetCommodity_Failed(t *testing.T) {
+	priceController := initPriceController(marketdata.NewFake())
+
+	for _, tc := range []struct {
+		symbol string
+		query  string
+		status int
+		code   string
+	}{
+		{"gold", "", http.StatusNotFound, "commodity_not_found"},
+		{"coffee", "interval=daily", http.StatusBadRequest, "invalid_interval"},
+		{"coffee", "interval=hourly", http.StatusBadRequest, "validation_failed"},
+		{"coffee", "from=2024-13-01", http.StatusBadRequest, "invalid_date"},
+		{"coffee", "from=2024-02-01&to=2024-01-01", http.StatusBadRequest, "invalid_date"},
+	} {
+		recorder, _ := getCommodity(t, priceController, tc.symbol, tc.query)
+
+		assert.Equal(t, tc.status, recorder.Code, tc.query)
+		assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\""+tc.code+"\""), recorder.Body.String())
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/logging"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs sends the logs of the test to the returned buffer as JSON, one
+// record per line, and restores the default logger afterwards.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	logger, err := logging.New(&buf, "debug", logging.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf
+}
+
+func TestRequestID_Success(t *testing.T) {
+	e := InitFinanceEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/finances", nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Add(echo.HeaderXRequestID, "req-123")
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	handler := middleware.RequestID()(middleware.AccessLog()(func(c echo.Context) error {
+		return handle(c, financeController.GetAll)
+	}))
+
+	if assert.NoError(t, handler(ctx)) {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "req-123", recorder.Header().Get(echo.HeaderXRequestID))
+
+		lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+
+		// the queries of the request and its access log
+		assert.Greater(t, len(lines), 1)
+
+		for _, line := range lines {
+			var record map[string]interface{}
+
+			if assert.NoError(t, json.Unmarshal([]byte(line), &record)) {
+				assert.Equal(t, "req-123", record["request_id"])
+			}
+		}
+
+		var access map[string]interface{}
+
+		if assert.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &access)) {
+			assert.Equal(t, "request", access["msg"])
+			assert.Equal(t, float64(user.ID), access["user_id"])
+			assert.Equal(t, float64(http.StatusOK), access["status"])
+		}
+	}
+}
+
+func TestRequestID_Failed(t *testing.T) {
+	e := InitFinanceEcho()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/finances", nil)
+	req.Header.Add(echo.HeaderXRequestID, "not a valid id\nlevel=ERROR")
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	// without a token the request fails before reaching the handler
+	handler := middleware.RequestID()(middleware.AccessLog()(middleware.Authenticate(testDB)(financeController.GetAll)))
+
+	if assert.NoError(t, handle(ctx, handler)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+		id := recorder.Header().Get(echo.HeaderXRequestID)
+
+		assert.NotEqual(t, "not a valid id\nlevel=ERROR", id)
+		assert.NotEmpty(t, id)
+
+		var access map[string]interface{}
+
+		if assert.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(logs.String())), &access)) {
+			assert.Equal(t, id, access["request_id"])
+			assert.Equal(t, "WARN", access["level"])
+			assert.NotContains(t, access, "user_id")
+		}
+	}
+}
@@ -4,9 +4,7 @@ import (
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/services"
 	"net/http"
-	"strings"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
@@ -14,29 +12,22 @@ type SavingController struct {
 	service services.SavingService
 }
 
-func InitSavingController() SavingController {
+func InitSavingController(service services.SavingService) SavingController {
 	return SavingController{
-		service: services.InitSavingService(),
+		service: service,
 	}
 }
 
 func (fc *SavingController) GetAll(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
-	savings, err := fc.service.GetAll(token)
+	savings, err := fc.service.GetAll(c.Request().Context(), userID)
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.Response[string]{
-			Status:  "failed",
-			Message: "failed to fetch savings data",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[[]models.Saving]{
@@ -47,24 +38,17 @@ func (fc *SavingController) GetAll(c echo.Context) error {
 }
 
 func (fc *SavingController) GetByID(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var savingID string = c.Param("id")
 
-	saving, err := fc.service.GetByID(savingID, token)
+	saving, err := fc.service.GetByID(c.Request().Context(), savingID, userID)
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "saving not found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.Saving]{
@@ -75,39 +59,26 @@ func (fc *SavingController) GetByID(c echo.Context) error {
 }
 
 func (fc *SavingController) Create(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var savingInput models.SavingInput
 
 	if err := c.Bind(&savingInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(savingInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	saving, err := fc.service.Create(savingInput, token)
+	saving, err := fc.service.Create(c.Request().Context(), savingInput, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, models.Response[models.Saving]{
@@ -118,41 +89,28 @@ func (fc *SavingController) Create(c echo.Context) error {
 }
 
 func (fc *SavingController) Update(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var savingID string = c.Param("id")
 
 	var savingUpdate models.SavingUpdate
 
 	if err := c.Bind(&savingUpdate); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(savingUpdate); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	saving, err := fc.service.Update(savingUpdate, savingID, token)
+	saving, err := fc.service.Update(c.Request().Context(), savingUpdate, savingID, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.Saving]{
@@ -163,24 +121,17 @@ func (fc *SavingController) Update(c echo.Context) error {
 }
 
 func (fc *SavingController) Delete(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
 	var savingID string = c.Param("id")
 
-	err := fc.service.Delete(savingID, token)
+	err = fc.service.Delete(c.Request().Context(), savingID, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "Not Found",
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[string]{
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"keuangan-pribadi/config"
 	"keuangan-pribadi/middleware"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -24,10 +27,11 @@ type testCaseSaving struct {
 	expectedBodyStartsWith string
 }
 
-var savingController SavingController = InitSavingController()
+var savingController SavingController
 
 func InitSavingEcho() *echo.Echo {
-	config.InitDB()
+	db := initTestDB()
+	savingController = InitSavingController(services.InitSavingService(repositories.InitSavingRepository(db)))
 
 	e := echo.New()
 
@@ -44,8 +48,8 @@ func TestGetAllSavings_Success(t *testing.T) {
 
 	e := InitSavingEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -56,7 +60,7 @@ func TestGetAllSavings_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, savingController.GetAll(ctx)) {
+	if assert.NoError(t, handle(ctx, savingController.GetAll)) {
 		assert.Equal(t, http.StatusOK, recorder.Code)
 
 		body := recorder.Body.String()
@@ -69,7 +73,7 @@ func TestGetAllSavings_Failed(t *testing.T) {
 	testcase := testCaseSaving{
 		name:                   "failed",
 		path:                   "/api/v1/savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -83,8 +87,8 @@ func TestGetAllSavings_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, savingController.GetAll(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	if assert.NoError(t, handle(ctx, savingController.GetAll)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 
 		body := recorder.Body.String()
 
@@ -102,18 +106,18 @@ func TestCreateSaving_Success(t *testing.T) {
 
 	e := InitSavingEcho()
 
-	user, err := config.SeedUser()
+	user, err := testutil.SeedUser(testDB)
 	if err != nil {
 		t.Errorf("error: %v\n", err)
 	}
 
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	var savingInput models.SavingInput = models.SavingInput{
 		Name:      		"test",
-		Value: 			1,
-		Goal: 			25000,
+		Value: 			money.New(100, "IDR"),
+		Goal: 			money.New(2500000, "IDR"),
 		UserID: 			user.ID,
 	}
 
@@ -136,7 +140,7 @@ func TestCreateSaving_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, savingController.Create(ctx)) {
+	if assert.NoError(t, handle(ctx, savingController.Create)) {
 		assert.Equal(t, http.StatusCreated, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -149,7 +153,7 @@ func TestCreateSaving_Failed(t *testing.T) {
 	testcase := testCaseSaving {
 		name:                   "failed",
 		path:                   "/api/v1/savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -169,8 +173,8 @@ func TestCreateSaving_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, savingController.Create(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	if assert.NoError(t, handle(ctx, savingController.Create)) {
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 		body := recorder.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -187,7 +191,7 @@ func TestGetSavingByID_Success(t *testing.T) {
 
 	e := InitSavingEcho()
 
-	saving, err := config.SeedSaving()
+	saving, err := testutil.SeedSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -195,8 +199,8 @@ func TestGetSavingByID_Success(t *testing.T) {
 
 	savingID := strconv.Itoa(int(saving.ID))
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	request := httptest.NewRequest(http.MethodGet, testcase.path, nil)
@@ -210,7 +214,7 @@ func TestGetSavingByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(savingID)
 
-	if assert.NoError(t, savingController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, savingController.GetByID)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -229,7 +233,7 @@ func TestGetSavingByID_Failed(t *testing.T) {
 
 	e := InitSavingEcho()
 
-	saving, err := config.SeedSaving()
+	saving, err := testutil.SeedSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -248,7 +252,7 @@ func TestGetSavingByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(savingID)
 
-	if assert.NoError(t, savingController.GetByID(ctx)) {
+	if assert.NoError(t, handle(ctx, savingController.GetByID)) {
 		assert.Equal(t, http.StatusBadRequest, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -261,21 +265,21 @@ func TestUpdateSavingByID_Failed(t *testing.T) {
 	testcase := testCaseSaving{
 		name:                   "failed",
 		path:                   "/api/v1/savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusNotFound,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
 	e := InitSavingEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
-	saving, _ := config.SeedSaving()
+	saving, _ := testutil.SeedSaving(testDB)
 
 	savingInput := models.SavingInput{
 		Name:      		"testupdate",
-		Value: 			1,
-		Goal: 			25000,
+		Value: 			money.New(100, "IDR"),
+		Goal: 			money.New(2500000, "IDR"),
 		UserID:  		user.ID,
 	}
 
@@ -296,8 +300,8 @@ func TestUpdateSavingByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(savingID)
 
-	if assert.NoError(t, savingController.Update(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	if assert.NoError(t, handle(ctx, savingController.Update)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
 		body := rec.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -308,18 +312,18 @@ func TestUpdateSavingByID_TokenFailed(t *testing.T) {
 	testcase := testCaseSaving{
 		name:                   "failed",
 		path:                   "/api/v1/savings",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
 	e := InitSavingEcho()
 
-	user, _ := config.SeedUser()
-	saving, _ := config.SeedSaving()
+	user, _ := testutil.SeedUser(testDB)
+	saving, _ := testutil.SeedSaving(testDB)
 
 	savingInput := models.SavingUpdate{
 		Name:      		"testupdate",
-		Goal: 			1,
+		Goal: 			money.New(100, "IDR"),
 		UserID:  		user.ID,
 	}
 
@@ -340,8 +344,8 @@ func TestUpdateSavingByID_TokenFailed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(savingID)
 
-	if assert.NoError(t, savingController.Update(ctx)) {
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	if assert.NoError(t, handle(ctx, savingController.Update)) {
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 		body := rec.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
@@ -358,14 +362,14 @@ func TestDeleteSavingByID_Success(t *testing.T) {
 
 	e := InitSavingEcho()
 
-	saving, err := config.SeedSaving()
+	saving, err := testutil.SeedSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
 	}
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
 	savingID := strconv.Itoa(int(saving.ID))
@@ -381,7 +385,7 @@ func TestDeleteSavingByID_Success(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(savingID)
 
-	if assert.NoError(t, savingController.Delete(ctx)) {
+	if assert.NoError(t, handle(ctx, savingController.Delete)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -400,7 +404,7 @@ func TestDeleteSavingByID_Failed(t *testing.T) {
 
 	e := InitSavingEcho()
 
-	saving, err := config.SeedSaving()
+	saving, err := testutil.SeedSaving(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -419,7 +423,7 @@ func TestDeleteSavingByID_Failed(t *testing.T) {
 	ctx.SetParamNames("id")
 	ctx.SetParamValues(savingID)
 
-	if assert.NoError(t, savingController.Delete(ctx)) {
+	if assert.NoError(t, handle(ctx, savingController.Delete)) {
 		assert.Equal(t, http.StatusBadRequest, testcase.expectedStatus)
 
 		body := recorder.Body.String()
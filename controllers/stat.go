@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StatController struct {
+	service services.StatService
+}
+
+func InitStatController(service services.StatService) StatController {
+	return StatController{
+		service: service,
+	}
+}
+
+func (sc *StatController) GetSummary(c echo.Context) error {
+	stat, err := sc.service.GetSummary(c.Request().Context())
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Stat]{
+		Status:  "success",
+		Message: "stats summary",
+		Data:    stat,
+	})
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCaseStat struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var statController StatController
+
+func InitStatEcho() *echo.Echo {
+	db := initTestDB()
+	statController = InitStatController(services.InitStatService(repositories.InitStatRepository(db)))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetStatSummary_Success(t *testing.T) {
+	testcase := testCaseStat{
+		name:                   "success",
+		path:                   "/api/v1/admin/stats",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitStatEcho()
+
+	_, _ = testutil.SeedFinance(testDB)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, statController.GetSummary)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout_Success(t *testing.T) {
+	e := InitFinanceEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/finances", nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	handler := middleware.RequestTimeout(30 * time.Second)(func(c echo.Context) error {
+		return handle(c, financeController.GetAll)
+	})
+
+	if assert.NoError(t, handler(ctx)) {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+}
+
+func TestRequestTimeout_Failed(t *testing.T) {
+	e := InitFinanceEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/finances", nil)
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	// the deadline has passed before the first query runs
+	handler := middleware.RequestTimeout(-time.Second)(func(c echo.Context) error {
+		return handle(c, financeController.GetAll)
+	})
+
+	if assert.NoError(t, handler(ctx)) {
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.Contains(body, "\"code\":\"timeout\""))
+	}
+
+}
@@ -1,41 +1,64 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"keuangan-pribadi/middleware"
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/services"
 	"net/http"
-	"strings"
+	"strconv"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
 type UserController struct {
 	service services.UserService
+	throttle *middleware.LoginThrottle
 }
 
-func InitUserController() UserController {
+// InitUserController creates the controller of the users. Failed logins are
+// locked out by throttle, which shares its store with the rate limits.
+func InitUserController(service services.UserService, throttle *middleware.LoginThrottle) UserController {
 	return UserController{
-		service: services.InitUserService(),
+		service: service,
+		throttle: throttle,
 	}
 }
 
+func (uc *UserController) GetAll(c echo.Context) error {
+	users, err := uc.service.GetAll(c.Request().Context())
+
+	if err != nil {
+		return err
+	}
+
+	userResponses := make([]models.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, models.NewUserResponse(user))
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.UserResponse]{
+		Status:  "success",
+		Message: "all users",
+		Data:    userResponses,
+	})
+}
+
 func (uc *UserController) GetByEmail(c echo.Context) error {
 	var userEmail string = c.Param("email")
 
-	user, err := uc.service.GetByEmail(userEmail)
+	user, err := uc.service.GetByEmail(c.Request().Context(), userEmail)
 
 	if err != nil {
-		return c.JSON(http.StatusNotFound, models.Response[string]{
-			Status:  "failed",
-			Message: "user not found",
-		})
+		return err
 	}
 
-	return c.JSON(http.StatusOK, models.Response[models.User]{
+	return c.JSON(http.StatusOK, models.Response[models.UserResponse]{
 		Status:  "success",
 		Message: "user found",
-		Data:    user,
+		Data:    models.NewUserResponse(user),
 	})
 }
 
@@ -43,27 +66,18 @@ func (uc *UserController) Register(c echo.Context) error {
 	var userInput models.UserInput
 
 	if err := c.Bind(&userInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(userInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	user, err := uc.service.Register(userInput)
+	user, err := uc.service.Register(c.Request().Context(), userInput, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, models.Response[models.User]{
@@ -77,28 +91,35 @@ func (uc *UserController) Login(c echo.Context) error {
 	var userInput models.UserAuth
 
 	if err := c.Bind(&userInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
+	validate := newValidator()
     if err := validate.Struct(userInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return validationError(err)
     }
 
-	userResponse, err := uc.service.Login(userInput)
-	if err != nil {
-		return c.JSON(http.StatusUnauthorized, models.Response[string]{
+	if wait := uc.throttle.Check(userInput.Email, c.RealIP()); wait > 0 {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+
+		return c.JSON(http.StatusTooManyRequests, models.Response[string]{
 			Status:  "failed",
-			Message: "authentication failed, invalid Email or Password",
+			Message: "too many failed login attempts, try again later",
+			Code:    "too_many_requests",
 		})
 	}
 
+	userResponse, err := uc.service.Login(c.Request().Context(), userInput)
+	if err != nil {
+		if errors.Is(err, models.ErrUnauthorized) {
+			uc.throttle.Fail(userInput.Email, c.RealIP())
+		}
+
+		return err
+	}
+
+	uc.throttle.Succeed(userInput.Email)
+
 	return c.JSON(http.StatusOK, models.Response[models.UserResponse]{
 		Status:  "success",
 		Message: "authenticated",
@@ -107,39 +128,26 @@ func (uc *UserController) Login(c echo.Context) error {
 }
 
 func (uc *UserController) Update(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-    if token == "" {
-        return c.JSON(http.StatusBadRequest, models.Response[string]{
-            Status:  "failed",
-            Message: "Missing token in request header",
-        })
-    }
-	token = strings.ReplaceAll(token, "Bearer ", "")
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
 
-	var userInput models.UserInput
+	var userUpdate models.UserUpdate
 
-	if err := c.Bind(&userInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: "invalid request",
-		})
+	if err := c.Bind(&userUpdate); err != nil {
+		return errInvalidRequest
 	}
 
-	validate := validator.New()
-    if err := validate.Struct(userInput); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+	validate := newValidator()
+    if err := validate.Struct(userUpdate); err != nil {
+		return validationError(err)
     }
 
-	user, err := uc.service.Update(userInput, token)
+	user, err := uc.service.Update(c.Request().Context(), userUpdate, userID, auditMeta(c))
 
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response[string]{
-			Status:  "failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, models.Response[models.User]{
@@ -147,4 +155,178 @@ func (uc *UserController) Update(c echo.Context) error {
 		Message: "user updated",
 		Data:    user,
 	})
-}
\ No newline at end of file
+}
+
+func (uc *UserController) ChangePassword(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var passwordInput models.UserChangePassword
+
+	if err := c.Bind(&passwordInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+    if err := validate.Struct(passwordInput); err != nil {
+		return validationError(err)
+    }
+
+	userResponse, err := uc.service.ChangePassword(c.Request().Context(), passwordInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.UserResponse]{
+		Status:  "success",
+		Message: "password changed, other sessions have been signed out",
+		Data:    userResponse,
+	})
+}
+
+func (uc *UserController) ChangeEmail(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var emailInput models.UserChangeEmail
+
+	if err := c.Bind(&emailInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+    if err := validate.Struct(emailInput); err != nil {
+		return validationError(err)
+    }
+
+	if err := uc.service.ChangeEmail(c.Request().Context(), emailInput, userID); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "verification token sent to the new email address",
+	})
+}
+
+func (uc *UserController) VerifyEmail(c echo.Context) error {
+	var verifyInput models.UserVerifyEmail
+
+	if err := c.Bind(&verifyInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+    if err := validate.Struct(verifyInput); err != nil {
+		return validationError(err)
+    }
+
+	user, err := uc.service.VerifyEmail(c.Request().Context(), verifyInput, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.User]{
+		Status:  "success",
+		Message: "email changed",
+		Data:    user,
+	})
+}
+
+func (uc *UserController) UpdateRole(c echo.Context) error {
+	var userID string = c.Param("id")
+
+	var roleInput models.UserRole
+
+	if err := c.Bind(&roleInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+    if err := validate.Struct(roleInput); err != nil {
+		return validationError(err)
+    }
+
+	user, err := uc.service.UpdateRole(c.Request().Context(), roleInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.UserResponse]{
+		Status:  "success",
+		Message: "user role updated",
+		Data:    models.NewUserResponse(user),
+	})
+}
+
+func (uc *UserController) Delete(c echo.Context) error {
+	var userID string = c.Param("id")
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	err = uc.service.Delete(c.Request().Context(), userID, adminID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "user deleted",
+	})
+}
+
+func (uc *UserController) Export(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	archive, err := uc.service.Export(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("keuangan-pribadi-export-%s.zip", time.Now().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
+
+func (uc *UserController) DeleteAccount(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var deleteInput models.UserDeleteAccount
+
+	if err := c.Bind(&deleteInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+    if err := validate.Struct(deleteInput); err != nil {
+		return validationError(err)
+    }
+
+	if err := uc.service.DeleteAccount(c.Request().Context(), deleteInput, userID); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "account deleted",
+	})
+}
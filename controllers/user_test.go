@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"keuangan-pribadi/config"
 	"keuangan-pribadi/middleware"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
@@ -24,10 +29,11 @@ type testCaseUser struct {
 	expectedBodyStartsWith string
 }
 
-var controller UserController = InitUserController()
+var controller UserController
 
 func InitEcho() *echo.Echo {
-	config.InitDB()
+	db := initTestDB()
+	controller = InitUserController(services.InitUserService(repositories.InitUserRepository(db, testConfig.Mail.Mailer(), testConfig.Auth)), testConfig.Login.Throttle(middleware.NewMemoryStore()))
 
 	e := echo.New()
 
@@ -48,7 +54,7 @@ func TestRegisterUser_Success(t *testing.T) {
 
 	var userInput models.UserInput = models.UserInput{
 		Name:       "test",
-		Email: 		"test@gmail.com",
+		Email: 		fmt.Sprintf("registered%d@gmail.com", time.Now().UnixNano()),
 		Password:  	string(password),
 	}
 
@@ -70,15 +76,42 @@ func TestRegisterUser_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, controller.Register(ctx)) {
-		assert.Equal(t, http.StatusCreated, testcase.expectedStatus)
+	if assert.NoError(t, handle(ctx, controller.Register)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
 
 		body := recorder.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+		assert.Contains(t, body, userInput.Email)
 	}
 }
 
+func TestRegisterUser_Conflict(t *testing.T) {
+	InitEcho()
+
+	email := fmt.Sprintf("twice%d@gmail.com", time.Now().UnixNano())
+	body := fmt.Sprintf(`{"name":"test","email":%q,"password":"inisecret"}`, email)
+
+	recorder := request(t, controller.Register, http.MethodPost, "/api/v1/users/register", body, "")
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	// the second account is refused by the unique index, not by a lookup
+	recorder = request(t, controller.Register, http.MethodPost, "/api/v1/users/register", body, "")
+
+	if assert.Equal(t, http.StatusConflict, recorder.Code) {
+		var response models.Response[any]
+
+		if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+			assert.Equal(t, "email_in_use", response.Code)
+		}
+	}
+
+	var count int64
+	testDB.Model(&models.User{}).Where("email = ?", email).Count(&count)
+
+	assert.Equal(t, int64(1), count)
+}
+
 func TestRegisterUser_Failed(t *testing.T) {
 	testcase := testCaseUser {
 		name:                   "failed",
@@ -103,7 +136,7 @@ func TestRegisterUser_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, controller.Register(ctx)) {
+	if assert.NoError(t, handle(ctx, controller.Register)) {
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 		body := recorder.Body.String()
 
@@ -121,7 +154,7 @@ func TestGetUserByEmail_Success(t *testing.T) {
 
 	e := InitEcho()
 
-	user, err := config.SeedUser()
+	user, err := testutil.SeedUser(testDB)
 
 	if err != nil {
 		t.Errorf("error: %v\n", err)
@@ -140,12 +173,18 @@ func TestGetUserByEmail_Success(t *testing.T) {
 	ctx.SetParamNames("email")
 	ctx.SetParamValues(userEmail)
 
-	if assert.NoError(t, controller.GetByEmail(ctx)) {
-		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
+	if assert.NoError(t, handle(ctx, controller.GetByEmail)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
 
 		body := recorder.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+		assert.False(t, strings.Contains(body, "password"))
+
+		var response models.Response[models.UserResponse]
+		if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+			assert.Equal(t, userEmail, response.Data.Email)
+		}
 	}
 }
 
@@ -167,7 +206,7 @@ func TestGetUserByEmail_Failed(t *testing.T) {
 	ctx.SetParamNames("email")
 	ctx.SetParamValues("false@gmail.com")
 
-	if assert.NoError(t, controller.GetByEmail(ctx)) {
+	if assert.NoError(t, handle(ctx, controller.GetByEmail)) {
 		assert.Equal(t, http.StatusNotFound, rec.Code)
 		body := rec.Body.String()
 
@@ -210,7 +249,7 @@ func TestLoginUser_Success(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, controller.Login(ctx)) {
+	if assert.NoError(t, handle(ctx, controller.Login)) {
 		assert.Equal(t, http.StatusOK, testcase.expectedStatus)
 
 		body := recorder.Body.String()
@@ -243,7 +282,7 @@ func TestLoginUser_Failed(t *testing.T) {
 
 	ctx.SetPath(testcase.path)
 
-	if assert.NoError(t, controller.Login(ctx)) {
+	if assert.NoError(t, handle(ctx, controller.Login)) {
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 		body := recorder.Body.String()
 
@@ -251,6 +290,72 @@ func TestLoginUser_Failed(t *testing.T) {
 	}
 }
 
+func TestLoginUser_Locked(t *testing.T) {
+	testcase := testCaseUser {
+		name:                   "locked",
+		path:                   "/api/v1/users/login",
+		expectedStatus:         http.StatusTooManyRequests,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	userAuth := models.UserAuth{
+		Email:    fmt.Sprintf("locked%d@gmail.com", time.Now().UnixNano()),
+		Password: "wrongsecret",
+	}
+
+	jsonBody, _ := json.Marshal(&userAuth)
+
+	var recorder *httptest.ResponseRecorder
+
+	for i := 0; i <= testConfig.Login.MaxAccountAttempts; i++ {
+		request := httptest.NewRequest(http.MethodPost, testcase.path, bytes.NewReader(jsonBody))
+		recorder = httptest.NewRecorder()
+
+		request.Header.Add("Content-Type", "application/json")
+
+		ctx := e.NewContext(request, recorder)
+
+		ctx.SetPath(testcase.path)
+
+		assert.NoError(t, handle(ctx, controller.Login))
+	}
+
+	assert.Equal(t, testcase.expectedStatus, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+
+	body := recorder.Body.String()
+
+	assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+}
+
+func TestLoginUser_ThrottleConfig(t *testing.T) {
+	InitEcho()
+
+	// the throttle is configured and counts in the store it is given
+	store := middleware.NewMemoryStore()
+	loginConfig := testConfig.Login
+	loginConfig.MaxAccountAttempts = 1
+
+	throttled := InitUserController(controller.service, loginConfig.Throttle(store))
+
+	email := fmt.Sprintf("throttled%d@gmail.com", time.Now().UnixNano())
+	body := fmt.Sprintf(`{"email":%q,"password":"wrongsecret"}`, email)
+
+	recorder := request(t, throttled.Login, http.MethodPost, "/api/v1/users/login", body, "")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, 1, store.Get("login:account:"+email).Count)
+
+	recorder = request(t, throttled.Login, http.MethodPost, "/api/v1/users/login", body, "")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+
+	// the other controller keeps its own count
+	recorder = request(t, controller.Login, http.MethodPost, "/api/v1/users/login", body, "")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
 func TestUpdateUser_Success(t *testing.T) {
 	testcase := testCaseUser{
 		name:                   "success",
@@ -261,19 +366,15 @@ func TestUpdateUser_Success(t *testing.T) {
 
 	e := InitEcho()
 
-	user, _ := config.SeedUser()
-	token, _ := middleware.CreateToken(user.ID, user.Name)
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
 	tokenString := fmt.Sprintf("Bearer %s", token)
 
-	password, _ := bcrypt.GenerateFromPassword([]byte("passupdate"), bcrypt.DefaultCost)
-
-	userInput := models.UserInput{
+	userUpdate := models.UserUpdate{
 		Name:      "updated",
-		Email:     "updated@gmail.com",
-		Password:  string(password),
 	}
 
-	jsonBody, _ := json.Marshal(&userInput)
+	jsonBody, _ := json.Marshal(&userUpdate)
 	bodyReader := bytes.NewReader(jsonBody)
 
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/users", bodyReader)
@@ -286,7 +387,7 @@ func TestUpdateUser_Success(t *testing.T) {
 
 	c.SetPath(testcase.path)
 
-	if assert.NoError(t, controller.Update(c)) {
+	if assert.NoError(t, handle(c, controller.Update)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 		body := rec.Body.String()
 
@@ -298,7 +399,7 @@ func TestUpdateUser_Failed(t *testing.T) {
 	testcase := testCaseUser{
 		name:                   "failed",
 		path:                   "/api/v1/users",
-		expectedStatus:         http.StatusBadRequest,
+		expectedStatus:         http.StatusUnauthorized,
 		expectedBodyStartsWith: "{\"status\":",
 	}
 
@@ -325,10 +426,681 @@ func TestUpdateUser_Failed(t *testing.T) {
 
 	c.SetPath(testcase.path)
 
-	if assert.NoError(t, controller.Update(c)) {
-		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	if assert.NoError(t, handle(c, controller.Update)) {
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestChangePasswordUser_Success(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "success",
+		path:                   "/api/v1/users/password",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	passwordInput := models.UserChangePassword{
+		CurrentPassword: "testsecret",
+		NewPassword:     "newsecret",
+	}
+
+	jsonBody, _ := json.Marshal(&passwordInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.ChangePassword)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestChangePasswordUser_Failed(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "failed",
+		path:                   "/api/v1/users/password",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	passwordInput := models.UserChangePassword{
+		CurrentPassword: "wrongsecret",
+		NewPassword:     "newsecret",
+	}
+
+	jsonBody, _ := json.Marshal(&passwordInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.ChangePassword)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestChangePasswordUser_RevokesTokens(t *testing.T) {
+	InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	oldToken := fmt.Sprintf("Bearer %s", token)
+
+	recorder := request(t, controller.ChangePassword, http.MethodPut, "/api/v1/users/password", `{"current_password":"testsecret","new_password":"newsecret"}`, oldToken)
+	if !assert.Equal(t, http.StatusOK, recorder.Code) {
+		return
+	}
+
+	var response models.Response[models.UserResponse]
+	if !assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		return
+	}
+
+	// the old token was issued in the same second as the change
+	recorder = request(t, controller.Export, http.MethodGet, "/api/v1/users/me/export", "", oldToken)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = request(t, controller.Export, http.MethodGet, "/api/v1/users/me/export", "", fmt.Sprintf("Bearer %s", response.Data.Token))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestChangeEmailUser_Success(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "success",
+		path:                   "/api/v1/users/email",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	emailInput := models.UserChangeEmail{
+		Email:    fmt.Sprintf("changed%d@gmail.com", time.Now().UnixNano()),
+		Password: "testsecret",
+	}
+
+	jsonBody, _ := json.Marshal(&emailInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.ChangeEmail)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestChangeEmailUser_Failed(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "failed",
+		path:                   "/api/v1/users/email",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	emailInput := models.UserChangeEmail{
+		Email:    "changed@gmail.com",
+		Password: "testsecret",
+	}
+
+	jsonBody, _ := json.Marshal(&emailInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "")
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.ChangeEmail)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestVerifyEmailUser_Failed(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "failed",
+		path:                   "/api/v1/users/email/verify",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	verifyInput := models.UserVerifyEmail{
+		Token: "invalidtoken",
+	}
+
+	jsonBody, _ := json.Marshal(&verifyInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.VerifyEmail)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
 		body := rec.Body.String()
 
 		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
 	}
-}
\ No newline at end of file
+}
+
+func TestGetAllUsers_Success(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "success",
+		path:                   "/api/v1/admin/users",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+		assert.False(t, strings.Contains(body, "password"))
+		assert.False(t, strings.Contains(body, "$2a$"))
+
+		var response models.Response[[]models.UserResponse]
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) && assert.NotEmpty(t, response.Data) {
+			assert.NotEmpty(t, response.Data[0].Email)
+			assert.Empty(t, response.Data[0].Token)
+		}
+	}
+}
+
+func TestUpdateUserRole_Success(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "success",
+		path:                   "/api/v1/admin/users",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+
+	roleInput := models.UserRole{
+		Role: models.RoleAdmin,
+	}
+
+	jsonBody, _ := json.Marshal(&roleInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(user.ID)))
+
+	if assert.NoError(t, handle(c, controller.UpdateRole)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+		assert.False(t, strings.Contains(body, "password"))
+
+		var response models.Response[models.UserResponse]
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, user.ID, response.Data.ID)
+			assert.Equal(t, models.RoleAdmin, response.Data.Role)
+		}
+
+		var stored models.User
+		testDB.First(&stored, user.ID)
+
+		assert.Equal(t, models.RoleAdmin, stored.Role)
+	}
+}
+
+func TestUpdateUserRole_Failed(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "failed",
+		path:                   "/api/v1/admin/users",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+
+	roleInput := models.UserRole{
+		Role: "superuser",
+	}
+
+	jsonBody, _ := json.Marshal(&roleInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(user.ID)))
+
+	if assert.NoError(t, handle(c, controller.UpdateRole)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteUser_Success(t *testing.T) {
+	InitEcho()
+
+	admin, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	household, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	owner := household.Members[0].UserID
+	member := addLedgerMember(t, household.ID, models.LedgerRoleEditor)
+
+	personal, err := repositories.PersonalLedger(testDB, member.ID)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	seedLedgerFinance(t, member.ID, personal.ID, "salary")
+	seedLedgerFinance(t, member.ID, household.ID, "groceries")
+
+	recorder := request(t, controller.Delete, http.MethodDelete, "/api/v1/admin/users/:id", "", bearer(admin.ID), "id", strconv.Itoa(int(member.ID)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// the account is deleted like by its user, the household keeps its entries
+	assert.Empty(t, ledgerFinances(t, personal.ID))
+	assert.Equal(t, []string{"groceries"}, ledgerFinances(t, household.ID))
+	assert.Equal(t, map[uint]string{owner: models.LedgerRoleOwner}, ledgerRoles(t, household.ID))
+
+	var users int64
+	testDB.Unscoped().Model(&models.User{}).Where("id = ?", member.ID).Count(&users)
+	assert.Zero(t, users)
+
+	// the deletion is audited as the admin's, without the account
+	var auditLogs []models.AuditLog
+	testDB.Where("entity_type = ? AND entity_id = ? AND action = ?", "user", member.ID, models.AuditActionDelete).Find(&auditLogs)
+
+	if assert.Len(t, auditLogs, 1) {
+		assert.Equal(t, admin.ID, auditLogs[0].ActorID)
+		assert.Zero(t, auditLogs[0].OwnerID)
+		assert.Empty(t, auditLogs[0].Before)
+	}
+}
+
+func TestDeleteUser_Failed(t *testing.T) {
+	InitEcho()
+
+	admin, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	recorder := request(t, controller.Delete, http.MethodDelete, "/api/v1/admin/users/:id", "", bearer(admin.ID), "id", "-1")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Body.String(), "{\"status\":"))
+}
+
+func TestDeleteUser_Self(t *testing.T) {
+	InitEcho()
+
+	admin, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	recorder := request(t, controller.Delete, http.MethodDelete, "/api/v1/admin/users/:id", "", bearer(admin.ID), "id", strconv.Itoa(int(admin.ID)))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\"cannot_delete_self\""))
+
+	var users int64
+	testDB.Model(&models.User{}).Where("id = ?", admin.ID).Count(&users)
+	assert.Equal(t, int64(1), users)
+}
+
+func TestExportUser_Success(t *testing.T) {
+	testcase := testCaseUser{
+		name:           "success",
+		path:           "/api/v1/users/me/export",
+		expectedStatus: http.StatusOK,
+	}
+
+	e := InitEcho()
+
+	finance, _ := testutil.SeedFinance(testDB)
+	token, _ := middleware.CreateToken(finance.User.ID, finance.User.Name, finance.User.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Authorization", tokenString)
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.Export)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestExportUser_Failed(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "failed",
+		path:                   "/api/v1/users/me/export",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Authorization", "")
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.Export)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestExportUser_OtherAlgorithm(t *testing.T) {
+	InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+
+	claims := jwt.MapClaims{"user_id": user.ID, "iat": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix()}
+
+	// signed with the right secret, but not with the algorithm tokens are issued with
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString([]byte(testConfig.Auth.JWTSecret))
+	recorder := request(t, controller.Export, http.MethodGet, "/api/v1/users/me/export", "", fmt.Sprintf("Bearer %s", token))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	token, _ = jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	recorder = request(t, controller.Export, http.MethodGet, "/api/v1/users/me/export", "", fmt.Sprintf("Bearer %s", token))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestDeleteAccountUser_Success(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "success",
+		path:                   "/api/v1/users/me",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	saving, _ := testutil.SeedSaving(testDB)
+	token, _ := middleware.CreateToken(saving.User.ID, saving.User.Name, saving.User.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	deleteInput := models.UserDeleteAccount{
+		Password: "testsecret",
+	}
+
+	jsonBody, _ := json.Marshal(&deleteInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.DeleteAccount)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteAccountUser_Failed(t *testing.T) {
+	testcase := testCaseUser{
+		name:                   "failed",
+		path:                   "/api/v1/users/me",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	deleteInput := models.UserDeleteAccount{
+		Password: "wrongsecret",
+	}
+
+	jsonBody, _ := json.Marshal(&deleteInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, bodyReader)
+	rec := httptest.NewRecorder()
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+
+	c := e.NewContext(req, rec)
+
+	c.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(c, controller.DeleteAccount)) {
+		assert.Equal(t, testcase.expectedStatus, rec.Code)
+		body := rec.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteAccountUser_SharedLedger(t *testing.T) {
+	InitEcho()
+
+	household, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	owner := household.Members[0].UserID
+	member := addLedgerMember(t, household.ID, models.LedgerRoleEditor)
+
+	personal, err := repositories.PersonalLedger(testDB, member.ID)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	seedLedgerFinance(t, member.ID, personal.ID, "salary")
+	seedLedgerFinance(t, member.ID, household.ID, "groceries")
+
+	// entries stay in a household the user was removed from earlier
+	former, err := testutil.SeedLedger(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	seedLedgerFinance(t, member.ID, former.ID, "rent")
+
+	recorder := request(t, controller.DeleteAccount, http.MethodDelete, "/api/v1/users/me", `{"password":"testsecret"}`, bearer(member.ID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	assert.Empty(t, ledgerFinances(t, personal.ID))
+	assert.Equal(t, []string{"groceries"}, ledgerFinances(t, household.ID))
+	assert.Equal(t, []string{"rent"}, ledgerFinances(t, former.ID))
+
+	var users int64
+	testDB.Model(&models.User{}).Where("id = ?", member.ID).Count(&users)
+	assert.Zero(t, users)
+
+	// the household entries now belong to the owners
+	var finances []models.Finance
+	testDB.Where("ledger_id IN ?", []uint{household.ID, former.ID}).Find(&finances)
+	for _, finance := range finances {
+		assert.NotEqual(t, member.ID, finance.UserID)
+	}
+	assert.Equal(t, map[uint]string{owner: models.LedgerRoleOwner}, ledgerRoles(t, household.ID))
+}
+
+func TestDeleteAccountUser_AuditLogs(t *testing.T) {
+	InitEcho()
+
+	user, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	recorder := request(t, controller.Update, http.MethodPut, "/api/v1/users/me", `{"name":"renamed"}`, bearer(user.ID))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = request(t, controller.DeleteAccount, http.MethodDelete, "/api/v1/users/me", `{"password":"testsecret"}`, bearer(user.ID))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// the change is still in the audit log, without anything about who made it
+	var auditLogs []models.AuditLog
+	testDB.Where("entity_type = ? AND entity_id = ?", "user", user.ID).Find(&auditLogs)
+
+	if assert.Len(t, auditLogs, 1) {
+		assert.Equal(t, models.AuditActionUpdate, auditLogs[0].Action)
+		assert.Zero(t, auditLogs[0].OwnerID)
+		assert.Zero(t, auditLogs[0].ActorID)
+		assert.Empty(t, auditLogs[0].IP)
+		assert.Empty(t, auditLogs[0].UserAgent)
+		assert.Empty(t, auditLogs[0].Before)
+		assert.Empty(t, auditLogs[0].After)
+		assert.Empty(t, auditLogs[0].Changes)
+	}
+
+	var owned int64
+	testDB.Model(&models.AuditLog{}).Where("owner_id = ? OR actor_id = ?", user.ID, user.ID).Count(&owned)
+	assert.Zero(t, owned)
+}
+
+func TestUpdateUser_AuditFailed(t *testing.T) {
+	InitEcho()
+
+	user, err := testutil.SeedUser(testDB)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	// without the audit log nothing can be written to it
+	if err := testDB.Exec("ALTER TABLE audit_logs RENAME TO audit_logs_unavailable").Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { testDB.Exec("ALTER TABLE audit_logs_unavailable RENAME TO audit_logs") })
+
+	recorder := request(t, controller.Update, http.MethodPut, "/api/v1/users/me", `{"name":"renamed"}`, bearer(user.ID))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code, recorder.Body.String())
+
+	// the change is rolled back along with its audit entry
+	var stored models.User
+	testDB.First(&stored, user.ID)
+	assert.Equal(t, user.Name, stored.Name)
+}
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"keuangan-pribadi/money"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// newValidator validates the request inputs. Amounts of money are checked
+// by their minor units, so min=1 on an amount means more than zero.
+func newValidator() *validator.Validate {
+	validate := validator.New()
+
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if amount, ok := field.Interface().(money.Money); ok {
+			return amount.Minor
+		}
+
+		return nil
+	}, money.Money{})
+
+	return validate
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"github.com/labstack/echo/v4"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/services"
+	"net/http"
+)
+
+type WebhookController struct {
+	service services.WebhookService
+}
+
+func InitWebhookController(service services.WebhookService) WebhookController {
+	return WebhookController{
+		service: service,
+	}
+}
+
+func (wc *WebhookController) GetAll(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := wc.service.GetAll(c.Request().Context(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.Webhook]{
+		Status:  "success",
+		Message: "all webhooks",
+		Data:    webhooks,
+	})
+}
+
+func (wc *WebhookController) Create(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var webhookInput models.WebhookInput
+
+	if err := c.Bind(&webhookInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(webhookInput); err != nil {
+		return validationError(err)
+	}
+
+	webhook, err := wc.service.Create(c.Request().Context(), webhookInput, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, models.Response[models.WebhookResponse]{
+		Status:  "success",
+		Message: "webhook created, copy the secret now as it will not be shown again",
+		Data:    webhook,
+	})
+}
+
+func (wc *WebhookController) Update(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var webhookInput models.WebhookInput
+
+	if err := c.Bind(&webhookInput); err != nil {
+		return errInvalidRequest
+	}
+
+	validate := newValidator()
+	if err := validate.Struct(webhookInput); err != nil {
+		return validationError(err)
+	}
+
+	var webhookID string = c.Param("id")
+
+	webhook, err := wc.service.Update(c.Request().Context(), webhookInput, webhookID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.Webhook]{
+		Status:  "success",
+		Message: "webhook updated",
+		Data:    webhook,
+	})
+}
+
+func (wc *WebhookController) Delete(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var webhookID string = c.Param("id")
+
+	err = wc.service.Delete(c.Request().Context(), webhookID, userID, auditMeta(c))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[string]{
+		Status:  "success",
+		Message: "webhook deleted",
+	})
+}
+
+func (wc *WebhookController) GetDeliveries(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var webhookID string = c.Param("id")
+
+	deliveries, err := wc.service.GetDeliveries(c.Request().Context(), webhookID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[[]models.WebhookDelivery]{
+		Status:  "success",
+		Message: "webhook deliveries",
+		Data:    deliveries,
+	})
+}
+
+func (wc *WebhookController) Test(c echo.Context) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var webhookID string = c.Param("id")
+
+	delivery, err := wc.service.Test(c.Request().Context(), webhookID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, models.Response[models.WebhookDelivery]{
+		Status:  "success",
+		Message: "test event sent",
+		Data:    delivery,
+	})
+}
@@ -0,0 +1,627 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"keuangan-pribadi/middleware"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"keuangan-pribadi/testutil"
+	"keuangan-pribadi/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCaseWebhook struct {
+	name                   string
+	path                   string
+	expectedStatus         int
+	expectedBodyStartsWith string
+}
+
+var webhookController WebhookController
+
+func InitWebhookEcho() *echo.Echo {
+	db := initTestDB()
+	// the test receivers listen on the loopback address
+	webhookController = InitWebhookController(services.InitWebhookService(repositories.InitWebhookRepository(db), true))
+
+	e := echo.New()
+
+	return e
+}
+
+func TestGetAllWebhooks_Success(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "success",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, webhookController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetAllWebhooks_Failed(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "failed",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusUnauthorized,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	tokenString := ""
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, webhookController.GetAll)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateWebhook_Success(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "success",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusCreated,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var webhookInput models.WebhookInput = models.WebhookInput{
+		URL:    "https://example.com/hook",
+		Events: []string{models.WebhookEventFinanceCreated, models.WebhookEventSavingGoalReached},
+	}
+
+	jsonBody, _ := json.Marshal(&webhookInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, webhookController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateWebhook_Failed(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "failed",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var webhookInput models.WebhookInput = models.WebhookInput{
+		URL:    "https://example.com/hook",
+		Events: []string{"finance.exploded"},
+	}
+
+	jsonBody, _ := json.Marshal(&webhookInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+
+	if assert.NoError(t, handle(ctx, webhookController.Create)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateWebhook_Success(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "success",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	webhook, err := testutil.SeedWebhook(testDB, "http://127.0.0.1:9/hook")
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(webhook.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var webhookInput models.WebhookInput = models.WebhookInput{
+		URL:    "https://example.com/hook",
+		Events: []string{models.WebhookEventFinanceCreated, models.WebhookEventSavingGoalReached},
+	}
+
+	jsonBody, _ := json.Marshal(&webhookInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(webhook.ID)))
+
+	if assert.NoError(t, handle(ctx, webhookController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestUpdateWebhook_Failed(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "failed",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusBadRequest,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	webhook, err := testutil.SeedWebhook(testDB, "http://127.0.0.1:9/hook")
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(webhook.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	var webhookInput models.WebhookInput = models.WebhookInput{
+		URL:    "https://example.com/hook",
+		Events: []string{"finance.exploded"},
+	}
+
+	jsonBody, _ := json.Marshal(&webhookInput)
+	bodyReader := bytes.NewReader(jsonBody)
+
+	req := httptest.NewRequest(http.MethodPut, testcase.path, bodyReader)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(webhook.ID)))
+
+	if assert.NoError(t, handle(ctx, webhookController.Update)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteWebhook_Success(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "success",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	webhook, err := testutil.SeedWebhook(testDB, "http://127.0.0.1:9/hook")
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(webhook.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(webhook.ID)))
+
+	if assert.NoError(t, handle(ctx, webhookController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestDeleteWebhook_Failed(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "failed",
+		path:                   "/api/v1/webhooks",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodDelete, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, webhookController.Delete)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetWebhookDeliveries_Success(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "success",
+		path:                   "/api/v1/webhooks/deliveries",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	webhook, err := testutil.SeedWebhook(testDB, "http://127.0.0.1:9/hook")
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(webhook.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(webhook.ID)))
+
+	if assert.NoError(t, handle(ctx, webhookController.GetDeliveries)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestGetWebhookDeliveries_Failed(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "failed",
+		path:                   "/api/v1/webhooks/deliveries",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodGet, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, webhookController.GetDeliveries)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestTestWebhook_Success(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "success",
+		path:                   "/api/v1/webhooks/test",
+		expectedStatus:         http.StatusOK,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	// the receiver only accepts payloads signed with the webhook secret
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get("X-Webhook-Timestamp"), 10, 64)
+
+		if r.Header.Get("X-Webhook-Signature") != "sha256="+utils.SignPayload("testsecret", timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		received <- r.Header.Get("X-Webhook-Event")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhook, err := testutil.SeedWebhook(testDB, receiver.URL)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	token, _ := middleware.CreateToken(webhook.UserID, "test", models.RoleUser)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(int(webhook.ID)))
+
+	if assert.NoError(t, handle(ctx, webhookController.Test)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+		assert.Contains(t, body, "\"status\":\"succeeded\"")
+		assert.Equal(t, models.WebhookEventTest, <-received)
+	}
+}
+
+func TestTestWebhook_Failed(t *testing.T) {
+	testcase := testCaseWebhook{
+		name:                   "failed",
+		path:                   "/api/v1/webhooks/test",
+		expectedStatus:         http.StatusNotFound,
+		expectedBodyStartsWith: "{\"status\":",
+	}
+
+	e := InitWebhookEcho()
+
+	user, _ := testutil.SeedUser(testDB)
+	token, _ := middleware.CreateToken(user.ID, user.Name, user.Role)
+	tokenString := fmt.Sprintf("Bearer %s", token)
+
+	req := httptest.NewRequest(http.MethodPost, testcase.path, nil)
+	req.Header.Add("Authorization", tokenString)
+	recorder := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, recorder)
+
+	ctx.SetPath(testcase.path)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("-1")
+
+	if assert.NoError(t, handle(ctx, webhookController.Test)) {
+		assert.Equal(t, testcase.expectedStatus, recorder.Code)
+
+		body := recorder.Body.String()
+
+		assert.True(t, strings.HasPrefix(body, testcase.expectedBodyStartsWith))
+	}
+}
+
+func TestCreateWebhook_PrivateTarget(t *testing.T) {
+	InitWebhookEcho()
+
+	// the server is not allowed to call into its own network
+	publicOnly := InitWebhookController(services.InitWebhookService(repositories.InitWebhookRepository(testDB), false))
+
+	user, _ := testutil.SeedUser(testDB)
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+	} {
+		recorder := request(t, publicOnly.Create, http.MethodPost, "/api/v1/webhooks", fmt.Sprintf(`{"url":%q,"events":["finance.created"]}`, url), bearer(user.ID))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, url)
+		assert.True(t, strings.Contains(recorder.Body.String(), "\"code\":\"webhook_url_not_public\""), url)
+	}
+
+	var count int64
+	testDB.Model(&models.Webhook{}).Where("user_id = ?", user.ID).Count(&count)
+
+	assert.Equal(t, int64(0), count)
+
+	recorder := request(t, publicOnly.Create, http.MethodPost, "/api/v1/webhooks", `{"url":"http://93.184.215.14/hook","events":["finance.created"]}`, bearer(user.ID))
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+func TestTestWebhook_PrivateTarget(t *testing.T) {
+	InitWebhookEcho()
+
+	publicOnly := InitWebhookController(services.InitWebhookService(repositories.InitWebhookRepository(testDB), false))
+
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	// a webhook that points at the server's network, e.g. through a host
+	// name that resolved elsewhere when it was saved, is refused on connect
+	webhook, err := testutil.SeedWebhook(testDB, receiver.URL)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	recorder := request(t, publicOnly.Test, http.MethodPost, "/api/v1/webhooks/:id/test", "", bearer(webhook.UserID), "id", fmt.Sprint(webhook.ID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response models.Response[models.WebhookDelivery]
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) {
+		assert.Equal(t, models.WebhookDeliveryPending, response.Data.Status)
+		assert.Contains(t, response.Data.Error, "address is not public")
+	}
+
+	assert.False(t, called)
+}
+
+func TestTestWebhook_ResponseBody(t *testing.T) {
+	InitWebhookEcho()
+
+	// whatever the receiver answers stays with the receiver
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal details"))
+	}))
+	defer receiver.Close()
+
+	webhook, err := testutil.SeedWebhook(testDB, receiver.URL)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	recorder := request(t, webhookController.Test, http.MethodPost, "/api/v1/webhooks/:id/test", "", bearer(webhook.UserID), "id", fmt.Sprint(webhook.ID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "\"response_status\":500")
+	assert.NotContains(t, recorder.Body.String(), "internal details")
+
+	recorder = request(t, webhookController.GetDeliveries, http.MethodGet, "/api/v1/webhooks/:id/deliveries", "", bearer(webhook.UserID), "id", fmt.Sprint(webhook.ID))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "internal details")
+}
+
+func TestClaimDueDeliveries_Once(t *testing.T) {
+	InitWebhookEcho()
+
+	webhook, err := testutil.SeedWebhook(testDB, "http://127.0.0.1:9/hook")
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	now := time.Now().UTC()
+	delivery := models.WebhookDelivery{WebhookID: webhook.ID, Event: models.WebhookEventTest, Payload: []byte(`{}`), Status: models.WebhookDeliveryPending, NextAttemptAt: &now}
+	testDB.Create(&delivery)
+
+	claimed := func(at time.Time) bool {
+		deliveries, err := repositories.InitWebhookRepository(testDB).ClaimDueDeliveries(context.Background(), at, 1000, time.Minute)
+		assert.NoError(t, err)
+
+		for _, due := range deliveries {
+			if due.ID == delivery.ID {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// an instance polling at the same time skips the claimed delivery
+	assert.True(t, claimed(now))
+	assert.False(t, claimed(now))
+
+	// a delivery whose instance stopped is due again after the lease
+	assert.False(t, claimed(now.Add(30*time.Second)))
+	assert.True(t, claimed(now.Add(2*time.Minute)))
+}
@@ -0,0 +1,309 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/marketdata"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Document is the part of an OpenAPI 3 document the API needs.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem has the operations of a path by lower case method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	Responses       map[string]Response       `json:"responses"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// errorResponses are the failed responses every operation may answer with,
+// named after the status they are written with.
+var errorResponses = map[int]string{
+	http.StatusBadRequest:          "BadRequest",
+	http.StatusUnauthorized:        "Unauthorized",
+	http.StatusForbidden:           "Forbidden",
+	http.StatusNotFound:            "NotFound",
+	http.StatusConflict:            "Conflict",
+	http.StatusTooManyRequests:     "TooManyRequests",
+	http.StatusInternalServerError: "InternalError",
+	http.StatusServiceUnavailable:  "Timeout",
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// OpenAPIPath is the OpenAPI form of an echo path, /finances/:id becomes
+// /finances/{id}.
+func OpenAPIPath(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	specErr  error
+)
+
+// JSON is the document of the API, built from the operations and the models
+// the first time it is asked for.
+func JSON() ([]byte, error) {
+	specOnce.Do(func() {
+		specJSON, specErr = json.Marshal(Spec())
+	})
+
+	return specJSON, specErr
+}
+
+// Spec builds the OpenAPI document of every operation of the API.
+func Spec() Document {
+	schemas := newSchemaSet()
+
+	doc := Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Keuangan Pribadi API",
+			Description: "Personal finance API. Successful responses are wrapped in an envelope with a status, a message and the data, failed responses have a machine-readable code.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Responses: map[string]Response{},
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "A session token from /api/v1/users/login or a personal access token.",
+				},
+			},
+		},
+	}
+
+	schemas.defs["Error"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "string", Enum: []interface{}{"failed"}},
+			"message": {Type: "string"},
+			"code":    {Type: "string", Description: "machine-readable reason, like finance_not_found or validation_failed"},
+		},
+		Required: []string{"status", "message", "code"},
+	}
+
+	for status, name := range errorResponses {
+		doc.Components.Responses[name] = Response{
+			Description: http.StatusText(status),
+			Content:     jsonContent(ref("Error")),
+		}
+	}
+
+	tags := map[string]bool{}
+
+	for _, op := range operations {
+		path := OpenAPIPath(op.Path)
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+
+		doc.Paths[path][strings.ToLower(op.Method)] = op.build(schemas)
+
+		if !tags[op.Tag] {
+			tags[op.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: op.Tag})
+		}
+	}
+
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	doc.Components.Schemas = schemas.defs
+
+	return doc
+}
+
+func (op operation) build(schemas *schemaSet) *Operation {
+	built := &Operation{
+		Tags:        []string{op.Tag},
+		Summary:     op.Summary,
+		OperationID: op.ID,
+		Responses:   map[string]Response{},
+	}
+
+	var descriptions []string
+
+	for _, match := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+		schema := &Schema{Type: "integer", Minimum: float(1)}
+		switch match[1] {
+		case "email":
+			schema = &Schema{Type: "string", Format: "email"}
+		case "symbol":
+			schema = &Schema{Type: "string", Enum: commoditySymbols()}
+		}
+
+		built.Parameters = append(built.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+	}
+
+	if op.Query != nil {
+		built.Parameters = append(built.Parameters, schemas.queryParameters(op.Query)...)
+	}
+
+	built.Parameters = append(built.Parameters, op.Params...)
+
+	if op.Body != nil {
+		built.RequestBody = &RequestBody{Required: true, Content: jsonContent(schemas.of(op.Body))}
+		built.Responses["400"] = Response{Ref: "#/components/responses/BadRequest"}
+		built.Responses["409"] = Response{Ref: "#/components/responses/Conflict"}
+	}
+
+	status := fmt.Sprint(op.Status)
+
+	switch {
+	case op.ContentType != "":
+		schema := &Schema{Type: "string", Format: "binary"}
+		switch op.ContentType {
+		case "application/json":
+			schema = &Schema{Type: "object"}
+		case "text/html", "text/plain":
+			schema = &Schema{Type: "string"}
+		}
+
+		built.Responses[status] = Response{
+			Description: op.Summary,
+			Content:     map[string]MediaType{op.ContentType: {Schema: schema}},
+		}
+	case op.Raw != nil:
+		built.Responses[status] = Response{Description: op.Summary, Content: jsonContent(schemas.of(op.Raw))}
+	default:
+		envelope := &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"status":  {Type: "string", Enum: []interface{}{"success"}},
+				"message": {Type: "string"},
+			},
+			Required: []string{"status", "message"},
+		}
+
+		if op.Data != nil {
+			envelope.Properties["data"] = schemas.of(op.Data)
+			envelope.Required = append(envelope.Required, "data")
+		}
+
+		built.Responses[status] = Response{Description: op.Summary, Content: jsonContent(envelope)}
+	}
+
+	if op.Scope != "" {
+		built.Security = []map[string][]string{{"bearerAuth": {}}}
+		built.Responses["401"] = Response{Ref: "#/components/responses/Unauthorized"}
+		built.Responses["403"] = Response{Ref: "#/components/responses/Forbidden"}
+		built.Responses["429"] = Response{Ref: "#/components/responses/TooManyRequests"}
+
+		descriptions = append(descriptions, fmt.Sprintf("Personal access tokens need the `%s` scope.", op.Scope))
+	}
+
+	if op.Admin {
+		descriptions = append(descriptions, "Only for admins.")
+	}
+
+	if strings.Contains(op.Path, ":") {
+		built.Responses["404"] = Response{Ref: "#/components/responses/NotFound"}
+	}
+
+	if op.RateLimited {
+		built.Responses["429"] = Response{Ref: "#/components/responses/TooManyRequests"}
+	}
+
+	built.Responses["500"] = Response{Ref: "#/components/responses/InternalError"}
+	built.Responses["503"] = Response{Ref: "#/components/responses/Timeout"}
+
+	built.Description = strings.TrimSpace(strings.Join(append([]string{op.Description}, descriptions...), " "))
+
+	return built
+}
+
+// commoditySymbols are the symbols of the commodities in the lower case the
+// paths use, like coffee.
+func commoditySymbols() []interface{} {
+	var symbols []string
+	for symbol := range marketdata.Commodities {
+		symbols = append(symbols, strings.ToLower(symbol))
+	}
+
+	sort.Strings(symbols)
+
+	enum := make([]interface{}, len(symbols))
+	for i, symbol := range symbols {
+		enum[i] = symbol
+	}
+
+	return enum
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,153 @@
+package docs
+
+import (
+	"keuangan-pribadi/models"
+	"net/http"
+)
+
+// operation describes a route of route.New. The schemas of the bodies come
+// from the models, so changing a model changes the document with it.
+type operation struct {
+	Method string
+	// Path is the echo path, with :name parameters
+	Path        string
+	ID          string
+	Tag         string
+	Summary     string
+	Description string
+	// Scope is the scope a personal access token needs, the operations
+	// without one are public
+	Scope       string
+	Admin       bool
+	RateLimited bool
+	// Query is a filter struct bound from the query string by its query tags
+	Query  interface{}
+	Params []Parameter
+	Body   interface{}
+	Status int
+	// Data is the data of the response envelope, nil when there is only a
+	// message
+	Data interface{}
+	// Raw is a response written without the envelope
+	Raw interface{}
+	// ContentType is set for responses that are not JSON
+	ContentType string
+}
+
+var operations = []operation{
+	{Method: http.MethodGet, Path: "/healthz", ID: "getLiveness", Tag: "health", Summary: "Liveness probe, answers while the process serves requests", Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/readyz", ID: "getReadiness", Tag: "health", Summary: "Readiness probe, checks the database and its migrations", Status: http.StatusOK, Data: models.Health{}},
+	{Method: http.MethodGet, Path: "/metrics", ID: "getMetrics", Tag: "health", Summary: "Metrics in the Prometheus text format", Status: http.StatusOK, ContentType: "text/plain"},
+
+	{Method: http.MethodGet, Path: "/api/docs", ID: "getDocs", Tag: "docs", Summary: "Interactive API documentation", Status: http.StatusOK, ContentType: "text/html"},
+	{Method: http.MethodGet, Path: "/api/docs/openapi.json", ID: "getOpenAPI", Tag: "docs", Summary: "This OpenAPI document", Status: http.StatusOK, ContentType: "application/json"},
+
+	{Method: http.MethodGet, Path: "/api/v1/coffee", ID: "getCoffeePrice", Tag: "prices", Summary: "Monthly coffee prices", Params: []Parameter{
+		{Name: "currency", In: "query", Description: "currency the prices are converted to, like IDR, the default currency without one", Schema: &Schema{Type: "string"}},
+		{Name: "locale", In: "query", Description: "locale the prices are formatted for, like id-ID or en-US", Schema: &Schema{Type: "string"}},
+	}, Description: "Prices are cached, when the market-data provider is down the last known prices are answered with stale set.", Status: http.StatusOK, Raw: models.CoffeePriceResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/commodities/:symbol", ID: "getCommodityPrices", Tag: "prices", Summary: "Prices of a commodity with statistics", Query: models.CommodityPriceFilter{}, Description: "Prices of coffee, sugar, wheat, corn and the other commodities, converted to a currency, with the minimum, maximum, average and percent change over the window. Oil and natural gas have daily, weekly and monthly prices, the others monthly, quarterly and annual ones. Prices are cached, when the market-data provider is down the last known prices are answered with stale set.", Status: http.StatusOK, Data: models.CommodityPrices{}},
+
+	{Method: http.MethodPost, Path: "/api/v1/users/login", ID: "login", Tag: "users", Summary: "Log in and get a session token", RateLimited: true, Body: models.UserAuth{}, Status: http.StatusOK, Data: models.UserResponse{}},
+	{Method: http.MethodPost, Path: "/api/v1/users/register", ID: "register", Tag: "users", Summary: "Register a user", RateLimited: true, Body: models.UserInput{}, Status: http.StatusCreated, Data: models.User{}},
+	{Method: http.MethodPost, Path: "/api/v1/users/email/verify", ID: "verifyEmail", Tag: "users", Summary: "Confirm a new email address", RateLimited: true, Body: models.UserVerifyEmail{}, Status: http.StatusOK, Data: models.User{}},
+	{Method: http.MethodGet, Path: "/api/v1/users/:email", ID: "getUserByEmail", Tag: "users", Summary: "Get a user by email", Scope: "users", Status: http.StatusOK, Data: models.UserResponse{}},
+	{Method: http.MethodPut, Path: "/api/v1/users", ID: "updateUser", Tag: "users", Summary: "Update the current user", Scope: "users", Body: models.UserUpdate{}, Status: http.StatusOK, Data: models.User{}},
+	{Method: http.MethodPut, Path: "/api/v1/users/password", ID: "changePassword", Tag: "users", Summary: "Change the password, other sessions are revoked", Scope: "users", Body: models.UserChangePassword{}, Status: http.StatusOK, Data: models.UserResponse{}},
+	{Method: http.MethodPut, Path: "/api/v1/users/email", ID: "changeEmail", Tag: "users", Summary: "Request a change of the email address", Scope: "users", Body: models.UserChangeEmail{}, Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/users/me/export", ID: "exportUser", Tag: "users", Summary: "Download all data of the current user as a zip archive", Scope: "users", Status: http.StatusOK, ContentType: "application/zip"},
+	{Method: http.MethodDelete, Path: "/api/v1/users/me", ID: "deleteAccount", Tag: "users", Summary: "Delete the current user and their data", Description: "Entries in shared ledgers are handed over to the household. The audit log keeps the changes, but no longer ties them to the user: who made them, from where and the snapshots of the account are removed.", Scope: "users", Body: models.UserDeleteAccount{}, Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/admin/users", ID: "listUsers", Tag: "admin", Summary: "List users", Scope: "users", Admin: true, Status: http.StatusOK, Data: []models.UserResponse{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/users/:id/role", ID: "updateUserRole", Tag: "admin", Summary: "Change the role of a user", Scope: "users", Admin: true, Body: models.UserRole{}, Status: http.StatusOK, Data: models.UserResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/admin/users/:id", ID: "deleteUser", Tag: "admin", Summary: "Delete a user", Description: "The account is deleted like by its user: entries in shared ledgers are handed over to the household and the audit log no longer names it. Admins delete their own account through deleteAccount.", Scope: "users", Admin: true, Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/admin/stats", ID: "getStats", Tag: "admin", Summary: "Counts of users and records", Scope: "users", Admin: true, Status: http.StatusOK, Data: models.Stat{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/tokens", ID: "listTokens", Tag: "tokens", Summary: "List personal access tokens", Scope: "tokens", Status: http.StatusOK, Data: []models.PersonalAccessToken{}},
+	{Method: http.MethodPost, Path: "/api/v1/tokens", ID: "createToken", Tag: "tokens", Summary: "Create a personal access token, the token is only shown once", Description: "A request made with a personal access token can only grant the scopes that token holds.", Scope: "tokens", Body: models.PersonalAccessTokenInput{}, Status: http.StatusCreated, Data: models.PersonalAccessTokenResponse{}},
+	{Method: http.MethodDelete, Path: "/api/v1/tokens/:id", ID: "deleteToken", Tag: "tokens", Summary: "Revoke a personal access token", Scope: "tokens", Status: http.StatusOK},
+
+	{Method: http.MethodGet, Path: "/api/v1/backups", ID: "createBackup", Tag: "backups", Summary: "Download a backup of the personal ledger", Description: "Finances, savings, debts with their repayments, bills and gold savings of the personal ledger. Shared ledgers belong to their household and are not included.", Scope: "backups", Status: http.StatusOK, Raw: models.Backup{}},
+	{Method: http.MethodPost, Path: "/api/v1/backups/restore", ID: "restoreBackup", Tag: "backups", Summary: "Restore a backup", Scope: "backups", Params: []Parameter{
+		{Name: "mode", In: "query", Description: "replace deletes the content of the personal ledger first, merge adds to it", Schema: &Schema{Type: "string", Enum: []interface{}{models.RestoreModeReplace, models.RestoreModeMerge}}},
+	}, Body: models.Backup{}, Status: http.StatusOK, Data: models.RestoreResult{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/categories", ID: "listCategories", Tag: "categories", Summary: "List the shared categories", Scope: "categories", Status: http.StatusOK, Data: []models.Category{}},
+	{Method: http.MethodGet, Path: "/api/v1/categories/:id", ID: "getCategory", Tag: "categories", Summary: "Get a category", Scope: "categories", Status: http.StatusOK, Data: models.Category{}},
+	{Method: http.MethodPost, Path: "/api/v1/categories", ID: "createCategory", Tag: "categories", Summary: "Create a shared category", Scope: "categories", Admin: true, Body: models.CategoryInput{}, Status: http.StatusCreated, Data: models.Category{}},
+	{Method: http.MethodPut, Path: "/api/v1/categories/:id", ID: "updateCategory", Tag: "categories", Summary: "Update a shared category", Scope: "categories", Admin: true, Body: models.CategoryInput{}, Status: http.StatusOK, Data: models.Category{}},
+	{Method: http.MethodDelete, Path: "/api/v1/categories/:id", ID: "deleteCategory", Tag: "categories", Summary: "Delete a shared category", Scope: "categories", Admin: true, Status: http.StatusOK},
+
+	{Method: http.MethodGet, Path: "/api/v1/ledgers", ID: "listLedgers", Tag: "ledgers", Summary: "List the ledgers the user is a member of", Scope: "ledgers", Status: http.StatusOK, Data: []models.Ledger{}},
+	{Method: http.MethodPost, Path: "/api/v1/ledgers", ID: "createLedger", Tag: "ledgers", Summary: "Create a shared ledger", Scope: "ledgers", Body: models.LedgerInput{}, Status: http.StatusCreated, Data: models.Ledger{}},
+	{Method: http.MethodPost, Path: "/api/v1/ledgers/invitations/accept", ID: "acceptLedgerInvitation", Tag: "ledgers", Summary: "Accept an invitation to a ledger", Scope: "ledgers", Body: models.LedgerInvitationAccept{}, Status: http.StatusOK, Data: models.LedgerMember{}},
+	{Method: http.MethodGet, Path: "/api/v1/ledgers/:id", ID: "getLedger", Tag: "ledgers", Summary: "Get a ledger", Scope: "ledgers", Status: http.StatusOK, Data: models.Ledger{}},
+	{Method: http.MethodPut, Path: "/api/v1/ledgers/:id", ID: "updateLedger", Tag: "ledgers", Summary: "Rename a ledger", Scope: "ledgers", Body: models.LedgerInput{}, Status: http.StatusOK, Data: models.Ledger{}},
+	{Method: http.MethodDelete, Path: "/api/v1/ledgers/:id", ID: "deleteLedger", Tag: "ledgers", Summary: "Delete a ledger", Scope: "ledgers", Status: http.StatusOK},
+	{Method: http.MethodPut, Path: "/api/v1/ledgers/:id/members/:user_id", ID: "updateLedgerMember", Tag: "ledgers", Summary: "Change the role of a member", Scope: "ledgers", Body: models.LedgerMemberInput{}, Status: http.StatusOK, Data: models.LedgerMember{}},
+	{Method: http.MethodDelete, Path: "/api/v1/ledgers/:id/members/:user_id", ID: "removeLedgerMember", Tag: "ledgers", Summary: "Remove a member", Scope: "ledgers", Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/api/v1/ledgers/:id/invitations", ID: "inviteLedgerMember", Tag: "ledgers", Summary: "Invite someone by email", Scope: "ledgers", Body: models.LedgerInvitationInput{}, Status: http.StatusCreated, Data: models.LedgerInvitation{}},
+	{Method: http.MethodGet, Path: "/api/v1/ledgers/:id/categories", ID: "listLedgerCategories", Tag: "ledgers", Summary: "List the shared categories and the ones of the ledger", Scope: "ledgers", Status: http.StatusOK, Data: []models.Category{}},
+	{Method: http.MethodPost, Path: "/api/v1/ledgers/:id/categories", ID: "createLedgerCategory", Tag: "ledgers", Summary: "Create a category of the ledger", Scope: "ledgers", Body: models.CategoryInput{}, Status: http.StatusCreated, Data: models.Category{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/audit", ID: "listAuditLogs", Tag: "audit", Summary: "List the changes made to the data of the user", Description: "Every change is written together with its entry, a change that cannot be audited is not made. Entries are kept when an account is deleted, without what identifies its user.", Scope: "audit", Query: models.AuditLogFilter{}, Status: http.StatusOK, Data: []models.AuditLog{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/finances", ID: "listFinances", Tag: "finances", Summary: "List finances", Scope: "finances", Status: http.StatusOK, Data: []models.Finance{}},
+	{Method: http.MethodGet, Path: "/api/v1/finances/:id", ID: "getFinance", Tag: "finances", Summary: "Get a finance", Scope: "finances", Status: http.StatusOK, Data: models.Finance{}},
+	{Method: http.MethodGet, Path: "/api/v1/finances/search", ID: "searchFinances", Tag: "finances", Summary: "List the finances created between two dates", Scope: "finances", Params: []Parameter{
+		{Name: "from", In: "query", Required: true, Schema: &Schema{Type: "string", Format: "date"}},
+		{Name: "to", In: "query", Required: true, Schema: &Schema{Type: "string", Format: "date"}},
+	}, Status: http.StatusOK, Data: []models.Finance{}},
+	{Method: http.MethodPost, Path: "/api/v1/finances", ID: "createFinance", Tag: "finances", Summary: "Create a finance", Scope: "finances", Body: models.FinanceInput{}, Status: http.StatusCreated, Data: models.Finance{}},
+	{Method: http.MethodPut, Path: "/api/v1/finances/:id", ID: "updateFinance", Tag: "finances", Summary: "Update a finance", Scope: "finances", Body: models.FinanceInput{}, Status: http.StatusOK, Data: models.Finance{}},
+	{Method: http.MethodDelete, Path: "/api/v1/finances/:id", ID: "deleteFinance", Tag: "finances", Summary: "Delete a finance", Scope: "finances", Status: http.StatusOK},
+
+	{Method: http.MethodGet, Path: "/api/v1/savings", ID: "listSavings", Tag: "savings", Summary: "List savings", Scope: "savings", Status: http.StatusOK, Data: []models.Saving{}},
+	{Method: http.MethodGet, Path: "/api/v1/savings/:id", ID: "getSaving", Tag: "savings", Summary: "Get a saving", Scope: "savings", Status: http.StatusOK, Data: models.Saving{}},
+	{Method: http.MethodPost, Path: "/api/v1/savings", ID: "createSaving", Tag: "savings", Summary: "Create a saving", Scope: "savings", Body: models.SavingInput{}, Status: http.StatusCreated, Data: models.Saving{}},
+	{Method: http.MethodPut, Path: "/api/v1/savings/:id", ID: "updateSaving", Tag: "savings", Summary: "Update a saving", Scope: "savings", Body: models.SavingUpdate{}, Status: http.StatusOK, Data: models.Saving{}},
+	{Method: http.MethodDelete, Path: "/api/v1/savings/:id", ID: "deleteSaving", Tag: "savings", Summary: "Delete a saving", Scope: "savings", Status: http.StatusOK},
+
+	{Method: http.MethodGet, Path: "/api/v1/detail-savings", ID: "listDetailSavings", Tag: "detail-savings", Summary: "List saving deposits and withdrawals", Scope: "detail-savings", Status: http.StatusOK, Data: []models.DetailSaving{}},
+	{Method: http.MethodGet, Path: "/api/v1/detail-savings/:id", ID: "getDetailSaving", Tag: "detail-savings", Summary: "Get a saving deposit or withdrawal", Scope: "detail-savings", Status: http.StatusOK, Data: models.DetailSaving{}},
+	{Method: http.MethodPost, Path: "/api/v1/detail-savings", ID: "createDetailSaving", Tag: "detail-savings", Summary: "Deposit to or withdraw from a saving", Scope: "detail-savings", Body: models.DetailSavingInput{}, Status: http.StatusCreated, Data: models.DetailSaving{}},
+	{Method: http.MethodPut, Path: "/api/v1/detail-savings/:id", ID: "updateDetailSaving", Tag: "detail-savings", Summary: "Update a saving deposit or withdrawal", Scope: "detail-savings", Body: models.DetailSavingInput{}, Status: http.StatusOK, Data: models.DetailSaving{}},
+	{Method: http.MethodDelete, Path: "/api/v1/detail-savings/:id", ID: "deleteDetailSaving", Tag: "detail-savings", Summary: "Delete a saving deposit or withdrawal", Scope: "detail-savings", Status: http.StatusOK},
+
+	{Method: http.MethodGet, Path: "/api/v1/gold-savings", ID: "listGoldSavings", Tag: "gold-savings", Summary: "List gold savings", Scope: "gold-savings", Status: http.StatusOK, Data: []models.GoldSaving{}},
+	{Method: http.MethodGet, Path: "/api/v1/gold-savings/:id", ID: "getGoldSaving", Tag: "gold-savings", Summary: "Get a gold saving with its deposits", Scope: "gold-savings", Status: http.StatusOK, Data: models.GoldSaving{}},
+	{Method: http.MethodPost, Path: "/api/v1/gold-savings", ID: "createGoldSaving", Tag: "gold-savings", Summary: "Create a gold saving with a goal in grams", Scope: "gold-savings", Body: models.GoldSavingInput{}, Status: http.StatusCreated, Data: models.GoldSaving{}},
+	{Method: http.MethodPut, Path: "/api/v1/gold-savings/:id", ID: "updateGoldSaving", Tag: "gold-savings", Summary: "Update a gold saving", Scope: "gold-savings", Body: models.GoldSavingUpdate{}, Status: http.StatusOK, Data: models.GoldSaving{}},
+	{Method: http.MethodDelete, Path: "/api/v1/gold-savings/:id", ID: "deleteGoldSaving", Tag: "gold-savings", Summary: "Delete a gold saving with its deposits", Scope: "gold-savings", Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/api/v1/gold-savings/:id/deposits", ID: "depositGold", Tag: "gold-savings", Summary: "Record gold bought for a saving", Description: "The price is what was paid for all the grams. Every deposit of a saving is paid in the same currency.", Scope: "gold-savings", Body: models.GoldDepositInput{}, Status: http.StatusCreated, Data: models.GoldDeposit{}},
+	{Method: http.MethodDelete, Path: "/api/v1/gold-savings/:id/deposits/:deposit_id", ID: "deleteGoldDeposit", Tag: "gold-savings", Summary: "Delete a gold deposit", Scope: "gold-savings", Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/gold-savings/:id/valuation", ID: "getGoldValuation", Tag: "gold-savings", Summary: "Value a gold saving at the current gold price", Params: []Parameter{
+		{Name: "locale", In: "query", Description: "locale the amounts are formatted for, like id-ID or en-US", Schema: &Schema{Type: "string"}},
+	}, Description: "The value and unrealized gain are in the currency the gold was bought in. The gold price is cached, when the market-data provider is down the last known price is used with stale set.", Scope: "gold-savings", Status: http.StatusOK, Data: models.GoldValuation{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/debts", ID: "listDebts", Tag: "debts", Summary: "List debts", Scope: "debts", Status: http.StatusOK, Data: []models.Debt{}},
+	{Method: http.MethodGet, Path: "/api/v1/debts/overdue", ID: "listOverdueDebts", Tag: "debts", Summary: "List the unsettled debts past their due date", Scope: "debts", Status: http.StatusOK, Data: []models.Debt{}},
+	{Method: http.MethodGet, Path: "/api/v1/debts/summary", ID: "getDebtSummary", Tag: "debts", Summary: "Outstanding totals per direction and counterparty", Scope: "debts", Status: http.StatusOK, Data: models.DebtSummary{}},
+	{Method: http.MethodGet, Path: "/api/v1/debts/:id", ID: "getDebt", Tag: "debts", Summary: "Get a debt with its repayments", Scope: "debts", Status: http.StatusOK, Data: models.Debt{}},
+	{Method: http.MethodPost, Path: "/api/v1/debts", ID: "createDebt", Tag: "debts", Summary: "Create a debt", Scope: "debts", Body: models.DebtInput{}, Status: http.StatusCreated, Data: models.Debt{}},
+	{Method: http.MethodPut, Path: "/api/v1/debts/:id", ID: "updateDebt", Tag: "debts", Summary: "Update a debt", Scope: "debts", Body: models.DebtInput{}, Status: http.StatusOK, Data: models.Debt{}},
+	{Method: http.MethodDelete, Path: "/api/v1/debts/:id", ID: "deleteDebt", Tag: "debts", Summary: "Delete a debt", Scope: "debts", Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/api/v1/debts/:id/repayments", ID: "repayDebt", Tag: "debts", Summary: "Record a repayment, a finance is created for it", Description: "The token also needs the `finances` scope.", Scope: "debts", Body: models.DebtRepaymentInput{}, Status: http.StatusCreated, Data: models.DebtRepayment{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/bills", ID: "listBills", Tag: "bills", Summary: "List bills", Scope: "bills", Status: http.StatusOK, Data: []models.Bill{}},
+	{Method: http.MethodGet, Path: "/api/v1/bills/:id", ID: "getBill", Tag: "bills", Summary: "Get a bill", Scope: "bills", Status: http.StatusOK, Data: models.Bill{}},
+	{Method: http.MethodPost, Path: "/api/v1/bills", ID: "createBill", Tag: "bills", Summary: "Create a bill", Scope: "bills", Body: models.BillInput{}, Status: http.StatusCreated, Data: models.Bill{}},
+	{Method: http.MethodPut, Path: "/api/v1/bills/:id", ID: "updateBill", Tag: "bills", Summary: "Update a bill", Scope: "bills", Body: models.BillInput{}, Status: http.StatusOK, Data: models.Bill{}},
+	{Method: http.MethodDelete, Path: "/api/v1/bills/:id", ID: "deleteBill", Tag: "bills", Summary: "Delete a bill", Scope: "bills", Status: http.StatusOK},
+	{Method: http.MethodPost, Path: "/api/v1/bills/:id/pay", ID: "payBill", Tag: "bills", Summary: "Pay a bill, a finance is created for it", Description: "The token also needs the `finances` scope.", Scope: "bills", Body: models.BillPayment{}, Status: http.StatusCreated, Data: models.Finance{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/notifications", ID: "listNotifications", Tag: "notifications", Summary: "List notifications", Scope: "notifications", Query: models.NotificationFilter{}, Status: http.StatusOK, Data: []models.Notification{}},
+	{Method: http.MethodPut, Path: "/api/v1/notifications/read", ID: "markAllNotificationsRead", Tag: "notifications", Summary: "Mark every notification as read", Scope: "notifications", Status: http.StatusOK},
+	{Method: http.MethodPut, Path: "/api/v1/notifications/:id/read", ID: "markNotificationRead", Tag: "notifications", Summary: "Mark a notification as read", Scope: "notifications", Status: http.StatusOK, Data: models.Notification{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/webhooks", ID: "listWebhooks", Tag: "webhooks", Summary: "List webhooks", Scope: "webhooks", Status: http.StatusOK, Data: []models.Webhook{}},
+	{Method: http.MethodPost, Path: "/api/v1/webhooks", ID: "createWebhook", Tag: "webhooks", Summary: "Create a webhook, the signing secret is only shown once", Description: "The URL must point at a public address. Events are finance.created, finance.updated, finance.deleted, saving.created, saving.goal_reached, debt.created, debt.settled and bill.paid, or * for all of them.", Scope: "webhooks", Body: models.WebhookInput{}, Status: http.StatusCreated, Data: models.WebhookResponse{}},
+	{Method: http.MethodPut, Path: "/api/v1/webhooks/:id", ID: "updateWebhook", Tag: "webhooks", Summary: "Update a webhook", Scope: "webhooks", Body: models.WebhookInput{}, Status: http.StatusOK, Data: models.Webhook{}},
+	{Method: http.MethodDelete, Path: "/api/v1/webhooks/:id", ID: "deleteWebhook", Tag: "webhooks", Summary: "Delete a webhook", Scope: "webhooks", Status: http.StatusOK},
+	{Method: http.MethodGet, Path: "/api/v1/webhooks/:id/deliveries", ID: "listWebhookDeliveries", Tag: "webhooks", Summary: "List the deliveries of a webhook", Scope: "webhooks", Status: http.StatusOK, Data: []models.WebhookDelivery{}},
+	{Method: http.MethodPost, Path: "/api/v1/webhooks/:id/test", ID: "testWebhook", Tag: "webhooks", Summary: "Send a test event to a webhook", Scope: "webhooks", Status: http.StatusOK, Data: models.WebhookDelivery{}},
+}
@@ -0,0 +1,260 @@
+package docs
+
+import (
+	"encoding/json"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// knownTypes are the types with their own JSON encoding.
+var knownTypes = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(time.Time{}): func() *Schema {
+		return &Schema{Type: "string", Format: "date-time"}
+	},
+	reflect.TypeOf(gorm.DeletedAt{}): func() *Schema {
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	},
+	reflect.TypeOf(json.RawMessage{}): func() *Schema {
+		return &Schema{Description: "any JSON value"}
+	},
+	reflect.TypeOf(money.Money{}): func() *Schema {
+		return ref("Money")
+	},
+	reflect.TypeOf(models.Grams(0)): func() *Schema {
+		return &Schema{Type: "string", Pattern: `^-?\d+(\.\d{1,4})?$`, Description: "weight in grams with at most 4 decimals, requests may also send a number"}
+	},
+}
+
+// schemaSet turns Go types into schemas. Named structs become components
+// referenced by their name, so a model is described once however often it
+// is used.
+type schemaSet struct {
+	defs map[string]*Schema
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{defs: map[string]*Schema{
+		"Money": {
+			Type:        "object",
+			Description: "An amount in a currency. Requests may also send the amount as a number, or send a bare amount in the default currency.",
+			Properties: map[string]*Schema{
+				"amount":   {Type: "string", Pattern: `^-?\d+(\.\d+)?$`, Description: "decimal amount, a string so no precision is lost"},
+				"currency": {Type: "string", Pattern: "^[A-Z]{3}$", Description: "ISO 4217 code"},
+			},
+			Required: []string{"amount", "currency"},
+		},
+	}}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// of is the schema of the type of the value.
+func (ss *schemaSet) of(value interface{}) *Schema {
+	return ss.schema(reflect.TypeOf(value))
+}
+
+func (ss *schemaSet) schema(t reflect.Type) *Schema {
+	if known, ok := knownTypes[t]; ok {
+		return known()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := ss.schema(t.Elem())
+		if schema.Ref != "" {
+			// siblings of $ref are ignored in OpenAPI 3.0
+			return schema
+		}
+
+		schema.Nullable = true
+
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: ss.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: ss.schema(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return ss.object(t)
+		}
+
+		if _, ok := ss.defs[t.Name()]; !ok {
+			// registered before the fields so recursive types end
+			ss.defs[t.Name()] = &Schema{}
+			*ss.defs[t.Name()] = *ss.object(t)
+		}
+
+		return ref(t.Name())
+	}
+
+	return &Schema{}
+}
+
+// object follows the rules of encoding/json for the property names, and the
+// validate tags for the constraints of the request bodies.
+func (ss *schemaSet) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// fields of embedded structs are promoted
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := ss.object(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := ss.schema(field.Type)
+
+		if required := applyValidation(property, field.Tag.Get("validate")); required {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// applyValidation adds the constraints of a validate tag to the schema and
+// tells if the field is required.
+func applyValidation(schema *Schema, tag string) bool {
+	required := false
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "min", "max":
+			limit, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+
+			setLimit(schema, name == "min", limit)
+		}
+	}
+
+	return required
+}
+
+// setLimit sets the bound of min and max the way the validator reads them:
+// the length of strings and slices, the value of numbers. Money is validated
+// on its amount, which the schema cannot say, so it is left out.
+func setLimit(schema *Schema, lower bool, limit int) {
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &limit
+		} else {
+			schema.MaxLength = &limit
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &limit
+		} else {
+			schema.MaxItems = &limit
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = float(float64(limit))
+		} else {
+			schema.Maximum = float(float64(limit))
+		}
+	}
+}
+
+// queryParameters are the query parameters bound into a filter struct by
+// their query tags.
+func (ss *schemaSet) queryParameters(filter interface{}) []Parameter {
+	t := reflect.TypeOf(filter)
+
+	var parameters []Parameter
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := field.Tag.Get("query")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := ss.schema(field.Type)
+		required := applyValidation(schema, field.Tag.Get("validate"))
+
+		parameters = append(parameters, Parameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+
+	return parameters
+}
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"keuangan-pribadi/models"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/logger"
+)
+
+// records are the JSON lines written to buf.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// useLogger makes a JSON logger at level the default logger of the test.
+func useLogger(t *testing.T, level string) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	log, err := New(&buf, level, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := slog.Default()
+	slog.SetDefault(log)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf
+}
+
+func TestNew_Success(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, err := New(&buf, "warn", FormatText)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	log.Info("not written")
+	log.Warn("written", "finance_id", 7)
+
+	assert.NotContains(t, buf.String(), "not written")
+	assert.Contains(t, buf.String(), "level=WARN msg=written finance_id=7")
+}
+
+func TestNew_Failed(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose", FormatJSON)
+	assert.ErrorContains(t, err, `invalid log level "verbose"`)
+
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	assert.ErrorContains(t, err, `invalid log format "xml"`)
+}
+
+func TestContextHandler_Success(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, err := New(&buf, "info", FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	ctx = models.WithPrincipal(ctx, models.Principal{UserID: 42, Role: "user"})
+
+	// loggers derived with attributes still add the context
+	log.With("component", "reminder").InfoContext(ctx, "sent", "bill_id", 3)
+	log.Info("started")
+
+	lines := records(t, &buf)
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+
+	assert.Equal(t, "reminder", lines[0]["component"])
+	assert.Equal(t, "req-123", lines[0]["request_id"])
+	assert.Equal(t, float64(42), lines[0]["user_id"])
+
+	// outside of a request there is nothing to add
+	assert.NotContains(t, lines[1], "request_id")
+	assert.NotContains(t, lines[1], "user_id")
+	assert.Equal(t, "", RequestID(context.Background()))
+}
+
+func TestGormLogger_Trace(t *testing.T) {
+	buf := useLogger(t, "info")
+	ctx := WithRequestID(context.Background(), "req-456")
+	query := func() (string, int64) { return "SELECT * FROM finances", 3 }
+
+	gormLogger := NewGormLogger(100 * time.Millisecond)
+
+	// a missing record is not an error and fast queries are only debug
+	gormLogger.Trace(ctx, time.Now(), query, logger.ErrRecordNotFound)
+	gormLogger.Trace(ctx, time.Now(), query, nil)
+	assert.Empty(t, buf.String())
+
+	gormLogger.Trace(ctx, time.Now(), query, errors.New("no such table: finances"))
+	gormLogger.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+
+	lines := records(t, buf)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, "ERROR", lines[0]["level"])
+		assert.Equal(t, "query failed", lines[0]["msg"])
+		assert.Equal(t, "no such table: finances", lines[0]["error"])
+		assert.Equal(t, "req-456", lines[0]["request_id"])
+
+		assert.Equal(t, "WARN", lines[1]["level"])
+		assert.Equal(t, "slow query", lines[1]["msg"])
+		assert.Equal(t, "SELECT * FROM finances", lines[1]["sql"])
+		assert.Equal(t, float64(100), lines[1]["threshold_ms"])
+	}
+
+	// a silenced logger writes nothing at all
+	buf.Reset()
+	gormLogger.LogMode(logger.Silent).Trace(ctx, time.Now(), query, errors.New("disk I/O error"))
+	assert.Empty(t, buf.String())
+}
+
+func TestGormLogger_Debug(t *testing.T) {
+	buf := useLogger(t, "debug")
+
+	NewGormLogger(0).Trace(context.Background(), time.Now().Add(-time.Hour), func() (string, int64) {
+		return "SELECT * FROM users", 1
+	}, nil)
+
+	// without a threshold no query is slow
+	lines := records(t, buf)
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "DEBUG", lines[0]["level"])
+		assert.Equal(t, "query", lines[0]["msg"])
+		assert.Equal(t, float64(1), lines[0]["rows"])
+	}
+}
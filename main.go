@@ -2,9 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"keuangan-pribadi/config"
+	"keuangan-pribadi/logging"
+	"keuangan-pribadi/metrics"
+	m "keuangan-pribadi/middleware"
+	"keuangan-pribadi/migrations"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
 	"keuangan-pribadi/route"
-	"log"
+	"keuangan-pribadi/services"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,28 +24,104 @@ import (
 type operation func(ctx context.Context) error
 
 func main() {
-	e := route.New()
-	
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal(err)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal(err)
+	}
+
+	slog.SetDefault(logger)
+
+	m.ConfigureJWT(m.JWTConfig{SecretKey: cfg.Auth.JWTSecret, TTL: cfg.Auth.TokenTTL})
+
+	if err := money.SetDefaultCurrency(cfg.Currency); err != nil {
+		fatal(err)
+	}
+
+	db, err := config.InitDB(cfg.Database)
+	if err != nil {
+		fatal(err)
+	}
+
+	// `migrate up|down|status` manages the schema without starting the server
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(db, args[1:]); err != nil {
+			fatal(err)
+		}
+
+		return
+	}
+
+	// pending migrations are applied on boot, a newer schema stops the boot
+	if _, err := migrations.Up(db); err != nil {
+		fatal(err)
+	}
+
+	database, err := db.DB()
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := metrics.RegisterDB(database, cfg.Database.Name); err != nil {
+		fatal(err)
+	}
+
+	e := route.New(db, cfg)
+
+	billReminders := services.InitBillReminderScheduler(repositories.InitBillRepository(db), cfg.Jobs.BillReminderInterval)
+	billReminders.Start()
+
+	webhookDispatcher := services.InitWebhookDispatcher(repositories.InitWebhookRepository(db), cfg.Jobs.WebhookDeliveryInterval, cfg.Webhook.AllowPrivateTargets)
+	webhookDispatcher.Start()
+
 	go func() {
-		if err := e.Start(":1323"); err != nil && err != http.ErrServerClosed {
-			e.Logger.Fatal("shutting down the server")
+		slog.Info("http server started", "port", cfg.Port)
+
+		if err := e.Start(fmt.Sprintf(":%d", cfg.Port)); err != nil && err != http.ErrServerClosed {
+			fatal(err)
 		}
 	}()
-	
-	wait := gracefulShutdown(context.Background(), 2*time.Second, map[string]operation{
-		"database": func(ctx context.Context) error {
-			return config.CloseDB()
+
+	// in-flight requests and job batches still use the database, so the pool
+	// is closed last
+	wait := gracefulShutdown(context.Background(), cfg.Server.ShutdownTimeout,
+		map[string]operation{
+			"http-server": func(ctx context.Context) error {
+				return e.Shutdown(ctx)
+			},
+		},
+		map[string]operation{
+			"bill-reminders": func(ctx context.Context) error {
+				return billReminders.Stop(ctx)
+			},
+			"webhook-dispatcher": func(ctx context.Context) error {
+				return webhookDispatcher.Stop(ctx)
+			},
 		},
-		"http-server": func(ctx context.Context) error {
-			return e.Shutdown(context.Background())
+		map[string]operation{
+			"database": func(ctx context.Context) error {
+				return config.CloseDB(db)
+			},
 		},
-	})
+	)
 
 	<-wait
 }
 
-// gracefulShutdown performs application shut down gracefully.
-func gracefulShutdown(ctx context.Context, timeout time.Duration, ops map[string]operation) <-chan struct{} {
+// fatal logs the error that stops the boot and exits.
+func fatal(err error) {
+	slog.Error(err.Error())
+	os.Exit(1)
+}
+
+// gracefulShutdown performs application shut down gracefully. The stages run
+// one after another, the operations of a stage run at the same time, and all
+// of them share the timeout.
+func gracefulShutdown(ctx context.Context, timeout time.Duration, stages ...map[string]operation) <-chan struct{} {
 	wait := make(chan struct{})
 	go func() {
 		s := make(chan os.Signal, 1)
@@ -46,40 +130,46 @@ func gracefulShutdown(ctx context.Context, timeout time.Duration, ops map[string
 		signal.Notify(s, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 		<-s
 
-		log.Println("shutting down")
+		slog.Info("shutting down")
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
 
-		// set timeout for the ops to be done to prevent system hang
-		timeoutFunc := time.AfterFunc(timeout, func() {
-			log.Printf("timeout %d ms has been elapsed, force exit", timeout.Milliseconds())
-			os.Exit(0)
+		// operations that ignore the context must not hang the system, the
+		// grace period is a bit longer so the ones that do can report
+		timeoutFunc := time.AfterFunc(timeout+time.Second, func() {
+			slog.Error("shutdown timeout has been elapsed, force exit", "timeout_ms", timeout.Milliseconds())
+			os.Exit(1)
 		})
 
 		defer timeoutFunc.Stop()
 
-		var wg sync.WaitGroup
+		for _, ops := range stages {
+			var wg sync.WaitGroup
 
-		// Do the operations asynchronously to save time
-		for key, op := range ops {
-			wg.Add(1)
-			innerOp := op
-			innerKey := key
-			go func() {
-				defer wg.Done()
+			// Do the operations of a stage asynchronously to save time
+			for key, op := range ops {
+				wg.Add(1)
+				innerOp := op
+				innerKey := key
+				go func() {
+					defer wg.Done()
 
-				log.Printf("cleaning up: %s", innerKey)
-				if err := innerOp(ctx); err != nil {
-					log.Printf("%s: clean up failed: %s", innerKey, err.Error())
-					return
-				}
+					slog.Info("cleaning up", "operation", innerKey)
+					if err := innerOp(ctx); err != nil {
+						slog.Error("clean up failed", "operation", innerKey, "error", err)
+						return
+					}
 
-				log.Printf("%s was shutdown gracefully", innerKey)
-			}()
-		}
+					slog.Info("shutdown gracefully", "operation", innerKey)
+				}()
+			}
 
-		wg.Wait()
+			wg.Wait()
+		}
 
 		close(wait)
 	}()
 
 	return wait
-}
\ No newline at end of file
+}
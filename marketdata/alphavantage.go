@@ -0,0 +1,156 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"keuangan-pribadi/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const alphaVantageURL = "https://www.alphavantage.co/query"
+
+// AlphaVantage fetches the prices from https://www.alphavantage.co. Its
+// commodity prices are in US dollars.
+type AlphaVantage struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+func NewAlphaVantage(apiKey string, timeout time.Duration) *AlphaVantage {
+	return &AlphaVantage{
+		BaseURL: alphaVantageURL,
+		APIKey:  apiKey,
+		Client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (av *AlphaVantage) Commodity(ctx context.Context, symbol, interval string) (models.PriceSeries, error) {
+	var body struct {
+		Name     string `json:"name"`
+		Interval string `json:"interval"`
+		Unit     string `json:"unit"`
+		Data     []struct {
+			Date  string `json:"date"`
+			Value string `json:"value"`
+		} `json:"data"`
+	}
+
+	if err := av.query(ctx, url.Values{"function": {symbol}, "interval": {interval}}, &body); err != nil {
+		return models.PriceSeries{}, err
+	}
+
+	if len(body.Data) == 0 {
+		return models.PriceSeries{}, fmt.Errorf("alpha vantage has no %s prices of %s", interval, symbol)
+	}
+
+	// the unit is like "dollars per barrel" or "cents per pound", prices
+	// in cents are kept in dollars so every price converts with the same
+	// exchange rate
+	quoted, unit, _ := strings.Cut(body.Unit, " per ")
+	divisor := 1.0
+	if quoted == "cents" {
+		divisor = 100
+	}
+
+	series := models.PriceSeries{
+		Symbol:    symbol,
+		Name:      body.Name,
+		Interval:  body.Interval,
+		Unit:      unit,
+		Currency:  "USD",
+		FetchedAt: time.Now().UTC(),
+	}
+
+	// the newest price comes first, the series is oldest first
+	for i := len(body.Data) - 1; i >= 0; i-- {
+		date, err := time.Parse(time.DateOnly, body.Data[i].Date)
+		if err != nil {
+			return models.PriceSeries{}, fmt.Errorf("alpha vantage date %q: %w", body.Data[i].Date, err)
+		}
+
+		// months without a price are a "."
+		value, err := strconv.ParseFloat(body.Data[i].Value, 64)
+		if err != nil {
+			continue
+		}
+
+		series.Points = append(series.Points, models.PricePoint{Date: date, Value: value / divisor})
+	}
+
+	return series, nil
+}
+
+func (av *AlphaVantage) ExchangeRate(ctx context.Context, from, to string) (models.ExchangeRate, error) {
+	var body struct {
+		Rate struct {
+			ExchangeRate string `json:"5. Exchange Rate"`
+		} `json:"Realtime Currency Exchange Rate"`
+	}
+
+	if err := av.query(ctx, url.Values{"function": {"CURRENCY_EXCHANGE_RATE"}, "from_currency": {from}, "to_currency": {to}}, &body); err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	rate, err := strconv.ParseFloat(body.Rate.ExchangeRate, 64)
+	if err != nil || rate <= 0 {
+		return models.ExchangeRate{}, fmt.Errorf("alpha vantage has no exchange rate from %s to %s", from, to)
+	}
+
+	return models.ExchangeRate{From: from, To: to, Rate: rate, FetchedAt: time.Now().UTC()}, nil
+}
+
+// query calls a function of the API and decodes the answer. Failures are
+// answered with status 200 and a message instead of the data.
+func (av *AlphaVantage) query(ctx context.Context, params url.Values, v interface{}) error {
+	if av.APIKey == "" {
+		return errors.New("alpha vantage API key is not configured")
+	}
+
+	params.Set("apikey", av.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, av.BaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := av.Client.Do(req)
+	if err != nil {
+		// the error of the client has the URL, and so the key, in it
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return fmt.Errorf("alpha vantage %s: %w", params.Get("function"), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("alpha vantage %s: status %d", params.Get("function"), resp.StatusCode)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("alpha vantage %s: %w", params.Get("function"), err)
+	}
+
+	for _, key := range []string{"Error Message", "Information", "Note"} {
+		if message, ok := raw[key]; ok {
+			return fmt.Errorf("alpha vantage %s: %s", params.Get("function"), message)
+		}
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, v)
+}
@@ -0,0 +1,141 @@
+package marketdata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestAlphaVantage is a client of a server answering every query with
+// status and body.
+func newTestAlphaVantage(t *testing.T, status int, body string) (*AlphaVantage, *http.Request) {
+	var received http.Request
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = *r
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	av := NewAlphaVantage("testkey", time.Second)
+	av.BaseURL = server.URL
+
+	return av, &received
+}
+
+func TestAlphaVantageCommodity_Success(t *testing.T) {
+	av, received := newTestAlphaVantage(t, http.StatusOK, `{
+		"name": "Global Price of Coffee",
+		"interval": "monthly",
+		"unit": "cents per pound",
+		"data": [
+			{"date": "2024-03-01", "value": "250.5"},
+			{"date": "2024-02-01", "value": "."},
+			{"date": "2024-01-01", "value": "230"}
+		]
+	}`)
+
+	series, err := av.Commodity(context.Background(), "COFFEE", IntervalMonthly)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "COFFEE", received.URL.Query().Get("function"))
+	assert.Equal(t, "monthly", received.URL.Query().Get("interval"))
+	assert.Equal(t, "testkey", received.URL.Query().Get("apikey"))
+
+	assert.Equal(t, "Global Price of Coffee", series.Name)
+	assert.Equal(t, "pound", series.Unit)
+	assert.Equal(t, "USD", series.Currency)
+
+	// oldest first, in dollars, the month without a price is left out
+	if assert.Len(t, series.Points, 2) {
+		assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), series.Points[0].Date)
+		assert.Equal(t, 2.3, series.Points[0].Value)
+		assert.Equal(t, 2.505, series.Points[1].Value)
+	}
+}
+
+func TestAlphaVantageCommodity_Failed(t *testing.T) {
+	for _, testcase := range []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"rate limited", http.StatusOK, `{"Information": "the rate limit is 25 requests per day"}`},
+		{"error message", http.StatusOK, `{"Error Message": "Invalid API call"}`},
+		{"note", http.StatusOK, `{"Note": "Thank you for using Alpha Vantage"}`},
+		{"no data", http.StatusOK, `{"name": "Global Price of Coffee", "data": []}`},
+		{"invalid date", http.StatusOK, `{"unit": "dollars per barrel", "data": [{"date": "March 2024", "value": "80"}]}`},
+		{"not json", http.StatusOK, `<html>maintenance</html>`},
+		{"server error", http.StatusInternalServerError, `{}`},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			av, _ := newTestAlphaVantage(t, testcase.status, testcase.body)
+
+			_, err := av.Commodity(context.Background(), "COFFEE", IntervalMonthly)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAlphaVantageExchangeRate_Success(t *testing.T) {
+	av, received := newTestAlphaVantage(t, http.StatusOK, `{
+		"Realtime Currency Exchange Rate": {
+			"1. From_Currency Code": "USD",
+			"3. To_Currency Code": "IDR",
+			"5. Exchange Rate": "16250.50000000"
+		}
+	}`)
+
+	rate, err := av.ExchangeRate(context.Background(), "USD", "IDR")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 16250.5, rate.Rate)
+		assert.Equal(t, "USD", rate.From)
+		assert.Equal(t, "IDR", rate.To)
+	}
+
+	assert.Equal(t, "CURRENCY_EXCHANGE_RATE", received.URL.Query().Get("function"))
+	assert.Equal(t, "USD", received.URL.Query().Get("from_currency"))
+	assert.Equal(t, "IDR", received.URL.Query().Get("to_currency"))
+}
+
+func TestAlphaVantageExchangeRate_Failed(t *testing.T) {
+	for _, body := range []string{
+		`{}`,
+		`{"Realtime Currency Exchange Rate": {"5. Exchange Rate": "0"}}`,
+		`{"Realtime Currency Exchange Rate": {"5. Exchange Rate": "n/a"}}`,
+	} {
+		av, _ := newTestAlphaVantage(t, http.StatusOK, body)
+
+		_, err := av.ExchangeRate(context.Background(), "USD", "XYZ")
+
+		assert.Error(t, err, body)
+	}
+}
+
+func TestAlphaVantageQuery_Failed(t *testing.T) {
+	// without a key nothing is asked
+	av := NewAlphaVantage("", time.Second)
+
+	_, err := av.Commodity(context.Background(), "COFFEE", IntervalMonthly)
+	assert.ErrorContains(t, err, "API key is not configured")
+
+	// the key is in the URL, it must not end up in the error and the logs
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	av = NewAlphaVantage("secretkey", time.Second)
+	av.BaseURL = server.URL
+
+	_, err = av.ExchangeRate(context.Background(), "USD", "IDR")
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "secretkey")
+	}
+}
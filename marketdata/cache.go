@@ -0,0 +1,126 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"keuangan-pribadi/models"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Cache keeps the answers of a provider for a while, the free plans of the
+// providers allow only a few calls a day. When the provider fails the last
+// answer is given again, even an expired one, and without one the prices
+// stored in the history, both marked as stale.
+type Cache struct {
+	provider MarketDataProvider
+	history  History
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	series map[string]models.PriceSeries
+	rates  map[string]models.ExchangeRate
+}
+
+func NewCache(provider MarketDataProvider, history History, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: provider,
+		history:  history,
+		ttl:      ttl,
+		now:      time.Now,
+		series:   map[string]models.PriceSeries{},
+		rates:    map[string]models.ExchangeRate{},
+	}
+}
+
+func (mc *Cache) Commodity(ctx context.Context, symbol, interval string) (models.PriceSeries, error) {
+	key := symbol + "/" + interval
+
+	mc.mu.Lock()
+	cached, ok := mc.series[key]
+	mc.mu.Unlock()
+
+	if ok && mc.fresh(cached.FetchedAt) {
+		return cached, nil
+	}
+
+	series, err := mc.provider.Commodity(ctx, symbol, interval)
+	if err == nil {
+		mc.mu.Lock()
+		mc.series[key] = series
+		mc.mu.Unlock()
+
+		if err := mc.history.SaveSeries(ctx, series); err != nil {
+			slog.ErrorContext(ctx, "error when saving the price history", "symbol", symbol, "interval", interval, "error", err)
+		}
+
+		return series, nil
+	}
+
+	slog.WarnContext(ctx, "error when fetching the prices", "symbol", symbol, "interval", interval, "error", err)
+
+	if ok {
+		cached.Stale = true
+		return cached, nil
+	}
+
+	stored, historyErr := mc.history.GetSeries(ctx, symbol, interval)
+	if historyErr != nil || len(stored.Points) == 0 {
+		return models.PriceSeries{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	stored.Stale = true
+
+	return stored, nil
+}
+
+func (mc *Cache) ExchangeRate(ctx context.Context, from, to string) (models.ExchangeRate, error) {
+	if from == to {
+		return models.ExchangeRate{From: from, To: to, Rate: 1, FetchedAt: mc.now().UTC()}, nil
+	}
+
+	key := from + "/" + to
+
+	mc.mu.Lock()
+	cached, ok := mc.rates[key]
+	mc.mu.Unlock()
+
+	if ok && mc.fresh(cached.FetchedAt) {
+		return cached, nil
+	}
+
+	rate, err := mc.provider.ExchangeRate(ctx, from, to)
+	if err == nil {
+		mc.mu.Lock()
+		mc.rates[key] = rate
+		mc.mu.Unlock()
+
+		if err := mc.history.SaveRate(ctx, rate); err != nil {
+			slog.ErrorContext(ctx, "error when saving the exchange rate", "from", from, "to", to, "error", err)
+		}
+
+		return rate, nil
+	}
+
+	slog.WarnContext(ctx, "error when fetching the exchange rate", "from", from, "to", to, "error", err)
+
+	if ok {
+		cached.Stale = true
+		return cached, nil
+	}
+
+	stored, historyErr := mc.history.GetRate(ctx, from, to)
+	if historyErr != nil {
+		return models.ExchangeRate{}, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	stored.Stale = true
+
+	return stored, nil
+}
+
+func (mc *Cache) fresh(fetchedAt time.Time) bool {
+	return mc.now().Sub(fetchedAt) < mc.ttl
+}
@@ -0,0 +1,225 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"keuangan-pribadi/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryHistory keeps the history in maps instead of the database.
+type memoryHistory struct {
+	mu     sync.Mutex
+	series map[string]models.PriceSeries
+	rates  map[string]models.ExchangeRate
+	err    error
+}
+
+func newMemoryHistory() *memoryHistory {
+	return &memoryHistory{series: map[string]models.PriceSeries{}, rates: map[string]models.ExchangeRate{}}
+}
+
+func (mh *memoryHistory) SaveSeries(ctx context.Context, series models.PriceSeries) error {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	if mh.err != nil {
+		return mh.err
+	}
+
+	mh.series[series.Symbol+"/"+series.Interval] = series
+
+	return nil
+}
+
+func (mh *memoryHistory) GetSeries(ctx context.Context, symbol, interval string) (models.PriceSeries, error) {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	return mh.series[symbol+"/"+interval], nil
+}
+
+func (mh *memoryHistory) SaveRate(ctx context.Context, rate models.ExchangeRate) error {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	if mh.err != nil {
+		return mh.err
+	}
+
+	mh.rates[rate.From+"/"+rate.To] = rate
+
+	return nil
+}
+
+func (mh *memoryHistory) GetRate(ctx context.Context, from, to string) (models.ExchangeRate, error) {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+
+	rate, ok := mh.rates[from+"/"+to]
+	if !ok {
+		return models.ExchangeRate{}, errors.New("no rate stored")
+	}
+
+	return rate, nil
+}
+
+// newTestCache is a cache of a fake provider whose clock is moved by the
+// returned function.
+func newTestCache(history History) (*Cache, *Fake, func(time.Duration)) {
+	fake := NewFake()
+	cache := NewCache(fake, history, time.Hour)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	return cache, fake, func(d time.Duration) { now = now.Add(d) }
+}
+
+var errUnreachable = errors.New("network is unreachable")
+
+func TestCommodity_Cached(t *testing.T) {
+	cache, fake, advance := newTestCache(newMemoryHistory())
+	ctx := context.Background()
+
+	series, err := cache.Commodity(ctx, "COFFEE", IntervalMonthly)
+	if assert.NoError(t, err) {
+		assert.Len(t, series.Points, 24)
+		assert.False(t, series.Stale)
+	}
+
+	// answered from the cache until the TTL passes
+	advance(59 * time.Minute)
+
+	_, err = cache.Commodity(ctx, "COFFEE", IntervalMonthly)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fake.Calls())
+
+	// another interval is another entry
+	_, err = cache.Commodity(ctx, "COFFEE", IntervalAnnual)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fake.Calls())
+
+	advance(2 * time.Minute)
+
+	_, err = cache.Commodity(ctx, "COFFEE", IntervalMonthly)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fake.Calls())
+}
+
+func TestCommodity_Stale(t *testing.T) {
+	history := newMemoryHistory()
+	cache, fake, advance := newTestCache(history)
+	ctx := context.Background()
+
+	fresh, err := cache.Commodity(ctx, "WHEAT", IntervalMonthly)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	advance(2 * time.Hour)
+	fake.SetErr(errUnreachable)
+
+	// the expired answer is given again, marked as stale
+	series, err := cache.Commodity(ctx, "WHEAT", IntervalMonthly)
+	if assert.NoError(t, err) {
+		assert.True(t, series.Stale)
+		assert.Equal(t, fresh.Points, series.Points)
+	}
+
+	// after a restart only the history is left
+	restarted, restartedFake, _ := newTestCache(history)
+	restartedFake.SetErr(errUnreachable)
+
+	series, err = restarted.Commodity(ctx, "WHEAT", IntervalMonthly)
+	if assert.NoError(t, err) {
+		assert.True(t, series.Stale)
+		assert.Equal(t, fresh.Points, series.Points)
+	}
+
+	// and without a history there is nothing to answer
+	_, err = restarted.Commodity(ctx, "CORN", IntervalMonthly)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestCommodity_HistoryFailed(t *testing.T) {
+	history := newMemoryHistory()
+	history.err = errors.New("database is locked")
+
+	cache, _, _ := newTestCache(history)
+
+	// the prices are answered even when they cannot be kept
+	series, err := cache.Commodity(context.Background(), "SUGAR", IntervalQuarterly)
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, series.Points)
+	}
+}
+
+func TestExchangeRate_Cached(t *testing.T) {
+	cache, fake, advance := newTestCache(newMemoryHistory())
+	ctx := context.Background()
+
+	// a currency converts to itself without asking the provider
+	rate, err := cache.ExchangeRate(ctx, "IDR", "IDR")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1.0, rate.Rate)
+	}
+	assert.Equal(t, 0, fake.Calls())
+
+	rate, err = cache.ExchangeRate(ctx, "USD", "IDR")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 16000.0, rate.Rate)
+	}
+
+	fake.SetRate("USD", "IDR", 16500)
+
+	rate, err = cache.ExchangeRate(ctx, "USD", "IDR")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 16000.0, rate.Rate)
+	}
+	assert.Equal(t, 1, fake.Calls())
+
+	advance(61 * time.Minute)
+
+	rate, err = cache.ExchangeRate(ctx, "USD", "IDR")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 16500.0, rate.Rate)
+		assert.False(t, rate.Stale)
+	}
+	assert.Equal(t, 2, fake.Calls())
+}
+
+func TestExchangeRate_Stale(t *testing.T) {
+	history := newMemoryHistory()
+	cache, fake, advance := newTestCache(history)
+	ctx := context.Background()
+
+	if _, err := cache.ExchangeRate(ctx, "USD", "EUR"); err != nil {
+		t.Fatal(err)
+	}
+
+	advance(2 * time.Hour)
+	fake.SetErr(errUnreachable)
+
+	rate, err := cache.ExchangeRate(ctx, "USD", "EUR")
+	if assert.NoError(t, err) {
+		assert.True(t, rate.Stale)
+		assert.Equal(t, 0.9, rate.Rate)
+	}
+
+	restarted, restartedFake, _ := newTestCache(history)
+	restartedFake.SetErr(errUnreachable)
+
+	rate, err = restarted.ExchangeRate(ctx, "USD", "EUR")
+	if assert.NoError(t, err) {
+		assert.True(t, rate.Stale)
+		assert.Equal(t, 0.9, rate.Rate)
+	}
+
+	_, err = restarted.ExchangeRate(ctx, "USD", "GBP")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
@@ -0,0 +1,151 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"keuangan-pribadi/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake answers fixed prices without calling anything, for the tests and for
+// running the application offline. Setting Err makes every call fail like an
+// unreachable provider.
+type Fake struct {
+	mu     sync.Mutex
+	series map[string]models.PriceSeries
+	rates  map[string]float64
+	err    error
+	calls  int
+}
+
+// NewFake has 24 prices of every commodity of Commodities at each of its
+// intervals, rates from US dollars to a few currencies and the price of a
+// troy ounce of gold, XAU, in dollars and rupiah.
+func NewFake() *Fake {
+	fake := &Fake{
+		series: map[string]models.PriceSeries{},
+		rates: map[string]float64{
+			"USD/IDR": 16000,
+			"USD/EUR": 0.9,
+			"USD/GBP": 0.8,
+			"USD/SGD": 1.35,
+			"XAU/USD": 2400,
+			"XAU/IDR": 38400000,
+		},
+	}
+
+	start := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := map[string]func(t time.Time, n int) time.Time{
+		IntervalDaily:     func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) },
+		IntervalWeekly:    func(t time.Time, n int) time.Time { return t.AddDate(0, 0, 7*n) },
+		IntervalMonthly:   func(t time.Time, n int) time.Time { return t.AddDate(0, n, 0) },
+		IntervalQuarterly: func(t time.Time, n int) time.Time { return t.AddDate(0, 3*n, 0) },
+		IntervalAnnual:    func(t time.Time, n int) time.Time { return t.AddDate(n, 0, 0) },
+	}
+
+	// every commodity has its own prices, 1 dollar for the first in
+	// alphabetical order, rising by 10 cents at each step
+	var symbols []string
+	for symbol := range Commodities {
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Strings(symbols)
+
+	for i, symbol := range symbols {
+		for _, interval := range Commodities[symbol] {
+			series := models.PriceSeries{
+				Symbol:   symbol,
+				Name:     fmt.Sprintf("Fake price of %s", symbol),
+				Interval: interval,
+				Unit:     "metric ton",
+				Currency: "USD",
+			}
+
+			for n := 0; n < 24; n++ {
+				series.Points = append(series.Points, models.PricePoint{
+					Date:  steps[interval](start, n),
+					Value: float64(i+1) + float64(n)/10,
+				})
+			}
+
+			fake.series[symbol+"/"+interval] = series
+		}
+	}
+
+	return fake
+}
+
+// SetSeries replaces the prices of a commodity at the interval of series.
+func (f *Fake) SetSeries(series models.PriceSeries) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.series[series.Symbol+"/"+series.Interval] = series
+}
+
+// SetRate replaces the rate between two currencies.
+func (f *Fake) SetRate(from, to string, rate float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rates[from+"/"+to] = rate
+}
+
+// SetErr makes the next calls fail with err, nil makes them succeed again.
+func (f *Fake) SetErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+}
+
+// Calls is the number of calls made to the provider.
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+func (f *Fake) Commodity(ctx context.Context, symbol, interval string) (models.PriceSeries, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	if f.err != nil {
+		return models.PriceSeries{}, f.err
+	}
+
+	series, ok := f.series[symbol+"/"+interval]
+	if !ok {
+		return models.PriceSeries{}, fmt.Errorf("fake has no %s prices of %s", interval, symbol)
+	}
+
+	series.Points = append([]models.PricePoint(nil), series.Points...)
+	series.FetchedAt = time.Now().UTC()
+
+	return series, nil
+}
+
+func (f *Fake) ExchangeRate(ctx context.Context, from, to string) (models.ExchangeRate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	if f.err != nil {
+		return models.ExchangeRate{}, f.err
+	}
+
+	rate, ok := f.rates[from+"/"+to]
+	if !ok {
+		return models.ExchangeRate{}, fmt.Errorf("fake has no exchange rate from %s to %s", from, to)
+	}
+
+	return models.ExchangeRate{From: from, To: to, Rate: rate, FetchedAt: time.Now().UTC()}, nil
+}
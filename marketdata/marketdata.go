@@ -0,0 +1,54 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"keuangan-pribadi/models"
+)
+
+// ErrUnavailable is returned when the prices cannot be fetched and none are
+// known from before.
+var ErrUnavailable = errors.New("market data is unavailable")
+
+// Intervals the prices of a commodity may be at.
+const (
+	IntervalDaily     = "daily"
+	IntervalWeekly    = "weekly"
+	IntervalMonthly   = "monthly"
+	IntervalQuarterly = "quarterly"
+	IntervalAnnual    = "annual"
+)
+
+// Commodities are the commodities with prices, the ones Alpha Vantage has,
+// by symbol with the intervals their prices are at.
+var Commodities = map[string][]string{
+	"WTI":         {IntervalDaily, IntervalWeekly, IntervalMonthly},
+	"BRENT":       {IntervalDaily, IntervalWeekly, IntervalMonthly},
+	"NATURAL_GAS": {IntervalDaily, IntervalWeekly, IntervalMonthly},
+	"COPPER":      {IntervalMonthly, IntervalQuarterly, IntervalAnnual},
+	"ALUMINUM":    {IntervalMonthly, IntervalQuarterly, IntervalAnnual},
+	"WHEAT":       {IntervalMonthly, IntervalQuarterly, IntervalAnnual},
+	"CORN":        {IntervalMonthly, IntervalQuarterly, IntervalAnnual},
+	"COTTON":      {IntervalMonthly, IntervalQuarterly, IntervalAnnual},
+	"SUGAR":       {IntervalMonthly, IntervalQuarterly, IntervalAnnual},
+	"COFFEE":      {IntervalMonthly, IntervalQuarterly, IntervalAnnual},
+}
+
+// MarketDataProvider fetches commodity prices and exchange rates from a
+// market-data service.
+type MarketDataProvider interface {
+	// Commodity is the price history of a commodity like COFFEE at an
+	// interval like monthly.
+	Commodity(ctx context.Context, symbol, interval string) (models.PriceSeries, error)
+	// ExchangeRate is the current rate between two currencies.
+	ExchangeRate(ctx context.Context, from, to string) (models.ExchangeRate, error)
+}
+
+// History keeps the fetched prices, so they can be answered when the
+// provider is down and nothing is cached, like after a restart.
+type History interface {
+	SaveSeries(ctx context.Context, series models.PriceSeries) error
+	GetSeries(ctx context.Context, symbol, interval string) (models.PriceSeries, error)
+	SaveRate(ctx context.Context, rate models.ExchangeRate) error
+	GetRate(ctx context.Context, from, to string) (models.ExchangeRate, error)
+}
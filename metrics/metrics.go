@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"database/sql"
+	"keuangan-pribadi/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "keuangan"
+
+// registry holds the metrics of the application. It is not the default
+// registry of the client library so nothing registered by a dependency ends
+// up in /metrics by accident.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	businessEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "business_events_total",
+		Help:      "Domain events like finance.created and saving.goal_reached.",
+	}, []string{"event"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		businessEvents,
+	)
+
+	// every event is exported from the start, so a rate over an event that
+	// has not happened yet is 0 instead of missing
+	for _, event := range models.Events {
+		businessEvents.WithLabelValues(event)
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exports the statistics of the connection pool, like the open,
+// in use and idle connections and the time spent waiting for one.
+func RegisterDB(db *sql.DB, name string) error {
+	return registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveRequest records a handled request. Route is the route pattern, like
+// /api/v1/finances/:id, so every finance does not get its own series.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	labels := []string{method, route, strconv.Itoa(status)}
+
+	httpRequests.WithLabelValues(labels...).Inc()
+	httpDuration.WithLabelValues(labels...).Observe(duration.Seconds())
+}
+
+// RecordEvent counts a domain event that has been committed.
+func RecordEvent(event string) {
+	businessEvents.WithLabelValues(event).Inc()
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"keuangan-pribadi/models"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ErrMissingToken is returned for requests without an Authorization header.
+var ErrMissingToken = models.UnauthorizedError("missing_token", "Missing token in request header")
+
+// Authenticate accepts either a session JWT or a personal access token in
+// the Authorization header. The user is resolved once and stored as the
+// principal of the request, handlers read it with GetPrincipal.
+func Authenticate(db *gorm.DB) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				return ErrMissingToken
+			}
+
+			// the lookups are cancelled with the request
+			db := db.WithContext(c.Request().Context())
+
+			var principal models.Principal
+
+			if strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+				pat, err := VerifyPersonalAccessToken(db, token)
+				if err != nil {
+					return err
+				}
+
+				var user models.User
+				if err := db.First(&user, "id = ?", pat.UserID).Error; err != nil {
+					return tokenLookupError(err)
+				}
+
+				principal = models.Principal{UserID: user.ID, Role: user.Role, PersonalAccessToken: &pat}
+			} else {
+				user, err := VerifyToken(db, token)
+				if err != nil {
+					return err
+				}
+
+				principal = models.Principal{UserID: user.ID, Role: user.Role}
+			}
+
+			c.SetRequest(c.Request().WithContext(models.WithPrincipal(c.Request().Context(), principal)))
+
+			return next(c)
+		}
+	}
+}
+
+// GetPrincipal is the principal stored by Authenticate, false when the
+// request was not authenticated.
+func GetPrincipal(c echo.Context) (models.Principal, bool) {
+	return models.PrincipalFromContext(c.Request().Context())
+}
+
+// RequireScope checks that a request authenticated with a personal access
+// token has the read (GET, HEAD) or write (everything else) scope for the
+// given resource. Session tokens are not restricted.
+func RequireScope(resource string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := GetPrincipal(c)
+			if !ok || principal.PersonalAccessToken == nil {
+				return next(c)
+			}
+
+			scope := resource + ":write"
+			if method := c.Request().Method; method == http.MethodGet || method == http.MethodHead {
+				scope = resource + ":read"
+			}
+
+			if !principal.PersonalAccessToken.HasScope(scope) {
+				return models.ForbiddenError("missing_scope", "token is missing the "+scope+" scope")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -4,42 +4,134 @@ import (
 	"errors"
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/utils"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
-func CreateToken(userId uint, name string) (string, error) {
+// JWTConfig signs and verifies the access tokens issued on login.
+type JWTConfig struct {
+	SecretKey string
+	TTL       time.Duration
+}
+
+var jwtConfig JWTConfig
+
+// ConfigureJWT sets the secret and lifetime of the access tokens, it is
+// called once at startup before any token is issued.
+func ConfigureJWT(config JWTConfig) {
+	jwtConfig = config
+}
+
+func CreateToken(userId uint, name, role string) (string, error) {
+	return CreateTokenAt(userId, name, role, time.Now())
+}
+
+// CreateTokenAt issues a token as if it was issued at issuedAt. The issue
+// time keeps its milliseconds so a revocation can tell apart the tokens of
+// the same second.
+func CreateTokenAt(userId uint, name, role string, issuedAt time.Time) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userId,
 		"name": name,
-		"exp": time.Now().Add(time.Hour * 1).Unix(),
+		"role": role,
+		"iat": float64(issuedAt.UnixMilli()) / 1000,
+		"exp": issuedAt.Add(jwtConfig.TTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(utils.GetConfig("JWT_SECRET_KEY")))
+	return token.SignedString([]byte(jwtConfig.SecretKey))
 }
 
-func VerifyToken(tokenString string) (models.User, error) {
+var (
+	errInvalidToken = models.UnauthorizedError("invalid_token", "invalid or expired token")
+	errRevokedToken = models.UnauthorizedError("revoked_token", "token has been revoked")
+)
+
+// VerifyToken resolves the user of a session JWT or a personal access token.
+// A token that is not valid gives an unauthorized error, a failing database
+// is returned as it is.
+func VerifyToken(db *gorm.DB, tokenString string) (models.User, error) {
     if strings.HasPrefix(tokenString, "Bearer ") {
         tokenString = strings.TrimPrefix(tokenString, "Bearer ")
     }
     var user models.User
 
+    if strings.HasPrefix(tokenString, models.PersonalAccessTokenPrefix) {
+        pat, err := VerifyPersonalAccessToken(db, tokenString)
+        if err != nil {
+            return user, err
+        }
+
+        if err := db.First(&user, "id = ?", pat.UserID).Error; err != nil {
+            return models.User{}, tokenLookupError(err)
+        }
+
+        return user, nil
+    }
+
+    // only the algorithm the tokens are signed with is accepted
     token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-        return []byte(utils.GetConfig("JWT_SECRET_KEY")), nil
-    })
+        return []byte(jwtConfig.SecretKey), nil
+    }, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
     if err != nil {
-        return user, err
+        return user, errInvalidToken.Wrap(err)
+    }
+
+    claims, ok := token.Claims.(jwt.MapClaims)
+    if !ok || !token.Valid {
+        return user, errInvalidToken
+    }
+
+    userID, ok := claims["user_id"].(float64)
+    if !ok {
+        return user, errInvalidToken
+    }
+
+    if err := db.First(&user, "id = ?", uint(userID)).Error; err != nil {
+        return models.User{}, tokenLookupError(err)
     }
 
-    if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-        user.ID = uint(claims["user_id"].(float64))
-    } else {
-        return user, errors.New("Invalid token")
+    // tokens issued up to the moment the user revoked their sessions are no
+    // longer valid, tokens without an issue time neither
+    if user.TokensRevokedAt != nil {
+        issuedAt, _ := claims["iat"].(float64)
+        if int64(math.Round(issuedAt*1000)) <= user.TokensRevokedAt.UnixMilli() {
+            return models.User{}, errRevokedToken
+        }
     }
 
     return user, nil
-}
\ No newline at end of file
+}
+
+// VerifyPersonalAccessToken looks up a personal access token by its hash and
+// checks that it has not expired or been revoked.
+func VerifyPersonalAccessToken(db *gorm.DB, tokenString string) (models.PersonalAccessToken, error) {
+    var pat models.PersonalAccessToken
+
+    if err := db.First(&pat, "token_hash = ?", utils.HashToken(tokenString)).Error; err != nil {
+        return models.PersonalAccessToken{}, tokenLookupError(err)
+    }
+
+    if pat.ExpiresAt != nil && pat.ExpiresAt.Before(time.Now()) {
+        return models.PersonalAccessToken{}, errInvalidToken
+    }
+
+    db.Model(&pat).UpdateColumn("last_used_at", time.Now())
+
+    return pat, nil
+}
+
+// tokenLookupError is the error of a token whose row is gone, like a deleted
+// user or a revoked personal access token.
+func tokenLookupError(err error) error {
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return errInvalidToken
+    }
+
+    return err
+}
@@ -1,16 +1,49 @@
 package middleware
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 )
 
-type LoggerConfig struct {
-	Format string
-}
+// AccessLog logs every request once it is answered. It must run after
+// RequestID so the line carries the request ID, the user ID is added once
+// Authenticate has run further down the chain.
+func AccessLog() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+			if err != nil {
+				// the status is only known once the error is written
+				c.Error(err)
+			}
+
+			request := c.Request()
+			response := c.Response()
 
-func (c *LoggerConfig) Init() echo.MiddlewareFunc {
-	return middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: c.Format,
-	})
-}
\ No newline at end of file
+			level := slog.LevelInfo
+			switch {
+			case response.Status >= 500:
+				level = slog.LevelError
+			case response.Status >= 400:
+				level = slog.LevelWarn
+			}
+
+			slog.Default().Log(request.Context(), level, "request",
+				"method", request.Method,
+				"route", c.Path(),
+				"uri", request.RequestURI,
+				"status", response.Status,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"bytes_out", response.Size,
+				"ip", c.RealIP(),
+				"user_agent", request.UserAgent(),
+			)
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+)
+
+type LoginThrottleConfig struct {
+	// MaxAccountAttempts is the number of failed logins for one account
+	// before it gets locked.
+	MaxAccountAttempts int
+	// MaxIPAttempts is the number of failed logins from one IP address
+	// before it gets locked, across all accounts.
+	MaxIPAttempts int
+	// BaseLockout is the first lockout, it doubles with every further
+	// failure up to MaxLockout.
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	// Window is how long failed attempts are remembered.
+	Window time.Duration
+}
+
+var DefaultLoginThrottleConfig = LoginThrottleConfig{
+	MaxAccountAttempts: 5,
+	MaxIPAttempts:      20,
+	BaseLockout:        time.Minute,
+	MaxLockout:         time.Hour,
+	Window:             time.Hour * 24,
+}
+
+// LoginThrottle tracks failed logins per account and per IP address and
+// locks them out progressively.
+type LoginThrottle struct {
+	store  RateLimitStore
+	config LoginThrottleConfig
+}
+
+func NewLoginThrottle(store RateLimitStore, config LoginThrottleConfig) *LoginThrottle {
+	return &LoginThrottle{
+		store:  store,
+		config: config,
+	}
+}
+
+// Check returns how long the account or IP address is still locked out, or
+// zero when a login attempt is allowed.
+func (lt *LoginThrottle) Check(email, ip string) time.Duration {
+	accountWait := lt.lockout(lt.store.Get(accountKey(email)), lt.config.MaxAccountAttempts)
+	ipWait := lt.lockout(lt.store.Get(ipKey(ip)), lt.config.MaxIPAttempts)
+
+	if ipWait > accountWait {
+		return ipWait
+	}
+
+	return accountWait
+}
+
+// Fail records a failed login attempt.
+func (lt *LoginThrottle) Fail(email, ip string) {
+	lt.store.Increment(accountKey(email), lt.config.Window)
+	lt.store.Increment(ipKey(ip), lt.config.Window)
+}
+
+// Succeed clears the failed attempts of the account. The IP counter is kept
+// so logging into another account does not unlock the address.
+func (lt *LoginThrottle) Succeed(email string) {
+	lt.store.Delete(accountKey(email))
+}
+
+func (lt *LoginThrottle) lockout(entry RateLimitEntry, maxAttempts int) time.Duration {
+	if entry.Count < maxAttempts {
+		return 0
+	}
+
+	duration := lt.config.BaseLockout
+	for i := maxAttempts; i < entry.Count && duration < lt.config.MaxLockout; i++ {
+		duration *= 2
+	}
+
+	if duration > lt.config.MaxLockout {
+		duration = lt.config.MaxLockout
+	}
+
+	if wait := time.Until(entry.LastHit.Add(duration)); wait > 0 {
+		return wait
+	}
+
+	return 0
+}
+
+func accountKey(email string) string {
+	return "login:account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "login:ip:" + ip
+}
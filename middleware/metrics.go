@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"keuangan-pribadi/metrics"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics records the count and the latency of the requests per route and
+// status.
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+			if err != nil {
+				// the status is only known once the error is written
+				c.Error(err)
+			}
+
+			metrics.ObserveRequest(c.Request().Method, c.Path(), c.Response().Status, time.Since(start))
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"keuangan-pribadi/models"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimitEntry is the hit counter of a single key inside its window.
+type RateLimitEntry struct {
+	Count   int
+	LastHit time.Time
+	ResetAt time.Time
+}
+
+// RateLimitStore keeps hit counters for rate limiting and login throttling.
+// Implementations must be safe for concurrent use.
+type RateLimitStore interface {
+	// Increment adds a hit to key, starting a new window when the previous
+	// one has expired, and returns the updated entry.
+	Increment(key string, window time.Duration) RateLimitEntry
+	// Get returns the current entry of key, or a zero entry when there is none.
+	Get(key string) RateLimitEntry
+	// Delete removes key from the store.
+	Delete(key string)
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]RateLimitEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   map[string]RateLimitEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (ms *MemoryStore) Increment(key string, window time.Duration) RateLimitEntry {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	ms.sweep(now)
+
+	entry, ok := ms.entries[key]
+	if !ok || !now.Before(entry.ResetAt) {
+		entry = RateLimitEntry{ResetAt: now.Add(window)}
+	}
+
+	entry.Count++
+	entry.LastHit = now
+	ms.entries[key] = entry
+
+	return entry
+}
+
+func (ms *MemoryStore) Get(key string) RateLimitEntry {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, ok := ms.entries[key]
+	if !ok || !time.Now().Before(entry.ResetAt) {
+		return RateLimitEntry{}
+	}
+
+	return entry
+}
+
+func (ms *MemoryStore) Delete(key string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.entries, key)
+}
+
+// sweep drops expired entries at most once a minute so the map does not grow
+// forever. The caller must hold the lock.
+func (ms *MemoryStore) sweep(now time.Time) {
+	if now.Sub(ms.lastSweep) < time.Minute {
+		return
+	}
+
+	for key, entry := range ms.entries {
+		if !now.Before(entry.ResetAt) {
+			delete(ms.entries, key)
+		}
+	}
+
+	ms.lastSweep = now
+}
+
+type RateLimitConfig struct {
+	Store RateLimitStore
+	// Prefix separates the counters of different route groups sharing a store.
+	Prefix string
+	Limit  int
+	Window time.Duration
+	// KeyFunc identifies the client, defaults to KeyByIP.
+	KeyFunc func(c echo.Context) string
+}
+
+// KeyByIP rate limits per client IP address.
+func KeyByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// KeyByUser rate limits per authenticated user and falls back to the client
+// IP address when the request is not authenticated. It must run after
+// Authenticate.
+func KeyByUser(c echo.Context) string {
+	if principal, ok := GetPrincipal(c); ok {
+		return "user:" + strconv.FormatUint(uint64(principal.UserID), 10)
+	}
+
+	return KeyByIP(c)
+}
+
+// RateLimit allows at most config.Limit requests per config.Window for each
+// key and sets the X-RateLimit-* headers on every response.
+func RateLimit(config RateLimitConfig) echo.MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByIP
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			entry := config.Store.Increment(config.Prefix+":"+config.KeyFunc(c), config.Window)
+
+			remaining := config.Limit - entry.Count
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			header := c.Response().Header()
+			header.Set("X-RateLimit-Limit", strconv.Itoa(config.Limit))
+			header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			header.Set("X-RateLimit-Reset", strconv.FormatInt(entry.ResetAt.Unix(), 10))
+
+			if entry.Count > config.Limit {
+				header.Set("Retry-After", retryAfterSeconds(time.Until(entry.ResetAt)))
+
+				return c.JSON(http.StatusTooManyRequests, models.Response[string]{
+					Status:  "failed",
+					Message: "too many requests, try again later",
+					Code:    "too_many_requests",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// retryAfterSeconds formats a duration for the Retry-After header, rounding
+// up so clients never retry too early.
+func retryAfterSeconds(d time.Duration) string {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return strconv.FormatInt(seconds, 10)
+}
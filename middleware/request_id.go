@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"keuangan-pribadi/logging"
+	"keuangan-pribadi/utils"
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+)
+
+// validRequestID limits the IDs taken from clients, so a header cannot
+// inject anything into the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID takes the X-Request-ID of the request, a proxy in front may have
+// set it already, or generates one. The ID is sent back in the response and
+// stored in the request context for the logs.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+
+			if !validRequestID.MatchString(id) {
+				generated, err := utils.RandomToken(16)
+				if err != nil {
+					return err
+				}
+
+				id = generated
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(c.Request().WithContext(logging.WithRequestID(c.Request().Context(), id)))
+
+			return next(c)
+		}
+	}
+}
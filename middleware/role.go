@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"keuangan-pribadi/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireRole only lets the request through when the authenticated user has
+// one of the given roles. It must run after Authenticate.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := GetPrincipal(c)
+			if !ok {
+				return ErrMissingToken
+			}
+
+			for _, role := range roles {
+				if principal.Role == role {
+					return next(c)
+				}
+			}
+
+			return models.ForbiddenError("role_required", "you are not allowed to access this resource")
+		}
+	}
+}
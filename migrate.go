@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"keuangan-pribadi/migrations"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+// runMigrate runs the migrate command: up applies every pending migration,
+// down [steps] rolls back the latest one or the given number and status
+// lists them all.
+func runMigrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down [steps]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(db)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("applied %d migration(s), schema is at version %d\n", len(applied), migrations.Latest())
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+
+			steps = n
+		}
+
+		rolledBack, err := migrations.Down(db, steps)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("rolled back %d migration(s)\n", len(rolledBack))
+	case "status":
+		statuses, err := migrations.Status(db)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		w.Flush()
+
+		// a newer schema is listed and reported
+		return err
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+
+	return nil
+}
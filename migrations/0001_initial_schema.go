@@ -0,0 +1,241 @@
+package migrations
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// initialSchemaUp creates the schema as AutoMigrate left it before versioned
+// migrations existed. Databases created back then are brought up to date
+// instead of failing on the existing tables.
+//
+// The tables are described by local copies of the models, so later changes
+// of the models do not change what this migration does.
+func initialSchemaUp(tx *gorm.DB) error {
+	type user struct {
+		ID                         uint `gorm:"primaryKey"`
+		Name                       string
+		Email                      string
+		Password                   string
+		Role                       string `gorm:"type:varchar(10);default:user"`
+		Exp                        int    `gorm:"null"`
+		PendingEmail               string
+		EmailVerificationToken     string `gorm:"index"`
+		EmailVerificationExpiresAt *time.Time
+		TokensRevokedAt            *time.Time
+		CreatedAt                  time.Time
+		UpdatedAt                  time.Time
+		DeletedAt                  gorm.DeletedAt `gorm:"index"`
+	}
+
+	type category struct {
+		ID        uint `gorm:"primaryKey"`
+		Name      string
+		LedgerID  *uint `gorm:"index"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+
+	type finance struct {
+		ID         uint `gorm:"primaryKey"`
+		Name       string
+		Type       int `gorm:"check:type IN(1,2)"`
+		Money      int
+		UserID     uint
+		CategoryID uint
+		LedgerID   *uint    `gorm:"index"`
+		User       user     `gorm:"foreignKey:UserID"`
+		Category   category `gorm:"foreignKey:CategoryID"`
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+		DeletedAt  gorm.DeletedAt `gorm:"index"`
+	}
+
+	type saving struct {
+		ID        uint `gorm:"primaryKey"`
+		Name      string
+		Value     int
+		Goal      int
+		UserID    uint
+		User      user  `gorm:"foreignKey:UserID"`
+		LedgerID  *uint `gorm:"index"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+
+	type detailSaving struct {
+		ID        uint `gorm:"primaryKey"`
+		Value     int
+		Status    int8 `gorm:"check:status IN(1,2)"`
+		SavingID  uint
+		Saving    saving `gorm:"foreignKey:SavingID"`
+		UserID    uint
+		User      user `gorm:"foreignKey:UserID"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+
+	type personalAccessToken struct {
+		ID         uint `gorm:"primaryKey"`
+		Name       string
+		TokenHash  string `gorm:"uniqueIndex;size:64"`
+		Prefix     string
+		Scopes     []string `gorm:"serializer:json"`
+		ExpiresAt  *time.Time
+		LastUsedAt *time.Time
+		UserID     uint `gorm:"index"`
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+		DeletedAt  gorm.DeletedAt `gorm:"index"`
+	}
+
+	type auditLog struct {
+		ID         uint                   `gorm:"primaryKey"`
+		ActorID    uint                   `gorm:"index"`
+		OwnerID    uint                   `gorm:"index"`
+		Action     string                 `gorm:"size:10"`
+		EntityType string                 `gorm:"size:50;index:idx_audit_entity"`
+		EntityID   uint                   `gorm:"index:idx_audit_entity"`
+		Before     map[string]interface{} `gorm:"serializer:json;type:text"`
+		After      map[string]interface{} `gorm:"serializer:json;type:text"`
+		Changes    map[string]interface{} `gorm:"serializer:json;type:text"`
+		IP         string                 `gorm:"size:45"`
+		UserAgent  string
+		CreatedAt  time.Time
+	}
+
+	type ledgerMember struct {
+		ID        uint   `gorm:"primaryKey"`
+		LedgerID  uint   `gorm:"uniqueIndex:idx_ledger_member"`
+		UserID    uint   `gorm:"uniqueIndex:idx_ledger_member"`
+		User      user   `gorm:"foreignKey:UserID"`
+		Role      string `gorm:"size:10"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	type ledger struct {
+		ID        uint `gorm:"primaryKey"`
+		Name      string
+		Personal  bool
+		Members   []ledgerMember `gorm:"foreignKey:LedgerID"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+
+	type ledgerInvitation struct {
+		ID          uint `gorm:"primaryKey"`
+		LedgerID    uint `gorm:"index"`
+		Email       string
+		Role        string `gorm:"size:10"`
+		TokenHash   string `gorm:"uniqueIndex;size:64"`
+		InvitedByID uint
+		ExpiresAt   time.Time
+		AcceptedAt  *time.Time
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+	}
+
+	type debtRepayment struct {
+		ID        uint `gorm:"primaryKey"`
+		DebtID    uint `gorm:"index"`
+		Amount    int
+		FinanceID uint
+		Finance   finance `gorm:"foreignKey:FinanceID"`
+		UserID    uint
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	type debt struct {
+		ID           uint   `gorm:"primaryKey"`
+		Counterparty string `gorm:"index"`
+		Direction    string `gorm:"size:10"`
+		Principal    int
+		Paid         int
+		Note         string
+		DueDate      *time.Time
+		SettledAt    *time.Time
+		UserID       uint
+		LedgerID     *uint           `gorm:"index"`
+		User         user            `gorm:"foreignKey:UserID"`
+		Repayments   []debtRepayment `gorm:"foreignKey:DebtID"`
+		CreatedAt    time.Time
+		UpdatedAt    time.Time
+		DeletedAt    gorm.DeletedAt `gorm:"index"`
+	}
+
+	type bill struct {
+		ID          uint `gorm:"primaryKey"`
+		Name        string
+		Amount      int
+		DueDay      int
+		Recurrence  string `gorm:"size:10"`
+		LeadDays    int
+		NextDueDate time.Time `gorm:"index"`
+		RemindedFor *time.Time
+		Active      bool `gorm:"default:true"`
+		CategoryID  uint
+		Category    category `gorm:"foreignKey:CategoryID"`
+		UserID      uint
+		LedgerID    *uint `gorm:"index"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		DeletedAt   gorm.DeletedAt `gorm:"index"`
+	}
+
+	type notification struct {
+		ID         uint   `gorm:"primaryKey"`
+		UserID     uint   `gorm:"index"`
+		Type       string `gorm:"size:30"`
+		Title      string
+		Message    string
+		EntityType string `gorm:"size:30"`
+		EntityID   uint
+		ReadAt     *time.Time
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+	}
+
+	type webhook struct {
+		ID          uint `gorm:"primaryKey"`
+		URL         string
+		Description string
+		Secret      string
+		Events      []string `gorm:"serializer:json"`
+		Active      bool     `gorm:"default:true"`
+		UserID      uint     `gorm:"index"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		DeletedAt   gorm.DeletedAt `gorm:"index"`
+	}
+
+	type webhookDelivery struct {
+		ID             uint            `gorm:"primaryKey"`
+		WebhookID      uint            `gorm:"index"`
+		Webhook        webhook         `gorm:"foreignKey:WebhookID"`
+		Event          string          `gorm:"size:50"`
+		Payload        json.RawMessage `gorm:"type:text"`
+		Status         string          `gorm:"size:10;index"`
+		Attempts       int
+		NextAttemptAt  *time.Time `gorm:"index"`
+		LastAttemptAt  *time.Time
+		ResponseStatus int
+		Error          string
+		CreatedAt      time.Time
+		UpdatedAt      time.Time
+	}
+
+	return tx.AutoMigrate(&user{}, &category{}, &finance{}, &saving{}, &detailSaving{}, &personalAccessToken{}, &auditLog{}, &ledger{}, &ledgerMember{}, &ledgerInvitation{}, &debt{}, &debtRepayment{}, &bill{}, &notification{}, &webhook{}, &webhookDelivery{})
+}
+
+func initialSchemaDown(tx *gorm.DB) error {
+	// dependent tables first so no foreign key is left dangling
+	return tx.Migrator().DropTable("webhook_deliveries", "webhooks", "notifications", "bills", "debt_repayments", "debts", "ledger_invitations", "ledger_members", "ledgers", "audit_logs", "personal_access_tokens", "detail_savings", "savings", "finances", "categories", "users")
+}
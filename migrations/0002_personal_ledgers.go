@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// personalLedgersUp moves finances and savings created before ledgers
+// existed into the personal ledger of the user who created them.
+func personalLedgersUp(tx *gorm.DB) error {
+	var userIDs []uint
+
+	for _, table := range []string{"finances", "savings"} {
+		var ids []uint
+
+		if err := tx.Table(table).Distinct("user_id").Where("ledger_id IS NULL").Pluck("user_id", &ids).Error; err != nil {
+			return err
+		}
+
+		userIDs = append(userIDs, ids...)
+	}
+
+	for _, userID := range userIDs {
+		ledgerID, err := personalLedgerID(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"finances", "savings"} {
+			if err := tx.Table(table).Where("user_id = ? AND ledger_id IS NULL", userID).Update("ledger_id", ledgerID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// personalLedgersDown keeps the data where it is, the rows stay valid with
+// a ledger.
+func personalLedgersDown(tx *gorm.DB) error {
+	return nil
+}
+
+// personalLedgerID finds or creates the personal ledger of the user.
+func personalLedgerID(tx *gorm.DB, userID uint) (uint, error) {
+	var ids []uint
+
+	owned := tx.Table("ledger_members").Select("ledger_id").Where("user_id = ? AND role = ?", userID, "owner")
+
+	if err := tx.Table("ledgers").Where("personal = ? AND deleted_at IS NULL AND id IN (?)", true, owned).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+
+	type ledger struct {
+		ID        uint `gorm:"primaryKey"`
+		Name      string
+		Personal  bool
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	type ledgerMember struct {
+		ID        uint `gorm:"primaryKey"`
+		LedgerID  uint
+		UserID    uint
+		Role      string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	personal := ledger{Name: "Personal", Personal: true}
+
+	if err := tx.Create(&personal).Error; err != nil {
+		return 0, err
+	}
+
+	if err := tx.Create(&ledgerMember{LedgerID: personal.ID, UserID: userID, Role: "owner"}).Error; err != nil {
+		return 0, err
+	}
+
+	return personal.ID, nil
+}
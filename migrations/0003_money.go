@@ -0,0 +1,125 @@
+package migrations
+
+import (
+	"fmt"
+	"keuangan-pribadi/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// moneyColumns are the amounts that were whole integers of the configured
+// currency and are now stored in minor units together with their currency.
+var moneyColumns = []struct {
+	table  string
+	column string
+}{
+	{"finances", "money"},
+	{"savings", "value"},
+	{"savings", "goal"},
+	{"detail_savings", "value"},
+	{"debts", "principal"},
+	{"debts", "paid"},
+	{"debt_repayments", "amount"},
+	{"bills", "amount"},
+}
+
+// moneyUp replaces every amount column by a <column>_minor column holding
+// minor units and a <column>_currency column. The existing amounts were in
+// the configured currency.
+func moneyUp(tx *gorm.DB) error {
+	currency, scale, err := moneyCurrency()
+	if err != nil {
+		return err
+	}
+
+	for _, mc := range moneyColumns {
+		table := clause.Table{Name: mc.table}
+		minor := clause.Column{Name: mc.column + "_minor"}
+		code := clause.Column{Name: mc.column + "_currency"}
+		old := clause.Column{Name: mc.column}
+
+		statements := []struct {
+			sql  string
+			vars []interface{}
+		}{
+			{"ALTER TABLE ? ADD COLUMN ? BIGINT NOT NULL DEFAULT 0", []interface{}{table, minor}},
+			{"ALTER TABLE ? ADD COLUMN ? VARCHAR(3)", []interface{}{table, code}},
+			{"UPDATE ? SET ? = COALESCE(?, 0) * ?, ? = ?", []interface{}{table, minor, old, scale, code, currency}},
+			{"ALTER TABLE ? DROP COLUMN ?", []interface{}{table, old}},
+		}
+
+		for _, statement := range statements {
+			if err := tx.Exec(statement.sql, statement.vars...).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// moneyDown brings back the integer columns of the configured currency.
+// Fractions are lost, amounts in any other currency cannot be brought back
+// and stop the rollback.
+func moneyDown(tx *gorm.DB) error {
+	currency, scale, err := moneyCurrency()
+	if err != nil {
+		return err
+	}
+
+	for _, mc := range moneyColumns {
+		code := clause.Column{Name: mc.column + "_currency"}
+
+		var others int64
+
+		if err := tx.Table(mc.table).Where("? IS NOT NULL AND ? <> ?", code, code, currency).Count(&others).Error; err != nil {
+			return err
+		}
+
+		if others > 0 {
+			return fmt.Errorf("%d amount(s) in %s.%s are not in %s", others, mc.table, mc.column, currency)
+		}
+	}
+
+	for _, mc := range moneyColumns {
+		table := clause.Table{Name: mc.table}
+		minor := clause.Column{Name: mc.column + "_minor"}
+		code := clause.Column{Name: mc.column + "_currency"}
+		old := clause.Column{Name: mc.column}
+
+		statements := []struct {
+			sql  string
+			vars []interface{}
+		}{
+			{"ALTER TABLE ? ADD COLUMN ? BIGINT", []interface{}{table, old}},
+			{"UPDATE ? SET ? = ? / ?", []interface{}{table, old, minor, scale}},
+			{"ALTER TABLE ? DROP COLUMN ?", []interface{}{table, minor}},
+			{"ALTER TABLE ? DROP COLUMN ?", []interface{}{table, code}},
+		}
+
+		for _, statement := range statements {
+			if err := tx.Exec(statement.sql, statement.vars...).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// moneyCurrency is the configured currency and the number of its minor units
+// in a whole one.
+func moneyCurrency() (string, int64, error) {
+	currency, err := money.LookupCurrency(money.DefaultCurrency())
+	if err != nil {
+		return "", 0, err
+	}
+
+	scale := int64(1)
+	for i := 0; i < currency.Exponent; i++ {
+		scale *= 10
+	}
+
+	return currency.Code, scale, nil
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// uniqueUserEmailsUp makes every email belong to one account. Registering
+// twice at the same time could store an address twice; of those accounts
+// logging in only ever reached the oldest one not deleted, which keeps the
+// address. The others get an address nobody can receive mail at that still
+// shows which one it was.
+func uniqueUserEmailsUp(tx *gorm.DB) error {
+	type user struct {
+		Email string `gorm:"size:255;uniqueIndex"`
+	}
+
+	var emails []string
+
+	if err := tx.Table("users").Group("email").Having("COUNT(*) > 1").Pluck("email", &emails).Error; err != nil {
+		return err
+	}
+
+	for _, email := range emails {
+		var ids []uint
+
+		if err := tx.Table("users").Where("email = ?", email).Order("deleted_at IS NOT NULL, id").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		for _, id := range ids[1:] {
+			if err := tx.Table("users").Where("id = ?", id).Update("email", fmt.Sprintf("%s.duplicate-%d", email, id)).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	// MySQL cannot index the longtext the column was created as
+	if tx.Dialector.Name() == "mysql" {
+		if err := tx.Migrator().AlterColumn(&user{}, "Email"); err != nil {
+			return err
+		}
+	}
+
+	return tx.Migrator().CreateIndex(&user{}, "Email")
+}
+
+func uniqueUserEmailsDown(tx *gorm.DB) error {
+	type user struct {
+		Email string `gorm:"size:255;uniqueIndex"`
+	}
+
+	return tx.Migrator().DropIndex(&user{}, "Email")
+}
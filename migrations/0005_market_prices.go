@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// marketPricesUp adds the table keeping the fetched commodity prices and
+// exchange rates.
+func marketPricesUp(tx *gorm.DB) error {
+	type marketPrice struct {
+		ID        uint      `gorm:"primaryKey"`
+		Symbol    string    `gorm:"size:20;uniqueIndex:idx_market_prices_point"`
+		Interval  string    `gorm:"size:10;uniqueIndex:idx_market_prices_point"`
+		Date      time.Time `gorm:"uniqueIndex:idx_market_prices_point"`
+		Value     float64
+		Name      string
+		Unit      string
+		Currency  string `gorm:"size:3"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	return tx.AutoMigrate(&marketPrice{})
+}
+
+func marketPricesDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable("market_prices")
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// goldSavingsUp adds the savings kept in grams of gold and their deposits.
+func goldSavingsUp(tx *gorm.DB) error {
+	type goldSaving struct {
+		ID           uint `gorm:"primaryKey"`
+		Name         string
+		Goal         int64  `gorm:"not null;default:0"`
+		Grams        int64  `gorm:"not null;default:0"`
+		CostMinor    int64  `gorm:"not null;default:0"`
+		CostCurrency string `gorm:"size:3"`
+		UserID       uint   `gorm:"index"`
+		LedgerID     *uint  `gorm:"index"`
+		CreatedAt    time.Time
+		UpdatedAt    time.Time
+		DeletedAt    gorm.DeletedAt `gorm:"index"`
+	}
+
+	type goldDeposit struct {
+		ID            uint   `gorm:"primaryKey"`
+		GoldSavingID  uint   `gorm:"index"`
+		Grams         int64  `gorm:"not null"`
+		PriceMinor    int64  `gorm:"not null;default:0"`
+		PriceCurrency string `gorm:"size:3"`
+		PurchasedAt   time.Time
+		UserID        uint `gorm:"index"`
+		CreatedAt     time.Time
+		UpdatedAt     time.Time
+		DeletedAt     gorm.DeletedAt `gorm:"index"`
+	}
+
+	return tx.AutoMigrate(&goldSaving{}, &goldDeposit{})
+}
+
+func goldSavingsDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable("gold_deposits", "gold_savings")
+}
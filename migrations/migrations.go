@@ -0,0 +1,288 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a numbered change of the database schema or data. Up and
+// Down run in a transaction together with the bookkeeping of the version.
+// MySQL commits every schema change on its own, so there a failed migration
+// can leave part of its changes behind and has to be repaired by hand before
+// it is run again.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// MigrationStatus tells whether a migration was applied and when.
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row of the table tracking the applied versions.
+type schemaMigration struct {
+	Version   uint   `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:100"`
+	AppliedAt time.Time
+}
+
+var ErrSchemaTooNew = errors.New("the database schema is newer than this binary")
+
+var ErrMigrationLocked = errors.New("another instance is migrating the database")
+
+const (
+	// lockName is the MySQL named lock and lockKey the PostgreSQL advisory
+	// lock held while migrating
+	lockName = "keuangan_pribadi_migrations"
+	lockKey  = 4708307145
+	// lockTimeout is how long an instance waits for another one to finish
+	lockTimeout = 5 * time.Minute
+)
+
+// all migrations in the order they are applied, versions must be ascending
+var migrations = []Migration{
+	{Version: 1, Name: "initial_schema", Up: initialSchemaUp, Down: initialSchemaDown},
+	{Version: 2, Name: "personal_ledgers", Up: personalLedgersUp, Down: personalLedgersDown},
+	{Version: 3, Name: "money", Up: moneyUp, Down: moneyDown},
+	{Version: 4, Name: "unique_user_emails", Up: uniqueUserEmailsUp, Down: uniqueUserEmailsDown},
+	{Version: 5, Name: "market_prices", Up: marketPricesUp, Down: marketPricesDown},
+	{Version: 6, Name: "gold_savings", Up: goldSavingsUp, Down: goldSavingsDown},
+}
+
+// Latest is the version of the newest migration known to this binary.
+func Latest() uint {
+	return migrations[len(migrations)-1].Version
+}
+
+// Up applies every pending migration and returns the applied ones. It
+// refuses to touch a database migrated by a newer binary.
+func Up(db *gorm.DB) ([]Migration, error) {
+	var done []Migration
+
+	err := withLock(db, func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+			return err
+		}
+
+		applied, err := appliedVersions(db)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersions(applied); err != nil {
+			return err
+		}
+
+		defer withoutForeignKeys(db)()
+
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+
+				return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+
+			slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down rolls back the given number of most recently applied migrations and
+// returns the rolled back ones.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	var done []Migration
+
+	err := withLock(db, func(db *gorm.DB) error {
+		applied, err := appliedVersions(db)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersions(applied); err != nil {
+			return err
+		}
+
+		defer withoutForeignKeys(db)()
+
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := migrations[i]
+
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+
+				return tx.Delete(&schemaMigration{}, migration.Version).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+
+			slog.Info("rolled back migration", "version", migration.Version, "name", migration.Name)
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Status lists every migration known to this binary.
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, checkVersions(applied)
+}
+
+// Pending is the number of migrations not applied yet.
+func Pending(db *gorm.DB) (int, error) {
+	statuses, err := Status(db)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+// appliedVersions only reads, it runs on every readiness probe. Without the
+// table nothing was applied yet, Up creates it.
+func appliedVersions(db *gorm.DB) (map[uint]schemaMigration, error) {
+	applied := map[uint]schemaMigration{}
+
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return applied, nil
+	}
+
+	var rows []schemaMigration
+
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+func checkVersions(applied map[uint]schemaMigration) error {
+	for version := range applied {
+		if version > Latest() {
+			return fmt.Errorf("%w: version %d is applied, this binary knows up to %d", ErrSchemaTooNew, version, Latest())
+		}
+	}
+
+	return nil
+}
+
+// withLock runs fn while holding a lock, so instances starting at the same
+// time migrate one after another. The lock belongs to a database session, fn
+// gets the connection holding it. SQLite locks the whole file on every write
+// and needs none.
+func withLock(db *gorm.DB, fn func(db *gorm.DB) error) error {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return db.Connection(func(conn *gorm.DB) error {
+			var locked int
+
+			if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&locked).Error; err != nil {
+				return err
+			}
+
+			if locked != 1 {
+				return ErrMigrationLocked
+			}
+
+			defer conn.Exec("SELECT RELEASE_LOCK(?)", lockName)
+
+			return fn(conn)
+		})
+	case "postgres":
+		return db.Connection(func(conn *gorm.DB) error {
+			if err := conn.Exec(fmt.Sprintf("SET lock_timeout = %d", lockTimeout.Milliseconds())).Error; err != nil {
+				return err
+			}
+
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+				return fmt.Errorf("%w: %w", ErrMigrationLocked, err)
+			}
+
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+			defer conn.Exec("RESET lock_timeout")
+
+			return fn(conn)
+		})
+	default:
+		return fn(db)
+	}
+}
+
+// withoutForeignKeys turns off foreign keys on SQLite, which alters a table
+// by copying it into a new one, and returns the function turning them back
+// on. The pragma has no effect inside a transaction so it is set around the
+// migrations.
+func withoutForeignKeys(db *gorm.DB) func() {
+	if db.Dialector.Name() != "sqlite" {
+		return func() {}
+	}
+
+	db.Exec("PRAGMA foreign_keys = OFF")
+
+	return func() {
+		db.Exec("PRAGMA foreign_keys = ON")
+	}
+}
@@ -0,0 +1,294 @@
+package migrations_test
+
+import (
+	"keuangan-pribadi/config"
+	"keuangan-pribadi/migrations"
+	"keuangan-pribadi/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// openDB is an empty in-memory database of its own for every test.
+func openDB(t *testing.T) *gorm.DB {
+	db, err := config.OpenDB(config.DriverSQLite, "file::memory:", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = config.CloseDB(db)
+	})
+
+	return db
+}
+
+func TestUp_Success(t *testing.T) {
+	db := openDB(t)
+
+	pending, err := migrations.Pending(db)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int(migrations.Latest()), pending)
+	}
+
+	applied, err := migrations.Up(db)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, applied, int(migrations.Latest())) {
+		for i, migration := range applied {
+			assert.Equal(t, uint(i+1), migration.Version)
+		}
+	}
+
+	for _, table := range []string{"users", "finances", "ledgers", "market_prices", "gold_savings", "gold_deposits", "webhook_deliveries"} {
+		assert.True(t, db.Migrator().HasTable(table), table)
+	}
+
+	assert.True(t, db.Migrator().HasColumn("finances", "money_minor"))
+	assert.False(t, db.Migrator().HasColumn("finances", "money"))
+
+	pending, err = migrations.Pending(db)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, pending)
+	}
+
+	// an up to date database is left alone
+	applied, err = migrations.Up(db)
+	if assert.NoError(t, err) {
+		assert.Empty(t, applied)
+	}
+}
+
+func TestUp_Failed(t *testing.T) {
+	db := openDB(t)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// a newer binary migrated the database
+	db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", migrations.Latest()+1, "from_the_future", time.Now())
+
+	_, err := migrations.Up(db)
+	assert.ErrorIs(t, err, migrations.ErrSchemaTooNew)
+
+	_, err = migrations.Down(db, 1)
+	assert.ErrorIs(t, err, migrations.ErrSchemaTooNew)
+
+	_, err = migrations.Pending(db)
+	assert.ErrorIs(t, err, migrations.ErrSchemaTooNew)
+}
+
+func TestDown_Success(t *testing.T) {
+	db := openDB(t)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	rolledBack, err := migrations.Down(db, 1)
+	if assert.NoError(t, err) && assert.Len(t, rolledBack, 1) {
+		assert.Equal(t, migrations.Latest(), rolledBack[0].Version)
+	}
+
+	pending, err := migrations.Pending(db)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, pending)
+	}
+
+	// every migration can be rolled back and applied again
+	rolledBack, err = migrations.Down(db, int(migrations.Latest()))
+	if assert.NoError(t, err) {
+		assert.Len(t, rolledBack, int(migrations.Latest())-1)
+	}
+
+	assert.False(t, db.Migrator().HasTable("users"))
+	assert.False(t, db.Migrator().HasTable("gold_savings"))
+
+	applied, err := migrations.Up(db)
+	if assert.NoError(t, err) {
+		assert.Len(t, applied, int(migrations.Latest()))
+	}
+}
+
+func TestStatus_Success(t *testing.T) {
+	db := openDB(t)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrations.Down(db, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := migrations.Status(db)
+	if !assert.NoError(t, err) || !assert.Len(t, statuses, int(migrations.Latest())) {
+		return
+	}
+
+	for _, status := range statuses[:len(statuses)-1] {
+		assert.NotNil(t, status.AppliedAt, status.Name)
+	}
+
+	assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+	assert.Equal(t, "initial_schema", statuses[0].Name)
+}
+
+func TestPending_ReadOnly(t *testing.T) {
+	db := openDB(t)
+
+	for i := 0; i < 2; i++ {
+		pending, err := migrations.Pending(db)
+		if assert.NoError(t, err) {
+			assert.Equal(t, int(migrations.Latest()), pending)
+		}
+	}
+
+	// asking what is pending creates nothing
+	assert.False(t, db.Migrator().HasTable("schema_migrations"))
+}
+
+func TestUp_LegacyData(t *testing.T) {
+	db := openDB(t)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// back to the schema AutoMigrate left before versioned migrations
+	if _, err := migrations.Down(db, int(migrations.Latest())-1); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	db.Exec("INSERT INTO users (id, name, email, role, created_at, updated_at) VALUES (1, 'budi', 'budi@example.com', 'user', ?, ?)", now, now)
+	db.Exec("INSERT INTO categories (id, name, created_at, updated_at) VALUES (1, 'food', ?, ?)", now, now)
+	db.Exec("INSERT INTO finances (name, type, money, user_id, category_id, created_at, updated_at) VALUES ('lunch', 2, 1500, 1, 1, ?, ?)", now, now)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var finance struct {
+		MoneyMinor    int64
+		MoneyCurrency string
+		LedgerID      *uint
+	}
+	db.Table("finances").Where("name = ?", "lunch").Take(&finance)
+
+	// whole rupiah became sen and the finance moved to a personal ledger
+	assert.Equal(t, int64(150000), finance.MoneyMinor)
+	assert.Equal(t, "IDR", finance.MoneyCurrency)
+
+	if assert.NotNil(t, finance.LedgerID) {
+		var owner struct {
+			UserID uint
+			Role   string
+		}
+		db.Table("ledger_members").Where("ledger_id = ?", *finance.LedgerID).Take(&owner)
+
+		assert.Equal(t, uint(1), owner.UserID)
+		assert.Equal(t, "owner", owner.Role)
+	}
+}
+
+func TestUp_LegacyDataCurrency(t *testing.T) {
+	db := openDB(t)
+
+	if err := money.SetDefaultCurrency("KWD"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = money.SetDefaultCurrency("IDR")
+	})
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrations.Down(db, int(migrations.Latest())-1); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	db.Exec("INSERT INTO users (id, name, email, role, created_at, updated_at) VALUES (1, 'budi', 'budi@example.com', 'user', ?, ?)", now, now)
+	db.Exec("INSERT INTO categories (id, name, created_at, updated_at) VALUES (1, 'food', ?, ?)", now, now)
+	db.Exec("INSERT INTO finances (name, type, money, user_id, category_id, created_at, updated_at) VALUES ('lunch', 2, 15, 1, 1, ?, ?)", now, now)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var finance struct {
+		MoneyMinor    int64
+		MoneyCurrency string
+	}
+	db.Table("finances").Where("name = ?", "lunch").Take(&finance)
+
+	// the amounts were in the configured currency, which has three digits
+	assert.Equal(t, int64(15000), finance.MoneyMinor)
+	assert.Equal(t, "KWD", finance.MoneyCurrency)
+}
+
+func TestDown_OtherCurrency(t *testing.T) {
+	db := openDB(t)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	db.Exec("INSERT INTO users (id, name, email, role, created_at, updated_at) VALUES (1, 'budi', 'budi@example.com', 'user', ?, ?)", now, now)
+	db.Exec("INSERT INTO categories (id, name, created_at, updated_at) VALUES (1, 'food', ?, ?)", now, now)
+	db.Exec("INSERT INTO finances (name, type, money_minor, money_currency, user_id, category_id, created_at, updated_at) VALUES ('lunch', 2, 1500, 'USD', 1, 1, ?, ?)", now, now)
+
+	// dollars cannot become whole rupiah
+	_, err := migrations.Down(db, int(migrations.Latest())-1)
+	assert.ErrorContains(t, err, "not in IDR")
+
+	// the rollback stopped at the money migration
+	statuses, err := migrations.Status(db)
+	if assert.NoError(t, err) {
+		assert.NotNil(t, statuses[2].AppliedAt)
+		assert.Nil(t, statuses[3].AppliedAt)
+	}
+
+	assert.True(t, db.Migrator().HasColumn("finances", "money_minor"))
+}
+
+func TestUp_DuplicateEmails(t *testing.T) {
+	db := openDB(t)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// back to before emails were unique
+	if _, err := migrations.Down(db, int(migrations.Latest())-3); err != nil {
+		t.Fatal(err)
+	}
+
+	// the oldest account was deleted, login reached the second one
+	now := time.Now().UTC()
+	db.Exec("INSERT INTO users (id, name, email, role, created_at, updated_at, deleted_at) VALUES (1, 'old', 'budi@example.com', 'user', ?, ?, ?)", now, now, now)
+	db.Exec("INSERT INTO users (id, name, email, role, created_at, updated_at) VALUES (2, 'budi', 'budi@example.com', 'user', ?, ?)", now, now)
+	db.Exec("INSERT INTO users (id, name, email, role, created_at, updated_at) VALUES (3, 'budi', 'budi@example.com', 'user', ?, ?)", now, now)
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var emails []string
+	db.Table("users").Order("id").Pluck("email", &emails)
+
+	assert.Equal(t, []string{"budi@example.com.duplicate-1", "budi@example.com", "budi@example.com.duplicate-3"}, emails)
+
+	err := db.Exec("INSERT INTO users (name, email, role, created_at, updated_at) VALUES ('budi', 'budi@example.com', 'user', ?, ?)", now, now).Error
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditLog is an append-only record of a single data change.
+type AuditLog struct {
+	ID         uint                   `json:"id" gorm:"primaryKey"`
+	ActorID    uint                   `json:"actor_id" gorm:"index"`
+	OwnerID    uint                   `json:"owner_id" gorm:"index"`
+	Action     string                 `json:"action" gorm:"size:10"`
+	EntityType string                 `json:"entity_type" gorm:"size:50;index:idx_audit_entity"`
+	EntityID   uint                   `json:"entity_id" gorm:"index:idx_audit_entity"`
+	Before     map[string]interface{} `json:"before" gorm:"serializer:json;type:text"`
+	After      map[string]interface{} `json:"after" gorm:"serializer:json;type:text"`
+	Changes    map[string]AuditChange `json:"changes" gorm:"serializer:json;type:text"`
+	IP         string                 `json:"ip" gorm:"size:45"`
+	UserAgent  string                 `json:"user_agent"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditMeta describes who made a change and from where.
+type AuditMeta struct {
+	ActorID   uint
+	IP        string
+	UserAgent string
+}
+
+type AuditLogFilter struct {
+	EntityType string `query:"entity_type"`
+	EntityID   uint   `query:"entity_id"`
+	Action     string `query:"action"`
+	Limit      int    `query:"limit"`
+}
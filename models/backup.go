@@ -0,0 +1,263 @@
+package models
+
+import (
+	"keuangan-pribadi/money"
+	"time"
+)
+
+// BackupVersion is the version of the backup format written by this build.
+// Restoring accepts backups up to this version. Version 2 writes amounts as
+// money with a currency, the plain numbers of version 1 are read in the
+// default currency. Version 3 adds debts with their repayments, bills and
+// gold savings with their deposits.
+const BackupVersion = 3
+
+const (
+	RestoreModeReplace = "replace"
+	RestoreModeMerge   = "merge"
+)
+
+// Backup is the portable format of a user's complete dataset. IDs are the
+// ones of the instance that wrote the backup and are remapped on restore.
+type Backup struct {
+	Version        int                   `json:"version"`
+	CreatedAt      time.Time             `json:"created_at"`
+	Categories     []BackupCategory      `json:"categories"`
+	Finances       []BackupFinance       `json:"finances"`
+	Savings        []BackupSaving        `json:"savings"`
+	DetailSavings  []BackupDetailSaving  `json:"detail_savings"`
+	Debts          []BackupDebt          `json:"debts"`
+	DebtRepayments []BackupDebtRepayment `json:"debt_repayments"`
+	Bills          []BackupBill          `json:"bills"`
+	GoldSavings    []BackupGoldSaving    `json:"gold_savings"`
+	GoldDeposits   []BackupGoldDeposit   `json:"gold_deposits"`
+}
+
+type BackupCategory struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type BackupFinance struct {
+	ID         uint        `json:"id"`
+	Name       string      `json:"name"`
+	Type       int         `json:"type"`
+	Money      money.Money `json:"money"`
+	CategoryID uint        `json:"category_id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+type BackupSaving struct {
+	ID        uint        `json:"id"`
+	Name      string      `json:"name"`
+	Value     money.Money `json:"value"`
+	Goal      money.Money `json:"goal"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type BackupDetailSaving struct {
+	ID        uint        `json:"id"`
+	SavingID  uint        `json:"saving_id"`
+	Value     money.Money `json:"value"`
+	Status    int8        `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type BackupDebt struct {
+	ID           uint        `json:"id"`
+	Counterparty string      `json:"counterparty"`
+	Direction    string      `json:"direction"`
+	Principal    money.Money `json:"principal"`
+	Paid         money.Money `json:"paid"`
+	Note         string      `json:"note"`
+	DueDate      *time.Time  `json:"due_date"`
+	SettledAt    *time.Time  `json:"settled_at"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// BackupDebtRepayment is a repayment of a debt, FinanceID is the finance it
+// was booked as.
+type BackupDebtRepayment struct {
+	ID        uint        `json:"id"`
+	DebtID    uint        `json:"debt_id"`
+	Amount    money.Money `json:"amount"`
+	FinanceID uint        `json:"finance_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// BackupBill is a bill, RemindedFor keeps a reminder that was already sent
+// from being sent again after a restore.
+type BackupBill struct {
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	Amount      money.Money `json:"amount"`
+	DueDay      int         `json:"due_day"`
+	Recurrence  string      `json:"recurrence"`
+	LeadDays    int         `json:"lead_days"`
+	NextDueDate time.Time   `json:"next_due_date"`
+	RemindedFor *time.Time  `json:"reminded_for"`
+	Active      bool        `json:"active"`
+	CategoryID  uint        `json:"category_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// BackupGoldSaving is a gold saving without its totals, they are added up
+// again from the deposits on restore.
+type BackupGoldSaving struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Goal      Grams     `json:"goal"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BackupGoldDeposit struct {
+	ID           uint        `json:"id"`
+	GoldSavingID uint        `json:"gold_saving_id"`
+	Grams        Grams       `json:"grams"`
+	Price        money.Money `json:"price"`
+	PurchasedAt  time.Time   `json:"purchased_at"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+type RestoreResult struct {
+	Mode           string `json:"mode"`
+	Categories     int    `json:"categories"`
+	Finances       int    `json:"finances"`
+	Savings        int    `json:"savings"`
+	DetailSavings  int    `json:"detail_savings"`
+	Debts          int    `json:"debts"`
+	DebtRepayments int    `json:"debt_repayments"`
+	Bills          int    `json:"bills"`
+	GoldSavings    int    `json:"gold_savings"`
+	GoldDeposits   int    `json:"gold_deposits"`
+}
+
+// NewBackup converts an export of the database rows into the backup format.
+func NewBackup(export UserExport) Backup {
+	backup := Backup{
+		Version:        BackupVersion,
+		CreatedAt:      time.Now(),
+		Categories:     []BackupCategory{},
+		Finances:       []BackupFinance{},
+		Savings:        []BackupSaving{},
+		DetailSavings:  []BackupDetailSaving{},
+		Debts:          []BackupDebt{},
+		DebtRepayments: []BackupDebtRepayment{},
+		Bills:          []BackupBill{},
+		GoldSavings:    []BackupGoldSaving{},
+		GoldDeposits:   []BackupGoldDeposit{},
+	}
+
+	for _, category := range export.Categories {
+		backup.Categories = append(backup.Categories, BackupCategory{ID: category.ID, Name: category.Name})
+	}
+
+	for _, finance := range export.Finances {
+		backup.Finances = append(backup.Finances, BackupFinance{
+			ID:         finance.ID,
+			Name:       finance.Name,
+			Type:       finance.Type,
+			Money:      finance.Money,
+			CategoryID: finance.CategoryID,
+			CreatedAt:  finance.CreatedAt,
+			UpdatedAt:  finance.UpdatedAt,
+		})
+	}
+
+	for _, saving := range export.Savings {
+		backup.Savings = append(backup.Savings, BackupSaving{
+			ID:        saving.ID,
+			Name:      saving.Name,
+			Value:     saving.Value,
+			Goal:      saving.Goal,
+			CreatedAt: saving.CreatedAt,
+			UpdatedAt: saving.UpdatedAt,
+		})
+	}
+
+	for _, detailSaving := range export.DetailSavings {
+		backup.DetailSavings = append(backup.DetailSavings, BackupDetailSaving{
+			ID:        detailSaving.ID,
+			SavingID:  detailSaving.SavingID,
+			Value:     detailSaving.Value,
+			Status:    detailSaving.Status,
+			CreatedAt: detailSaving.CreatedAt,
+			UpdatedAt: detailSaving.UpdatedAt,
+		})
+	}
+
+	for _, debt := range export.Debts {
+		backup.Debts = append(backup.Debts, BackupDebt{
+			ID:           debt.ID,
+			Counterparty: debt.Counterparty,
+			Direction:    debt.Direction,
+			Principal:    debt.Principal,
+			Paid:         debt.Paid,
+			Note:         debt.Note,
+			DueDate:      debt.DueDate,
+			SettledAt:    debt.SettledAt,
+			CreatedAt:    debt.CreatedAt,
+			UpdatedAt:    debt.UpdatedAt,
+		})
+	}
+
+	for _, repayment := range export.DebtRepayments {
+		backup.DebtRepayments = append(backup.DebtRepayments, BackupDebtRepayment{
+			ID:        repayment.ID,
+			DebtID:    repayment.DebtID,
+			Amount:    repayment.Amount,
+			FinanceID: repayment.FinanceID,
+			CreatedAt: repayment.CreatedAt,
+			UpdatedAt: repayment.UpdatedAt,
+		})
+	}
+
+	for _, bill := range export.Bills {
+		backup.Bills = append(backup.Bills, BackupBill{
+			ID:          bill.ID,
+			Name:        bill.Name,
+			Amount:      bill.Amount,
+			DueDay:      bill.DueDay,
+			Recurrence:  bill.Recurrence,
+			LeadDays:    bill.LeadDays,
+			NextDueDate: bill.NextDueDate,
+			RemindedFor: bill.RemindedFor,
+			Active:      bill.Active,
+			CategoryID:  bill.CategoryID,
+			CreatedAt:   bill.CreatedAt,
+			UpdatedAt:   bill.UpdatedAt,
+		})
+	}
+
+	for _, goldSaving := range export.GoldSavings {
+		backup.GoldSavings = append(backup.GoldSavings, BackupGoldSaving{
+			ID:        goldSaving.ID,
+			Name:      goldSaving.Name,
+			Goal:      goldSaving.Goal,
+			CreatedAt: goldSaving.CreatedAt,
+			UpdatedAt: goldSaving.UpdatedAt,
+		})
+
+		for _, deposit := range goldSaving.Deposits {
+			backup.GoldDeposits = append(backup.GoldDeposits, BackupGoldDeposit{
+				ID:           deposit.ID,
+				GoldSavingID: deposit.GoldSavingID,
+				Grams:        deposit.Grams,
+				Price:        deposit.Price,
+				PurchasedAt:  deposit.PurchasedAt,
+				CreatedAt:    deposit.CreatedAt,
+				UpdatedAt:    deposit.UpdatedAt,
+			})
+		}
+	}
+
+	return backup
+}
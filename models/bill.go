@@ -0,0 +1,98 @@
+package models
+
+import (
+	"keuangan-pribadi/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BillRecurrenceOnce      = "once"
+	BillRecurrenceMonthly   = "monthly"
+	BillRecurrenceQuarterly = "quarterly"
+	BillRecurrenceYearly    = "yearly"
+)
+
+// Bill is a recurring payment such as electricity, BPJS or a credit card.
+// A reminder is sent LeadDays before NextDueDate and paying the bill books
+// an expense and moves NextDueDate to the following period.
+type Bill struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" form:"name"`
+	Amount      money.Money    `json:"amount" form:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	DueDay      int            `json:"due_day" form:"due_day"`
+	Recurrence  string         `json:"recurrence" form:"recurrence" gorm:"size:10"`
+	LeadDays    int            `json:"lead_days" form:"lead_days"`
+	NextDueDate time.Time      `json:"next_due_date" gorm:"index"`
+	RemindedFor *time.Time     `json:"reminded_for"`
+	Active      bool           `json:"active" gorm:"default:true"`
+	CategoryID  uint           `json:"category_id" form:"category_id"`
+	Category    Category       `json:"category" gorm:"foreignKey:CategoryID"`
+	UserID      uint           `json:"user_id" form:"user_id"`
+	LedgerID    *uint          `json:"ledger_id" form:"ledger_id" gorm:"index"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type BillInput struct {
+	Name       string      `json:"name" form:"name" validate:"required"`
+	Amount     money.Money `json:"amount" form:"amount" validate:"required,min=1"`
+	DueDay     int         `json:"due_day" form:"due_day" validate:"required,min=1,max=31"`
+	Recurrence string      `json:"recurrence" form:"recurrence" validate:"required,oneof=once monthly quarterly yearly"`
+	LeadDays   int         `json:"lead_days" form:"lead_days" validate:"min=0,max=60"`
+	CategoryID uint        `json:"category_id" form:"category_id" validate:"required"`
+	LedgerID   uint        `json:"ledger_id" form:"ledger_id"`
+}
+
+// BillPayment pays a bill. Money defaults to the amount of the bill, it can
+// be set when the actual bill differs, e.g. for electricity.
+type BillPayment struct {
+	Money money.Money `json:"money" form:"money" validate:"omitempty,min=1"`
+}
+
+// FirstDueDate is the first date on or after from that falls on dueDay. In
+// shorter months the last day of the month is used instead.
+func FirstDueDate(dueDay int, from time.Time) time.Time {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+
+	due := dueDateIn(from.Year(), from.Month(), dueDay, from.Location())
+	if due.Before(from) {
+		due = dueDateIn(from.Year(), from.Month()+1, dueDay, from.Location())
+	}
+
+	return due
+}
+
+// FollowingDueDate is the due date of the period after NextDueDate. It
+// returns false when the bill does not recur.
+func (b Bill) FollowingDueDate() (time.Time, bool) {
+	months := map[string]int{
+		BillRecurrenceMonthly:   1,
+		BillRecurrenceQuarterly: 3,
+		BillRecurrenceYearly:    12,
+	}[b.Recurrence]
+
+	if months == 0 {
+		return time.Time{}, false
+	}
+
+	next := b.NextDueDate
+	return dueDateIn(next.Year(), next.Month()+time.Month(months), b.DueDay, next.Location()), true
+}
+
+// RemindAt is the moment the reminder for the current period is due.
+func (b Bill) RemindAt() time.Time {
+	return b.NextDueDate.AddDate(0, 0, -b.LeadDays)
+}
+
+func dueDateIn(year int, month time.Month, day int, loc *time.Location) time.Time {
+	// day 0 of the next month is the last day of this one
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > last {
+		day = last
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
@@ -9,6 +9,7 @@ import (
 type Category struct {
 	ID        	uint           	`json:"id" gorm:"primaryKey"`
 	Name     	string 		 	`json:"name" form:"name"`
+	LedgerID 	*uint 			`json:"ledger_id" form:"ledger_id" gorm:"index"`
 	CreatedAt 	time.Time      	`json:"created_at"`
 	UpdatedAt 	time.Time      	`json:"updated_at"`
 	DeletedAt 	gorm.DeletedAt 	`json:"deleted_at" gorm:"index"`
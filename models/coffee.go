@@ -5,9 +5,14 @@ type CoffeePrice struct {
 	Value string `json:"value"`
 }
 
+// CoffeePriceResponse has the prices converted to Currency. Stale tells the
+// market-data provider could not be reached and the prices are the last
+// ones known.
 type CoffeePriceResponse struct {
 	Name     string        `json:"name"`
 	Interval string        `json:"interval"`
 	Unit     string        `json:"unit"`
+	Currency string        `json:"currency"`
+	Stale    bool          `json:"stale"`
 	Data     []CoffeePrice `json:"data"`
-}
\ No newline at end of file
+}
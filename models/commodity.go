@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// CommodityPriceFilter picks the prices of a commodity. From and To are
+// dates like 2024-01-31, both included, the whole history without them.
+type CommodityPriceFilter struct {
+	Interval string `query:"interval" validate:"omitempty,oneof=daily weekly monthly quarterly annual"`
+	Currency string `query:"currency" validate:"omitempty,len=3"`
+	Locale   string `query:"locale"`
+	From     string `query:"from"`
+	To       string `query:"to"`
+}
+
+// CommodityPrice is a price in the currency of the response, as a number
+// and formatted for the locale of the request.
+type CommodityPrice struct {
+	Date      string  `json:"date,omitempty"`
+	Value     float64 `json:"value"`
+	Formatted string  `json:"formatted"`
+}
+
+// CommodityStats summarize the prices of the requested window.
+// PercentChange is from the first price to the last one.
+type CommodityStats struct {
+	Min           CommodityPrice `json:"min"`
+	Max           CommodityPrice `json:"max"`
+	Average       CommodityPrice `json:"average"`
+	PercentChange float64        `json:"percent_change"`
+}
+
+// CommodityPrices are the prices of a commodity converted to Currency with
+// ExchangeRate. Stats is null when no price falls in the window. Stale
+// tells the market-data provider could not be reached and the prices are
+// the last ones known.
+type CommodityPrices struct {
+	Symbol       string           `json:"symbol"`
+	Name         string           `json:"name"`
+	Interval     string           `json:"interval"`
+	Unit         string           `json:"unit"`
+	Currency     string           `json:"currency"`
+	ExchangeRate float64          `json:"exchange_rate"`
+	FetchedAt    time.Time        `json:"fetched_at"`
+	Stale        bool             `json:"stale"`
+	Stats        *CommodityStats  `json:"stats"`
+	Data         []CommodityPrice `json:"data"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"keuangan-pribadi/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DebtDirectionPayable is money borrowed from the counterparty (hutang).
+	DebtDirectionPayable = "payable"
+	// DebtDirectionReceivable is money lent to the counterparty (piutang).
+	DebtDirectionReceivable = "receivable"
+)
+
+type Debt struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	Counterparty string          `json:"counterparty" form:"counterparty" gorm:"index"`
+	Direction    string          `json:"direction" form:"direction" gorm:"size:10"`
+	Principal    money.Money     `json:"principal" form:"principal" gorm:"embedded;embeddedPrefix:principal_"`
+	Paid         money.Money     `json:"paid" gorm:"embedded;embeddedPrefix:paid_"`
+	Outstanding  money.Money     `json:"outstanding" gorm:"-"`
+	Note         string          `json:"note" form:"note"`
+	DueDate      *time.Time      `json:"due_date" form:"due_date"`
+	SettledAt    *time.Time      `json:"settled_at"`
+	UserID       uint            `json:"user_id" form:"user_id"`
+	LedgerID     *uint           `json:"ledger_id" form:"ledger_id" gorm:"index"`
+	User         User            `json:"-" gorm:"foreignKey:UserID"`
+	Repayments   []DebtRepayment `json:"repayments,omitempty" gorm:"foreignKey:DebtID"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt  `json:"deleted_at" gorm:"index"`
+}
+
+// DebtRepayment is a partial or full repayment of a debt. Every repayment is
+// booked as a finance entry: an expense when paying back a payable, an
+// income when a receivable is paid back.
+type DebtRepayment struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	DebtID    uint        `json:"debt_id" gorm:"index"`
+	Amount    money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	FinanceID uint        `json:"finance_id"`
+	Finance   Finance     `json:"finance" gorm:"foreignKey:FinanceID"`
+	UserID    uint        `json:"user_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type DebtInput struct {
+	Counterparty string      `json:"counterparty" form:"counterparty" validate:"required"`
+	Direction    string      `json:"direction" form:"direction" validate:"required,oneof=payable receivable"`
+	Principal    money.Money `json:"principal" form:"principal" validate:"required,min=1"`
+	Note         string      `json:"note" form:"note"`
+	DueDate      *time.Time  `json:"due_date" form:"due_date"`
+	LedgerID     uint        `json:"ledger_id" form:"ledger_id"`
+}
+
+type DebtRepaymentInput struct {
+	Amount     money.Money `json:"amount" form:"amount" validate:"required,min=1"`
+	CategoryID uint        `json:"category_id" form:"category_id" validate:"required"`
+}
+
+// DebtSummary is the outstanding balance per counterparty. Net is positive
+// when the counterparty owes money overall and negative when it is owed.
+// Every total has one entry per currency.
+type DebtSummary struct {
+	Payable        money.Totals              `json:"payable"`
+	Receivable     money.Totals              `json:"receivable"`
+	Net            money.Totals              `json:"net"`
+	Counterparties []DebtCounterpartySummary `json:"counterparties"`
+}
+
+type DebtCounterpartySummary struct {
+	Counterparty string       `json:"counterparty"`
+	Payable      money.Totals `json:"payable"`
+	Receivable   money.Totals `json:"receivable"`
+	Net          money.Totals `json:"net"`
+}
+
+func (d *Debt) AfterFind(tx *gorm.DB) error {
+	outstanding, err := d.Principal.Sub(d.Paid)
+	if err != nil {
+		return err
+	}
+
+	d.Outstanding = outstanding
+	return nil
+}
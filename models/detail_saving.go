@@ -1,6 +1,7 @@
 package models
 
 import (
+	"keuangan-pribadi/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,7 +9,7 @@ import (
 
 type DetailSaving struct {
 	ID        	uint           	`json:"id" gorm:"primaryKey"`
-	Value     	int 		 	`json:"value" form:"value"`
+	Value     	money.Money 	`json:"value" form:"value" gorm:"embedded;embeddedPrefix:value_"`
 	Status     	int8 		 	`json:"status" form:"status" gorm:"check:status IN(1,2)"`
 	SavingID 	uint 			`json:"saving_id" form:"saving_id"`
 	Saving   	Saving 			`gorm:"foreignKey:SavingID"`
@@ -20,7 +21,7 @@ type DetailSaving struct {
 }
 
 type DetailSavingInput struct {
-	Value     	int 	`json:"value" form:"value" validate:"required"`
+	Value     	money.Money `json:"value" form:"value" validate:"required"`
 	SavingID    uint 	`json:"saving_id" form:"saving_id" validate:"required"`
 	UserID 		uint 	`json:"user_id" form:"user_id"`
 }
\ No newline at end of file
@@ -0,0 +1,89 @@
+package models
+
+// ErrorKind is what went wrong in the terms of the domain, the HTTP layer
+// picks the status code from it.
+type ErrorKind string
+
+const (
+	ErrorKindNotFound     ErrorKind = "not_found"
+	ErrorKindForbidden    ErrorKind = "forbidden"
+	ErrorKindValidation   ErrorKind = "validation_failed"
+	ErrorKindConflict     ErrorKind = "conflict"
+	ErrorKindUnauthorized ErrorKind = "unauthorized"
+	ErrorKindUnavailable  ErrorKind = "unavailable"
+)
+
+// Error is an error the client may see. Code is a machine-readable reason
+// like "finance_not_found", Message is for people. The cause, which may hold
+// details like SQL, is never sent to the client.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Err     error
+}
+
+// the kinds to match with errors.Is, like errors.Is(err, models.ErrNotFound)
+var (
+	ErrNotFound     = &Error{Kind: ErrorKindNotFound}
+	ErrForbidden    = &Error{Kind: ErrorKindForbidden}
+	ErrValidation   = &Error{Kind: ErrorKindValidation}
+	ErrConflict     = &Error{Kind: ErrorKindConflict}
+	ErrUnauthorized = &Error{Kind: ErrorKindUnauthorized}
+	ErrUnavailable  = &Error{Kind: ErrorKindUnavailable}
+)
+
+func NotFoundError(code, message string) *Error {
+	return &Error{Kind: ErrorKindNotFound, Code: code, Message: message}
+}
+
+func ForbiddenError(code, message string) *Error {
+	return &Error{Kind: ErrorKindForbidden, Code: code, Message: message}
+}
+
+func ValidationError(code, message string) *Error {
+	return &Error{Kind: ErrorKindValidation, Code: code, Message: message}
+}
+
+func ConflictError(code, message string) *Error {
+	return &Error{Kind: ErrorKindConflict, Code: code, Message: message}
+}
+
+func UnauthorizedError(code, message string) *Error {
+	return &Error{Kind: ErrorKindUnauthorized, Code: code, Message: message}
+}
+
+func UnavailableError(code, message string) *Error {
+	return &Error{Kind: ErrorKindUnavailable, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches an error of the same kind, and of the same code when the
+// target has one.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return e.Kind == t.Kind && (t.Code == "" || e.Code == t.Code)
+}
+
+// Wrap keeps the cause of the error for the logs.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+
+	return &wrapped
+}
@@ -0,0 +1,11 @@
+package models
+
+// the domain events no webhook can subscribe to, they are only counted in
+// the metrics
+const (
+	EventGoldSavingGoalReached = "gold_saving.goal_reached"
+	EventBackupRestored        = "backup.restored"
+)
+
+// Events are every domain event, the webhook events included.
+var Events = append(append([]string{}, WebhookEvents...), EventGoldSavingGoalReached, EventBackupRestored)
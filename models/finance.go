@@ -1,6 +1,7 @@
 package models
 
 import (
+	"keuangan-pribadi/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,9 +11,10 @@ type Finance struct {
 	ID        	uint           	`json:"id" gorm:"primaryKey"`
 	Name     	string 			`json:"name" form:"name"`
 	Type		int 			`json:"type" form:"type" gorm:"check:type IN(1,2)"`
-	Money		int 			`json:"money" form:"money"`
+	Money		money.Money 	`json:"money" form:"money" gorm:"embedded;embeddedPrefix:money_"`
 	UserID 		uint 			`json:"user_id" form:"user_id"`
 	CategoryID 	uint 			`json:"category_id" form:"category_id"`
+	LedgerID 	*uint 			`json:"ledger_id" form:"ledger_id" gorm:"index"`
 	User   		User 			`gorm:"foreignKey:UserID"`
 	Category   	Category 		`gorm:"foreignKey:CategoryID"`
 	CreatedAt 	time.Time      	`json:"created_at"`
@@ -23,7 +25,8 @@ type Finance struct {
 type FinanceInput struct {
 	Name		string 	`json:"name" form:"name" validate:"required"`
 	Type    	int 	`json:"type" form:"type" validate:"required"`
-	Money    	int 	`json:"money" form:"money" validate:"required"`
+	Money    	money.Money `json:"money" form:"money" validate:"required"`
 	UserID 		uint 	`json:"user_id" form:"user_id"`
 	CategoryID 	uint 	`json:"category_id" form:"category_id" validate:"required"`
+	LedgerID 	uint 	`json:"ledger_id" form:"ledger_id"`
 }
\ No newline at end of file
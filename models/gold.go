@@ -0,0 +1,175 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"keuangan-pribadi/money"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GramsPerTroyOunce converts the price of gold, quoted per troy ounce, to a
+// price per gram.
+const GramsPerTroyOunce = 31.1034768
+
+// gramDecimals is the precision gold savings are sold in, 0.0001 g.
+const gramDecimals = 4
+
+var ErrInvalidGrams = errors.New("invalid weight")
+
+// Grams is a weight of gold kept as an integer of ten-thousandths of a gram,
+// so 1.5 g is 15000. It is written to JSON as a string like "1.5000" so no
+// client reads it as a float.
+type Grams int64
+
+// ParseGrams reads a decimal weight like "1.5". More than four decimals are
+// refused instead of being rounded.
+func ParseGrams(weight string) (Grams, error) {
+	weight = strings.TrimSpace(weight)
+
+	negative := strings.HasPrefix(weight, "-")
+	weight = strings.TrimPrefix(strings.TrimPrefix(weight, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(weight, ".")
+	if whole == "" && fraction == "" || len(fraction) > gramDecimals || strings.ContainsAny(whole+fraction, "+-") {
+		return 0, fmt.Errorf("%w %q, grams have at most %d decimals", ErrInvalidGrams, weight, gramDecimals)
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", gramDecimals-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidGrams, weight)
+	}
+
+	if negative {
+		units = -units
+	}
+
+	return Grams(units), nil
+}
+
+// Float64 is the weight in grams, for prices only.
+func (g Grams) Float64() float64 {
+	return float64(g) / 10000
+}
+
+func (g Grams) String() string {
+	sign := ""
+	units := int64(g)
+	if units < 0 {
+		sign, units = "-", -units
+	}
+
+	return fmt.Sprintf("%s%d.%04d", sign, units/10000, units%10000)
+}
+
+func (g Grams) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.String())
+}
+
+// UnmarshalJSON reads a weight written as a string or a number.
+func (g *Grams) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseGrams(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+
+	*g = parsed
+
+	return nil
+}
+
+// UnmarshalParam reads a weight from a form or query parameter.
+func (g *Grams) UnmarshalParam(param string) error {
+	parsed, err := ParseGrams(param)
+	if err != nil {
+		return err
+	}
+
+	*g = parsed
+
+	return nil
+}
+
+// GoldSaving is a saving in grams of gold, like tabungan emas. Grams is what
+// the deposits add up to and Cost what was paid for them.
+type GoldSaving struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name"`
+	Goal      Grams          `json:"goal" gorm:"not null;default:0"`
+	Grams     Grams          `json:"grams" gorm:"not null;default:0"`
+	Cost      money.Money    `json:"cost" gorm:"embedded;embeddedPrefix:cost_"`
+	UserID    uint           `json:"user_id" gorm:"index"`
+	LedgerID  *uint          `json:"ledger_id" gorm:"index"`
+	Deposits  []GoldDeposit  `json:"deposits,omitempty" gorm:"foreignKey:GoldSavingID"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// GoldDeposit is gold bought for a saving. Price is what was paid for all
+// of its grams.
+type GoldDeposit struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	GoldSavingID uint           `json:"gold_saving_id" gorm:"index"`
+	Grams        Grams          `json:"grams" gorm:"not null"`
+	Price        money.Money    `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	PurchasedAt  time.Time      `json:"purchased_at"`
+	UserID       uint           `json:"user_id" gorm:"index"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type GoldSavingInput struct {
+	Name     string `json:"name" form:"name" validate:"required"`
+	Goal     Grams  `json:"goal" form:"goal" validate:"required,min=1"`
+	LedgerID uint   `json:"ledger_id" form:"ledger_id"`
+}
+
+type GoldSavingUpdate struct {
+	Name string `json:"name" form:"name" validate:"required"`
+	Goal Grams  `json:"goal" form:"goal" validate:"required,min=1"`
+}
+
+// GoldDepositInput is gold bought for a saving, bought now without a
+// purchase date.
+type GoldDepositInput struct {
+	Grams       Grams       `json:"grams" form:"grams" validate:"required,min=1"`
+	Price       money.Money `json:"price" form:"price" validate:"required,min=1"`
+	PurchasedAt time.Time   `json:"purchased_at" form:"purchased_at"`
+}
+
+// GoldValuation is what a gold saving is worth at the current gold price,
+// in the currency it was bought in. Stale tells the market-data provider
+// could not be reached and the price is the last one known.
+type GoldValuation struct {
+	GoldSavingID          uint                   `json:"gold_saving_id"`
+	Grams                 Grams                  `json:"grams"`
+	Goal                  Grams                  `json:"goal"`
+	GoalPercent           float64                `json:"goal_percent"`
+	PricePerGram          money.Money            `json:"price_per_gram"`
+	Value                 money.Money            `json:"value"`
+	Cost                  money.Money            `json:"cost"`
+	UnrealizedGain        money.Money            `json:"unrealized_gain"`
+	UnrealizedGainPercent float64                `json:"unrealized_gain_percent"`
+	Formatted             GoldValuationFormatted `json:"formatted"`
+	PricedAt              time.Time              `json:"priced_at"`
+	Stale                 bool                   `json:"stale"`
+}
+
+// GoldValuationFormatted has the amounts of a valuation formatted for the
+// locale of the request.
+type GoldValuationFormatted struct {
+	PricePerGram   string `json:"price_per_gram"`
+	Value          string `json:"value"`
+	Cost           string `json:"cost"`
+	UnrealizedGain string `json:"unrealized_gain"`
+}
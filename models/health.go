@@ -0,0 +1,7 @@
+package models
+
+// Health is the state of the dependencies checked by the readiness probe.
+type Health struct {
+	Database      string `json:"database"`
+	SchemaVersion uint   `json:"schema_version"`
+}
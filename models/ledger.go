@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	LedgerRoleOwner  = "owner"
+	LedgerRoleEditor = "editor"
+	LedgerRoleViewer = "viewer"
+)
+
+// Ledger groups finances, categories and savings that are shared between
+// its members. Every user has a personal ledger created on registration.
+type Ledger struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" form:"name"`
+	Personal  bool           `json:"personal"`
+	Members   []LedgerMember `json:"members,omitempty" gorm:"foreignKey:LedgerID"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type LedgerMember struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	LedgerID  uint      `json:"ledger_id" gorm:"uniqueIndex:idx_ledger_member"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_ledger_member"`
+	User      User      `json:"-" gorm:"foreignKey:UserID"`
+	Role      string    `json:"role" gorm:"size:10"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LedgerInvitation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	LedgerID    uint       `json:"ledger_id" gorm:"index"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role" gorm:"size:10"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex;size:64"`
+	InvitedByID uint       `json:"invited_by_id"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type LedgerInput struct {
+	Name string `json:"name" form:"name" validate:"required"`
+}
+
+type LedgerMemberInput struct {
+	Role string `json:"role" form:"role" validate:"required,oneof=editor viewer"`
+}
+
+type LedgerInvitationInput struct {
+	Email string `json:"email" form:"email" validate:"required,email"`
+	Role  string `json:"role" form:"role" validate:"required,oneof=editor viewer"`
+}
+
+type LedgerInvitationAccept struct {
+	Token string `json:"token" form:"token" validate:"required"`
+}
+
+// CanWrite reports whether the member may change data in the ledger.
+func (lm LedgerMember) CanWrite() bool {
+	return lm.Role == LedgerRoleOwner || lm.Role == LedgerRoleEditor
+}
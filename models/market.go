@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// PricePoint is the price of a commodity at a date, in whole units of the
+// currency of its series, like 2.15 dollars per pound.
+type PricePoint struct {
+	Date  time.Time
+	Value float64
+}
+
+// PriceSeries is the history of a commodity at an interval like monthly,
+// oldest first. Unit is the quantity a price is for, like pound. Stale tells
+// the provider could not be reached and the prices are the last ones known.
+type PriceSeries struct {
+	Symbol    string
+	Name      string
+	Interval  string
+	Unit      string
+	Currency  string
+	Points    []PricePoint
+	FetchedAt time.Time
+	Stale     bool
+}
+
+// ExchangeRate is how much of To one unit of From is worth.
+type ExchangeRate struct {
+	From      string
+	To        string
+	Rate      float64
+	FetchedAt time.Time
+	Stale     bool
+}
+
+// MarketPrice is a stored price. Every fetched price is kept, so the prices
+// can still be answered when the provider is down after a restart. Exchange
+// rates are stored with a symbol like USD/IDR and the rate interval.
+type MarketPrice struct {
+	ID        uint      `gorm:"primaryKey"`
+	Symbol    string    `gorm:"size:20;uniqueIndex:idx_market_prices_point"`
+	Interval  string    `gorm:"size:10;uniqueIndex:idx_market_prices_point"`
+	Date      time.Time `gorm:"uniqueIndex:idx_market_prices_point"`
+	Value     float64
+	Name      string
+	Unit      string
+	Currency  string `gorm:"size:3"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MarketPriceRateInterval is the interval of the stored exchange rates, one
+// row a day holding the last rate fetched that day.
+const MarketPriceRateInterval = "rate"
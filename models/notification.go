@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const NotificationTypeBillReminder = "bill_reminder"
+
+// Notification is a message in the in-app inbox of a user.
+type Notification struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index"`
+	Type       string     `json:"type" gorm:"size:30"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	EntityType string     `json:"entity_type" gorm:"size:30"`
+	EntityID   uint       `json:"entity_id"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type NotificationFilter struct {
+	Unread bool `query:"unread"`
+}
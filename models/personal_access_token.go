@@ -0,0 +1,57 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix marks a bearer token as a personal access token
+// instead of a session JWT.
+const PersonalAccessTokenPrefix = "kp_"
+
+// PersonalAccessTokenResources are the resources a personal access token can
+// be scoped to, each with a ":read" and ":write" scope.
+var PersonalAccessTokenResources = []string{"users", "categories", "finances", "savings", "detail-savings", "gold-savings", "tokens", "audit", "backups", "ledgers", "debts", "bills", "notifications", "webhooks"}
+
+type PersonalAccessToken struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name" form:"name"`
+	TokenHash  string         `json:"-" gorm:"uniqueIndex;size:64"`
+	Prefix     string         `json:"prefix"`
+	Scopes     []string       `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	UserID     uint           `json:"user_id" form:"user_id" gorm:"index"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type PersonalAccessTokenInput struct {
+	Name          string   `json:"name" form:"name" validate:"required"`
+	Scopes        []string `json:"scopes" form:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" form:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type PersonalAccessTokenResponse struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// HasScope reports whether the token grants the given scope, e.g. "finances:read".
+// A write scope also grants read access to the same resource.
+func (pat PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range pat.Scopes {
+		if s == scope {
+			return true
+		}
+
+		if resource, found := strings.CutSuffix(scope, ":read"); found && s == resource+":write" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,28 @@
+package models
+
+import "context"
+
+// Principal is who made a request. It is resolved once by the
+// authentication middleware and carried in the request context.
+type Principal struct {
+	UserID uint
+	Role   string
+	// PersonalAccessToken is set when the request was authenticated with a
+	// personal access token instead of a session
+	PersonalAccessToken *PersonalAccessToken
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the context carrying the principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext is the principal of the request, false when it was
+// not authenticated.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+
+	return principal, ok
+}
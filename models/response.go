@@ -3,5 +3,7 @@ package models
 type Response[T any] struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
-	Data    T      `json:"data,omitempty"`
-}
\ No newline at end of file
+	// Code is the machine-readable reason of a failed request
+	Code string `json:"code,omitempty"`
+	Data T      `json:"data,omitempty"`
+}
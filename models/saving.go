@@ -1,6 +1,7 @@
 package models
 
 import (
+	"keuangan-pribadi/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,10 +10,11 @@ import (
 type Saving struct {
 	ID        	uint           	`json:"id" gorm:"primaryKey"`
 	Name     	string 		 	`json:"name" form:"name"`
-	Value     	int 		 	`json:"value" form:"value"`
-	Goal     	int 		 	`json:"goal" form:"goal"`
+	Value     	money.Money 	`json:"value" form:"value" gorm:"embedded;embeddedPrefix:value_"`
+	Goal     	money.Money 	`json:"goal" form:"goal" gorm:"embedded;embeddedPrefix:goal_"`
 	UserID 		uint 			`json:"user_id" form:"user_id"`
 	User   		User 			`gorm:"foreignKey:UserID"`
+	LedgerID 	*uint 			`json:"ledger_id" form:"ledger_id" gorm:"index"`
 	CreatedAt 	time.Time      	`json:"created_at"`
 	UpdatedAt 	time.Time      	`json:"updated_at"`
 	DeletedAt 	gorm.DeletedAt 	`json:"deleted_at" gorm:"index"`
@@ -20,13 +22,14 @@ type Saving struct {
 
 type SavingInput struct {
 	Name     	string 	`json:"name" form:"name" validate:"required"`
-	Value     	int 	`json:"value" form:"value"`
-	Goal     	int 	`json:"goal" form:"goal" validate:"required"`
+	Value     	money.Money `json:"value" form:"value" validate:"min=0"`
+	Goal     	money.Money `json:"goal" form:"goal" validate:"required,min=1"`
 	UserID 		uint 	`json:"user_id" form:"user_id"`
+	LedgerID 	uint 	`json:"ledger_id" form:"ledger_id"`
 }
 
 type SavingUpdate struct {
 	Name     	string 	`json:"name" form:"name" validate:"required"`
-	Goal     	int 	`json:"goal" form:"goal" validate:"required"`
+	Goal     	money.Money `json:"goal" form:"goal" validate:"required,min=1"`
 	UserID 		uint 	`json:"user_id" form:"user_id"`
 }
\ No newline at end of file
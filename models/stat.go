@@ -0,0 +1,10 @@
+package models
+
+type Stat struct {
+	Users         int64 `json:"users"`
+	Admins        int64 `json:"admins"`
+	Categories    int64 `json:"categories"`
+	Finances      int64 `json:"finances"`
+	Savings       int64 `json:"savings"`
+	DetailSavings int64 `json:"detail_savings"`
+}
@@ -6,12 +6,22 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID        	uint           	`json:"id" gorm:"primaryKey"`
 	Name     	string 			`json:"name" form:"name"`
-	Email    	string 			`json:"email" form:"email"`
-	Password 	string 			`json:"password" form:"password"`
+	Email    	string 			`json:"email" form:"email" gorm:"size:255;uniqueIndex"`
+	Password 	string 			`json:"-" form:"password"`
+	Role 		string 			`json:"role" form:"role" gorm:"type:varchar(10);default:user"`
 	Exp 		int 			`json:"exp" form:"exp" gorm:"null"`
+	PendingEmail 				string 		`json:"-"`
+	EmailVerificationToken 		string 		`json:"-" gorm:"index"`
+	EmailVerificationExpiresAt 	*time.Time 	`json:"-"`
+	TokensRevokedAt 			*time.Time 	`json:"-"`
 	CreatedAt 	time.Time      	`json:"created_at"`
 	UpdatedAt 	time.Time      	`json:"updated_at"`
 	DeletedAt 	gorm.DeletedAt 	`json:"deleted_at" gorm:"index"`
@@ -23,14 +33,64 @@ type UserInput struct {
 	Password string `json:"password" form:"password" validate:"required,min=5"`
 }
 
+type UserUpdate struct {
+	Name     string `json:"name" form:"name" validate:"omitempty,min=1"`
+}
+
+type UserChangePassword struct {
+	CurrentPassword string `json:"current_password" form:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" form:"new_password" validate:"required,min=5"`
+}
+
+type UserChangeEmail struct {
+	Email    string `json:"email" form:"email" validate:"required,email"`
+	Password string `json:"password" form:"password" validate:"required"`
+}
+
+type UserVerifyEmail struct {
+	Token string `json:"token" form:"token" validate:"required"`
+}
+
+type UserRole struct {
+	Role string `json:"role" form:"role" validate:"required,oneof=user admin"`
+}
+
+type UserDeleteAccount struct {
+	Password string `json:"password" form:"password" validate:"required"`
+}
+
+// UserExport is everything stored in a user's personal ledger.
+type UserExport struct {
+	User           User
+	Categories     []Category
+	Finances       []Finance
+	Savings        []Saving
+	DetailSavings  []DetailSaving
+	Debts          []Debt
+	DebtRepayments []DebtRepayment
+	Bills          []Bill
+	GoldSavings    []GoldSaving
+}
+
 type UserAuth struct {
 	Email    string `json:"email" form:"email" validate:"required,email"`
 	Password string `json:"password" form:"password" validate:"required,min=5"`
 }
 
+// UserResponse is a user as shown to clients, Token is only set on login.
 type UserResponse struct {
 	ID 		uint 	`json:"id" form:"id"`
 	Name    string 	`json:"name" form:"name"`
 	Email   string 	`json:"email" form:"email"`
-	Token 	string 	`json:"token" form:"token"`
+	Role 	string 	`json:"role" form:"role"`
+	Token 	string 	`json:"token,omitempty" form:"token"`
+}
+
+func NewUserResponse(user User) UserResponse {
+	return UserResponse{
+		ID:    user.ID,
+		Name:  user.Name,
+		Email: user.Email,
+		Role:  user.Role,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	WebhookEventFinanceCreated    = "finance.created"
+	WebhookEventFinanceUpdated    = "finance.updated"
+	WebhookEventFinanceDeleted    = "finance.deleted"
+	WebhookEventSavingCreated     = "saving.created"
+	WebhookEventSavingGoalReached = "saving.goal_reached"
+	WebhookEventDebtCreated       = "debt.created"
+	WebhookEventDebtSettled       = "debt.settled"
+	WebhookEventBillPaid          = "bill.paid"
+	WebhookEventTest              = "webhook.test"
+)
+
+// WebhookEvents are the event types a webhook can subscribe to. There is no
+// budget.exceeded event, the app has no budgets that could be exceeded.
+var WebhookEvents = []string{
+	WebhookEventFinanceCreated,
+	WebhookEventFinanceUpdated,
+	WebhookEventFinanceDeleted,
+	WebhookEventSavingCreated,
+	WebhookEventSavingGoalReached,
+	WebhookEventDebtCreated,
+	WebhookEventDebtSettled,
+	WebhookEventBillPaid,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookMaxAttempts is how often a delivery is tried before it is given up.
+const WebhookMaxAttempts = 8
+
+// Webhook is an endpoint registered by a user that receives a signed POST
+// request for every subscribed event in the ledgers the user is a member of.
+type Webhook struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	URL         string         `json:"url" form:"url"`
+	Description string         `json:"description" form:"description"`
+	Secret      string         `json:"-"`
+	Events      []string       `json:"events" form:"events" gorm:"serializer:json"`
+	Active      bool           `json:"active" gorm:"default:true"`
+	UserID      uint           `json:"user_id" gorm:"index"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// WebhookDelivery is a queued event for a webhook together with the result
+// of the last attempt to deliver it.
+type WebhookDelivery struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	WebhookID      uint            `json:"webhook_id" gorm:"index"`
+	Webhook        Webhook         `json:"-" gorm:"foreignKey:WebhookID"`
+	Event          string          `json:"event" gorm:"size:50"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:text"`
+	Status         string          `json:"status" gorm:"size:10;index"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at" gorm:"index"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus int             `json:"response_status"`
+	Error          string          `json:"error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// WebhookPayload is the body posted to a webhook.
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookInput struct {
+	URL         string   `json:"url" form:"url" validate:"required,url,startswith=http"`
+	Description string   `json:"description" form:"description"`
+	Events      []string `json:"events" form:"events" validate:"required,min=1"`
+	Active      *bool    `json:"active" form:"active"`
+}
+
+// WebhookResponse is returned once when a webhook is created, it is the only
+// time the signing secret is shown.
+type WebhookResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// Subscribed reports whether the webhook wants to receive the event.
+func (w Webhook) Subscribed(event string) bool {
+	for _, subscribed := range w.Events {
+		if subscribed == event || subscribed == "*" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency. Exponent is the number of digits of the
+// minor unit, amounts are stored as integers of that unit.
+type Currency struct {
+	Code     string
+	Exponent int
+	Symbol   string
+	// Locale formats the amounts when no locale is asked for
+	Locale string
+}
+
+var currencies = map[string]Currency{
+	"IDR": {Code: "IDR", Exponent: 2, Symbol: "Rp", Locale: "id-ID"},
+	"USD": {Code: "USD", Exponent: 2, Symbol: "$", Locale: "en-US"},
+	"EUR": {Code: "EUR", Exponent: 2, Symbol: "€", Locale: "de-DE"},
+	"GBP": {Code: "GBP", Exponent: 2, Symbol: "£", Locale: "en-GB"},
+	"SGD": {Code: "SGD", Exponent: 2, Symbol: "S$", Locale: "en-SG"},
+	"MYR": {Code: "MYR", Exponent: 2, Symbol: "RM", Locale: "ms-MY"},
+	"AUD": {Code: "AUD", Exponent: 2, Symbol: "A$", Locale: "en-AU"},
+	"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥", Locale: "ja-JP"},
+	"KWD": {Code: "KWD", Exponent: 3, Symbol: "KD", Locale: "en-KW"},
+}
+
+var defaultCurrency string = "IDR"
+
+// LookupCurrency finds a supported currency by its code.
+func LookupCurrency(code string) (Currency, error) {
+	currency, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+
+	return currency, nil
+}
+
+// SetDefaultCurrency sets the currency of amounts given without one. It is
+// called once at startup.
+func SetDefaultCurrency(code string) error {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return err
+	}
+
+	defaultCurrency = currency.Code
+
+	return nil
+}
+
+// DefaultCurrency is the currency of amounts given without one.
+func DefaultCurrency() string {
+	return defaultCurrency
+}
+
+// scale is 10 to the power of the exponent of the currency.
+func (c Currency) scale() int64 {
+	scale := int64(1)
+	for i := 0; i < c.Exponent; i++ {
+		scale *= 10
+	}
+
+	return scale
+}
@@ -0,0 +1,85 @@
+package money
+
+import (
+	"strings"
+)
+
+// Locale is how a locale writes amounts of money.
+type Locale struct {
+	Thousand string
+	Decimal  string
+	// Pattern places the symbol (%s) and the number (%v)
+	Pattern string
+}
+
+var locales = map[string]Locale{
+	"id-ID": {Thousand: ".", Decimal: ",", Pattern: "%s%v"},
+	"en-US": {Thousand: ",", Decimal: ".", Pattern: "%s%v"},
+	"en-GB": {Thousand: ",", Decimal: ".", Pattern: "%s%v"},
+	"en-SG": {Thousand: ",", Decimal: ".", Pattern: "%s%v"},
+	"en-AU": {Thousand: ",", Decimal: ".", Pattern: "%s%v"},
+	"ms-MY": {Thousand: ",", Decimal: ".", Pattern: "%s%v"},
+	"de-DE": {Thousand: ".", Decimal: ",", Pattern: "%v %s"},
+	"fr-FR": {Thousand: " ", Decimal: ",", Pattern: "%v %s"},
+	"ja-JP": {Thousand: ",", Decimal: ".", Pattern: "%s%v"},
+	"en-KW": {Thousand: ",", Decimal: ".", Pattern: "%s %v"},
+}
+
+// LookupLocale finds the locale by its tag, like "id-ID" or "id". Unknown
+// tags give false.
+func LookupLocale(tag string) (Locale, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+
+	for name, locale := range locales {
+		if strings.EqualFold(name, tag) {
+			return locale, true
+		}
+	}
+
+	// only the language was given, take the first region of it
+	for name, locale := range locales {
+		if language, _, _ := strings.Cut(name, "-"); tag != "" && strings.EqualFold(language, tag) {
+			return locale, true
+		}
+	}
+
+	return Locale{}, false
+}
+
+// Format writes the amount for people reading the given locale, like
+// "Rp1.500,25" for id-ID. An unknown or empty locale uses the usual locale
+// of the currency.
+func (m Money) Format(tag string) string {
+	c, err := LookupCurrency(m.currency(m))
+	if err != nil {
+		return m.String()
+	}
+
+	locale, ok := LookupLocale(tag)
+	if !ok {
+		locale = locales[c.Locale]
+	}
+
+	whole, fraction := m.split(c)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(locale.Thousand)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	number := grouped.String()
+	if c.Exponent > 0 {
+		number += locale.Decimal + fraction
+	}
+
+	formatted := strings.Replace(strings.Replace(locale.Pattern, "%s", c.Symbol, 1), "%v", number, 1)
+
+	if m.Minor < 0 {
+		return "-" + formatted
+	}
+
+	return formatted
+}
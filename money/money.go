@@ -0,0 +1,354 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrOverflow         = errors.New("amount is out of range")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Money is an amount of a currency kept as an integer of its minor unit, so
+// 1500.25 IDR is 150025. Models embed it with a column prefix, the amount is
+// stored as a BIGINT and the currency as its code.
+type Money struct {
+	Minor    int64  `gorm:"not null;default:0"`
+	Currency string `gorm:"size:3"`
+}
+
+// New is an amount given in minor units.
+func New(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: strings.ToUpper(currency)}
+}
+
+// Zero is no money in the currency.
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// FromMajor is an amount given in whole units of the currency.
+func FromMajor(major int64, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	minor, err := mul(major, c.scale())
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Minor: minor, Currency: c.Code}, nil
+}
+
+// FromFloat rounds an amount computed in floating point, like a price
+// converted with an exchange rate, to the minor unit.
+func FromFloat(amount float64, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	minor := math.Round(amount * float64(c.scale()))
+	if math.IsNaN(minor) || minor >= math.MaxInt64 || minor <= math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Minor: int64(minor), Currency: c.Code}, nil
+}
+
+// Parse reads a decimal amount like "-1500.25". More decimals than the
+// currency has are refused instead of being rounded.
+func Parse(amount, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount = strings.TrimSpace(amount)
+
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(strings.TrimPrefix(amount, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" || len(fraction) > c.Exponent || strings.ContainsAny(whole+fraction, "+-") {
+		return Money{}, fmt.Errorf("%w %q for %s", ErrInvalidAmount, amount, c.Code)
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	digits := whole + fraction + strings.Repeat("0", c.Exponent-len(fraction))
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return Money{}, ErrOverflow
+		}
+
+		return Money{}, fmt.Errorf("%w %q for %s", ErrInvalidAmount, amount, c.Code)
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Minor: minor, Currency: c.Code}, nil
+}
+
+// Add sums two amounts of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.Minor + other.Minor
+	if (sum > m.Minor) != (other.Minor > 0) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Minor: sum, Currency: m.currency(other)}, nil
+}
+
+// Sub subtracts an amount of the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Minor == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+
+	return m.Add(Money{Minor: -other.Minor, Currency: other.Currency})
+}
+
+// Mul multiplies the amount, for example by a quantity.
+func (m Money) Mul(n int64) (Money, error) {
+	product, err := mul(m.Minor, n)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Minor: product, Currency: m.Currency}, nil
+}
+
+// Cmp compares two amounts of the same currency, it is -1, 0 or 1 like
+// strings.Compare.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Minor < other.Minor:
+		return -1, nil
+	case m.Minor > other.Minor:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Minor < 0
+}
+
+// Float64 is the amount in whole units, for statistics only.
+func (m Money) Float64() float64 {
+	c, err := LookupCurrency(m.currency(m))
+	if err != nil {
+		return float64(m.Minor)
+	}
+
+	return float64(m.Minor) / float64(c.scale())
+}
+
+// Decimal writes the amount in whole units with every decimal of the
+// currency, like "-1500.25".
+func (m Money) Decimal() string {
+	c, err := LookupCurrency(m.currency(m))
+	if err != nil {
+		return strconv.FormatInt(m.Minor, 10)
+	}
+
+	whole, fraction := m.split(c)
+
+	sign := ""
+	if m.Minor < 0 {
+		sign = "-"
+	}
+
+	if c.Exponent == 0 {
+		return sign + whole
+	}
+
+	return sign + whole + "." + fraction
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency(m)
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON writes {"amount":"1500.25","currency":"IDR"}, the amount is
+// a string so no client reads it as a float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{Amount: m.Decimal(), Currency: m.currency(m)})
+}
+
+// UnmarshalJSON reads the object written by MarshalJSON, where the amount
+// may also be a number. A bare number or string is an amount in the default
+// currency, like the plain integers older clients and backups send.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var object moneyJSON
+
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+	} else {
+		object.Amount = data
+	}
+
+	if object.Currency == "" {
+		object.Currency = defaultCurrency
+	}
+
+	amount := strings.Trim(string(object.Amount), `"`)
+
+	parsed, err := Parse(amount, object.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+// UnmarshalParam reads an amount in the default currency from a form or
+// query parameter.
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := Parse(param, defaultCurrency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+// currency is the code of the amount, zero values have no currency yet and
+// take the one of the other amount or the default.
+func (m Money) currency(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+
+	if other.Currency != "" {
+		return other.Currency
+	}
+
+	return defaultCurrency
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != "" && other.Currency != "" && m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	return nil
+}
+
+// split is the absolute amount in whole units and the padded decimals.
+func (m Money) split(c Currency) (string, string) {
+	minor := m.Minor
+
+	var abs uint64
+	if minor < 0 {
+		abs = uint64(-(minor + 1)) + 1
+	} else {
+		abs = uint64(minor)
+	}
+
+	scale := uint64(c.scale())
+	whole := strconv.FormatUint(abs/scale, 10)
+	fraction := strconv.FormatUint(abs%scale, 10)
+
+	if c.Exponent > 0 {
+		fraction = strings.Repeat("0", c.Exponent-len(fraction)) + fraction
+	}
+
+	return whole, fraction
+}
+
+// mul multiplies two integers and reports an overflow.
+func mul(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+
+	negative := (a < 0) != (b < 0)
+
+	ua, ub := uint64(a), uint64(b)
+	if a < 0 {
+		ua = uint64(-(a + 1)) + 1
+	}
+	if b < 0 {
+		ub = uint64(-(b + 1)) + 1
+	}
+
+	hi, lo := bits.Mul64(ua, ub)
+	if hi != 0 || (!negative && lo > math.MaxInt64) || (negative && lo > uint64(math.MaxInt64)+1) {
+		return 0, ErrOverflow
+	}
+
+	if negative {
+		return -int64(lo-1) - 1, nil
+	}
+
+	return int64(lo), nil
+}
+
+// Totals sums amounts of several currencies, one entry per currency.
+type Totals []Money
+
+// Add sums the amount into the entry of its currency.
+func (t Totals) Add(m Money) (Totals, error) {
+	for i, total := range t {
+		if total.currency(m) == m.currency(total) {
+			sum, err := total.Add(m)
+			if err != nil {
+				return nil, err
+			}
+
+			t[i] = sum
+			return t, nil
+		}
+	}
+
+	return append(t, New(m.Minor, m.currency(m))), nil
+}
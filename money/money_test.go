@@ -0,0 +1,272 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdd_Success(t *testing.T) {
+	sum, err := New(150025, "IDR").Add(New(-25, "IDR"))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, New(150000, "IDR"), sum)
+	}
+
+	// a zero value takes the currency of the other amount
+	sum, err = Money{}.Add(New(100, "USD"))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, New(100, "USD"), sum)
+	}
+
+	sum, err = New(math.MaxInt64-1, "IDR").Add(New(1, "IDR"))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(math.MaxInt64), sum.Minor)
+	}
+}
+
+func TestAdd_Failed(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		a, b  Money
+		error error
+	}{
+		{"overflow", New(math.MaxInt64, "IDR"), New(1, "IDR"), ErrOverflow},
+		{"underflow", New(math.MinInt64, "IDR"), New(-1, "IDR"), ErrOverflow},
+		{"currency mismatch", New(100, "IDR"), New(100, "USD"), ErrCurrencyMismatch},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			_, err := testcase.a.Add(testcase.b)
+
+			assert.ErrorIs(t, err, testcase.error)
+		})
+	}
+}
+
+func TestSub_Success(t *testing.T) {
+	difference, err := New(100, "IDR").Sub(New(250, "IDR"))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, New(-150, "IDR"), difference)
+	}
+
+	difference, err = New(-1, "IDR").Sub(New(math.MaxInt64, "IDR"))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(math.MinInt64), difference.Minor)
+	}
+}
+
+func TestSub_Failed(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		a, b  Money
+		error error
+	}{
+		{"overflow", New(math.MaxInt64, "IDR"), New(-1, "IDR"), ErrOverflow},
+		{"underflow", New(math.MinInt64, "IDR"), New(1, "IDR"), ErrOverflow},
+		// the negation of the smallest amount does not fit
+		{"smallest amount", New(0, "IDR"), New(math.MinInt64, "IDR"), ErrOverflow},
+		{"currency mismatch", New(100, "EUR"), New(100, "USD"), ErrCurrencyMismatch},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			_, err := testcase.a.Sub(testcase.b)
+
+			assert.ErrorIs(t, err, testcase.error)
+		})
+	}
+}
+
+func TestMul_Success(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		m     Money
+		n     int64
+		minor int64
+	}{
+		{"quantity", New(150025, "IDR"), 3, 450075},
+		{"negative", New(150025, "IDR"), -2, -300050},
+		{"zero", New(math.MaxInt64, "IDR"), 0, 0},
+		{"smallest amount", New(math.MinInt64/2, "IDR"), 2, math.MinInt64},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			product, err := testcase.m.Mul(testcase.n)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, New(testcase.minor, "IDR"), product)
+			}
+		})
+	}
+}
+
+func TestMul_Failed(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		m    Money
+		n    int64
+	}{
+		{"overflow", New(math.MaxInt64/2+1, "IDR"), 2},
+		{"underflow", New(math.MinInt64/2-1, "IDR"), 2},
+		{"negative overflow", New(math.MinInt64, "IDR"), -1},
+		{"large factors", New(1<<40, "IDR"), 1 << 40},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			_, err := testcase.m.Mul(testcase.n)
+
+			assert.ErrorIs(t, err, ErrOverflow)
+		})
+	}
+}
+
+func TestCmp_Failed(t *testing.T) {
+	_, err := New(100, "IDR").Cmp(New(100, "JPY"))
+
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestParse_Success(t *testing.T) {
+	for _, testcase := range []struct {
+		amount   string
+		currency string
+		minor    int64
+		decimal  string
+	}{
+		{"1500.25", "IDR", 150025, "1500.25"},
+		{"-1500.25", "IDR", -150025, "-1500.25"},
+		{"+7", "IDR", 700, "7.00"},
+		{".5", "USD", 50, "0.50"},
+		{"-0.05", "usd", -5, "-0.05"},
+		{" 12 ", "EUR", 1200, "12.00"},
+		{"0", "IDR", 0, "0.00"},
+		{"1500", "JPY", 1500, "1500"},
+		{"-1500", "JPY", -1500, "-1500"},
+		{"1.234", "KWD", 1234, "1.234"},
+		{"-0.001", "KWD", -1, "-0.001"},
+		{"2.5", "KWD", 2500, "2.500"},
+		{"92233720368547758.07", "IDR", math.MaxInt64, "92233720368547758.07"},
+	} {
+		t.Run(testcase.amount+" "+testcase.currency, func(t *testing.T) {
+			parsed, err := Parse(testcase.amount, testcase.currency)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, testcase.minor, parsed.Minor)
+			assert.Equal(t, testcase.decimal, parsed.Decimal())
+
+			// what Decimal writes is read back to the same amount
+			again, err := Parse(parsed.Decimal(), parsed.Currency)
+			if assert.NoError(t, err) {
+				assert.Equal(t, parsed, again)
+			}
+		})
+	}
+}
+
+func TestParse_Failed(t *testing.T) {
+	for _, testcase := range []struct {
+		amount   string
+		currency string
+		error    error
+	}{
+		{"", "IDR", ErrInvalidAmount},
+		{"-", "IDR", ErrInvalidAmount},
+		{".", "IDR", ErrInvalidAmount},
+		{"abc", "IDR", ErrInvalidAmount},
+		{"1e5", "IDR", ErrInvalidAmount},
+		{"1,50", "IDR", ErrInvalidAmount},
+		{"--1", "IDR", ErrInvalidAmount},
+		{"1.-5", "IDR", ErrInvalidAmount},
+		{"1.2.3", "KWD", ErrInvalidAmount},
+		// more decimals than the currency has are not rounded
+		{"1.005", "IDR", ErrInvalidAmount},
+		{"1.5", "JPY", ErrInvalidAmount},
+		{"1.2345", "KWD", ErrInvalidAmount},
+		{"92233720368547758.08", "IDR", ErrOverflow},
+		{"1", "XXX", ErrUnknownCurrency},
+	} {
+		t.Run(testcase.amount+" "+testcase.currency, func(t *testing.T) {
+			_, err := Parse(testcase.amount, testcase.currency)
+
+			assert.ErrorIs(t, err, testcase.error)
+		})
+	}
+}
+
+func TestFormat_Success(t *testing.T) {
+	for _, testcase := range []struct {
+		m         Money
+		locale    string
+		formatted string
+	}{
+		{New(150025, "IDR"), "id-ID", "Rp1.500,25"},
+		{New(-150025, "IDR"), "", "-Rp1.500,25"},
+		{New(123456789, "USD"), "en-US", "$1,234,567.89"},
+		{New(123456789, "EUR"), "de", "1.234.567,89 €"},
+		{New(0, "USD"), "en-US", "$0.00"},
+		{New(1234567, "JPY"), "", "¥1,234,567"},
+		{New(-1234567, "KWD"), "", "-KD 1,234.567"},
+		{New(math.MinInt64, "JPY"), "", "-¥9,223,372,036,854,775,808"},
+	} {
+		t.Run(testcase.formatted, func(t *testing.T) {
+			assert.Equal(t, testcase.formatted, testcase.m.Format(testcase.locale))
+		})
+	}
+}
+
+func TestJSON_Success(t *testing.T) {
+	for _, m := range []Money{New(-150025, "IDR"), New(1500, "JPY"), New(1, "KWD"), {}} {
+		data, err := json.Marshal(m)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		var decoded Money
+		if assert.NoError(t, json.Unmarshal(data, &decoded), string(data)) {
+			assert.Equal(t, m.Minor, decoded.Minor)
+			assert.Equal(t, m.currency(m), decoded.Currency)
+		}
+	}
+
+	// older clients send bare numbers in the default currency
+	var decoded Money
+	if assert.NoError(t, json.Unmarshal([]byte(`15000`), &decoded)) {
+		assert.Equal(t, New(1500000, DefaultCurrency()), decoded)
+	}
+}
+
+func TestJSON_Failed(t *testing.T) {
+	for _, data := range []string{
+		`{"amount":"1.5","currency":"JPY"}`,
+		`{"amount":"1","currency":"XXX"}`,
+		`{"amount":"one","currency":"IDR"}`,
+		`"1.005"`,
+	} {
+		var decoded Money
+
+		assert.Error(t, json.Unmarshal([]byte(data), &decoded), data)
+	}
+}
+
+func TestTotals_Success(t *testing.T) {
+	var totals Totals
+
+	for _, m := range []Money{New(100, "IDR"), New(5, "USD"), New(-30, "IDR")} {
+		var err error
+
+		totals, err = totals.Add(m)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	assert.Equal(t, Totals{New(70, "IDR"), New(5, "USD")}, totals)
+
+	_, err := totals.Add(New(math.MaxInt64, "USD"))
+
+	assert.ErrorIs(t, err, ErrOverflow)
+}
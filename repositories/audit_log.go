@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"keuangan-pribadi/models"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &AuditLogRepositoryImpl{db: db}
+}
+
+func (ar *AuditLogRepositoryImpl) GetAll(ctx context.Context, filter models.AuditLogFilter, userID uint) ([]models.AuditLog, error) {
+	db := ar.db.WithContext(ctx)
+
+	var auditLogs []models.AuditLog
+
+	query := db.Where("owner_id = ?", userID)
+
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Find(&auditLogs).Error; err != nil {
+		return nil, err
+	}
+
+	return auditLogs, nil
+}
+
+// recordAudit appends an entry to the audit log. before is nil for creates
+// and after is nil for deletes. It runs in the transaction of the change, so
+// a change is never saved without its entry.
+func recordAudit(tx *gorm.DB, meta models.AuditMeta, ownerID uint, action, entityType string, entityID uint, before, after interface{}) error {
+	beforeSnapshot := auditSnapshot(before)
+	afterSnapshot := auditSnapshot(after)
+
+	changes := auditDiff(beforeSnapshot, afterSnapshot)
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	var auditLog models.AuditLog = models.AuditLog{
+		ActorID:    meta.ActorID,
+		OwnerID:    ownerID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeSnapshot,
+		After:      afterSnapshot,
+		Changes:    changes,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+	}
+
+	return tx.Create(&auditLog).Error
+}
+
+// auditSnapshot converts an entity to a flat map of its JSON fields. Nested
+// associations, the objects with an id, timestamps and secrets are left out.
+func auditSnapshot(entity interface{}) map[string]interface{} {
+	if entity == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(entity)
+	if err != nil {
+		return nil
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil
+	}
+
+	for key, value := range snapshot {
+		if nested, ok := value.(map[string]interface{}); ok && nested["id"] != nil {
+			delete(snapshot, key)
+		}
+	}
+
+	delete(snapshot, "password")
+	delete(snapshot, "created_at")
+	delete(snapshot, "updated_at")
+	delete(snapshot, "deleted_at")
+
+	return snapshot
+}
+
+func auditDiff(before, after map[string]interface{}) map[string]models.AuditChange {
+	changes := map[string]models.AuditChange{}
+
+	for key, newValue := range after {
+		if oldValue, ok := before[key]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = models.AuditChange{Old: before[key], New: newValue}
+		}
+	}
+
+	for key, oldValue := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = models.AuditChange{Old: oldValue, New: nil}
+		}
+	}
+
+	return changes
+}
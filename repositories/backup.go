@@ -0,0 +1,271 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"keuangan-pribadi/models"
+
+	"gorm.io/gorm"
+)
+
+type BackupRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitBackupRepository(db *gorm.DB) BackupRepository {
+	return &BackupRepositoryImpl{db: db}
+}
+
+func (br *BackupRepositoryImpl) Create(ctx context.Context, userID uint) (models.Backup, error) {
+	db := br.db.WithContext(ctx)
+
+	export, err := (&UserRepositoryImpl{db: db}).Export(ctx, userID)
+	if err != nil {
+		return models.Backup{}, err
+	}
+
+	return models.NewBackup(export), nil
+}
+
+// Restore imports a backup inside a single transaction. New IDs are given to
+// every row and the references between them are remapped. Everything lands
+// in the personal ledger, in replace mode its current content is removed
+// first.
+func (br *BackupRepositoryImpl) Restore(ctx context.Context, backup models.Backup, mode string, userID uint, meta models.AuditMeta) (models.RestoreResult, error) {
+	db := br.db.WithContext(ctx)
+
+	result := models.RestoreResult{Mode: mode}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// restored data always lands in the personal ledger
+		ledger, err := PersonalLedger(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		// shared ledgers are not part of a backup and are left as they are
+		if mode == models.RestoreModeReplace {
+			// repayments point at the finances they were booked as
+			debts := tx.Unscoped().Model(&models.Debt{}).Select("id").Where("ledger_id = ?", ledger.ID)
+
+			if err := tx.Where("debt_id IN (?)", debts).Delete(&models.DebtRepayment{}).Error; err != nil {
+				return err
+			}
+
+			goldSavings := tx.Unscoped().Model(&models.GoldSaving{}).Select("id").Where("ledger_id = ?", ledger.ID)
+
+			if err := tx.Unscoped().Where("gold_saving_id IN (?)", goldSavings).Delete(&models.GoldDeposit{}).Error; err != nil {
+				return err
+			}
+
+			savings := tx.Unscoped().Model(&models.Saving{}).Select("id").Where("ledger_id = ?", ledger.ID)
+
+			if err := tx.Unscoped().Where("saving_id IN (?)", savings).Delete(&models.DetailSaving{}).Error; err != nil {
+				return err
+			}
+
+			for _, model := range []interface{}{&models.Debt{}, &models.Bill{}, &models.Finance{}, &models.Saving{}, &models.GoldSaving{}} {
+				if err := tx.Unscoped().Where("ledger_id = ?", ledger.ID).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// system categories are shared, they are matched by name and only
+		// created when no category with that name exists yet
+		categoryIDs := map[uint]uint{}
+		for _, backupCategory := range backup.Categories {
+			var category models.Category
+
+			err := tx.Where("name = ? AND (ledger_id IS NULL OR ledger_id = ?)", backupCategory.Name, ledger.ID).First(&category).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				category = models.Category{Name: backupCategory.Name, LedgerID: &ledger.ID}
+				if err := tx.Create(&category).Error; err != nil {
+					return err
+				}
+				result.Categories++
+			} else if err != nil {
+				return err
+			}
+
+			categoryIDs[backupCategory.ID] = category.ID
+		}
+
+		financeIDs := map[uint]uint{}
+		for _, backupFinance := range backup.Finances {
+			finance := models.Finance{
+				Name:       backupFinance.Name,
+				Type:       backupFinance.Type,
+				Money:      backupFinance.Money,
+				UserID:     userID,
+				CategoryID: categoryIDs[backupFinance.CategoryID],
+				LedgerID:   &ledger.ID,
+				CreatedAt:  backupFinance.CreatedAt,
+				UpdatedAt:  backupFinance.UpdatedAt,
+			}
+
+			if err := tx.Create(&finance).Error; err != nil {
+				return err
+			}
+			financeIDs[backupFinance.ID] = finance.ID
+			result.Finances++
+		}
+
+		savingIDs := map[uint]uint{}
+		for _, backupSaving := range backup.Savings {
+			saving := models.Saving{
+				Name:      backupSaving.Name,
+				Value:     backupSaving.Value,
+				Goal:      backupSaving.Goal,
+				UserID:    userID,
+				LedgerID:  &ledger.ID,
+				CreatedAt: backupSaving.CreatedAt,
+				UpdatedAt: backupSaving.UpdatedAt,
+			}
+
+			if err := tx.Create(&saving).Error; err != nil {
+				return err
+			}
+			savingIDs[backupSaving.ID] = saving.ID
+			result.Savings++
+		}
+
+		for _, backupDetailSaving := range backup.DetailSavings {
+			detailSaving := models.DetailSaving{
+				Value:     backupDetailSaving.Value,
+				Status:    backupDetailSaving.Status,
+				SavingID:  savingIDs[backupDetailSaving.SavingID],
+				UserID:    userID,
+				CreatedAt: backupDetailSaving.CreatedAt,
+				UpdatedAt: backupDetailSaving.UpdatedAt,
+			}
+
+			if err := tx.Create(&detailSaving).Error; err != nil {
+				return err
+			}
+			result.DetailSavings++
+		}
+
+		debtIDs := map[uint]uint{}
+		for _, backupDebt := range backup.Debts {
+			debt := models.Debt{
+				Counterparty: backupDebt.Counterparty,
+				Direction:    backupDebt.Direction,
+				Principal:    backupDebt.Principal,
+				Paid:         backupDebt.Paid,
+				Note:         backupDebt.Note,
+				DueDate:      backupDebt.DueDate,
+				SettledAt:    backupDebt.SettledAt,
+				UserID:       userID,
+				LedgerID:     &ledger.ID,
+				CreatedAt:    backupDebt.CreatedAt,
+				UpdatedAt:    backupDebt.UpdatedAt,
+			}
+
+			if err := tx.Create(&debt).Error; err != nil {
+				return err
+			}
+			debtIDs[backupDebt.ID] = debt.ID
+			result.Debts++
+		}
+
+		for _, backupRepayment := range backup.DebtRepayments {
+			repayment := models.DebtRepayment{
+				DebtID:    debtIDs[backupRepayment.DebtID],
+				Amount:    backupRepayment.Amount,
+				FinanceID: financeIDs[backupRepayment.FinanceID],
+				UserID:    userID,
+				CreatedAt: backupRepayment.CreatedAt,
+				UpdatedAt: backupRepayment.UpdatedAt,
+			}
+
+			if err := tx.Omit("Finance").Create(&repayment).Error; err != nil {
+				return err
+			}
+			result.DebtRepayments++
+		}
+
+		for _, backupBill := range backup.Bills {
+			bill := models.Bill{
+				Name:        backupBill.Name,
+				Amount:      backupBill.Amount,
+				DueDay:      backupBill.DueDay,
+				Recurrence:  backupBill.Recurrence,
+				LeadDays:    backupBill.LeadDays,
+				NextDueDate: backupBill.NextDueDate,
+				RemindedFor: backupBill.RemindedFor,
+				Active:      backupBill.Active,
+				CategoryID:  categoryIDs[backupBill.CategoryID],
+				UserID:      userID,
+				LedgerID:    &ledger.ID,
+				CreatedAt:   backupBill.CreatedAt,
+				UpdatedAt:   backupBill.UpdatedAt,
+			}
+
+			if err := tx.Omit("Category").Create(&bill).Error; err != nil {
+				return err
+			}
+
+			// the column defaults to true, so a paused bill is paused again
+			if !backupBill.Active {
+				if err := tx.Model(&bill).Update("active", false).Error; err != nil {
+					return err
+				}
+			}
+			result.Bills++
+		}
+
+		goldSavings := map[uint]*models.GoldSaving{}
+		for _, backupGoldSaving := range backup.GoldSavings {
+			goldSaving := models.GoldSaving{
+				Name:      backupGoldSaving.Name,
+				Goal:      backupGoldSaving.Goal,
+				UserID:    userID,
+				LedgerID:  &ledger.ID,
+				CreatedAt: backupGoldSaving.CreatedAt,
+				UpdatedAt: backupGoldSaving.UpdatedAt,
+			}
+
+			if err := tx.Create(&goldSaving).Error; err != nil {
+				return err
+			}
+			goldSavings[backupGoldSaving.ID] = &goldSaving
+			result.GoldSavings++
+		}
+
+		for _, backupDeposit := range backup.GoldDeposits {
+			deposit := models.GoldDeposit{
+				GoldSavingID: goldSavings[backupDeposit.GoldSavingID].ID,
+				Grams:        backupDeposit.Grams,
+				Price:        backupDeposit.Price,
+				PurchasedAt:  backupDeposit.PurchasedAt,
+				UserID:       userID,
+				CreatedAt:    backupDeposit.CreatedAt,
+				UpdatedAt:    backupDeposit.UpdatedAt,
+			}
+
+			if err := tx.Create(&deposit).Error; err != nil {
+				return err
+			}
+			result.GoldDeposits++
+		}
+
+		for _, goldSaving := range goldSavings {
+			if err := updateGoldTotals(tx, goldSaving); err != nil {
+				return err
+			}
+		}
+
+		meta.ActorID = userID
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "backup_restore", 0, nil, result)
+	})
+
+	if err != nil {
+		return models.RestoreResult{}, err
+	}
+
+	emitEvent(db, nil, models.EventBackupRestored, result)
+
+	return result, nil
+}
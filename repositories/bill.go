@@ -0,0 +1,330 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"keuangan-pribadi/models"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errBillClaimed rolls back a reminder another instance already sent.
+var errBillClaimed = errors.New("bill reminder already claimed")
+
+type BillRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitBillRepository(db *gorm.DB) BillRepository {
+	return &BillRepositoryImpl{db: db}
+}
+
+func (br *BillRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.Bill, error) {
+	db := br.db.WithContext(ctx)
+
+	var bills []models.Bill
+
+	if err := db.Preload("Category").Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).Order("next_due_date").Find(&bills).Error; err != nil {
+		return nil, err
+	}
+
+	return bills, nil
+}
+
+func (br *BillRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.Bill, error) {
+	db := br.db.WithContext(ctx)
+
+	var bill models.Bill
+
+	if err := db.Preload("Category").Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).First(&bill, "id = ?", id).Error; err != nil {
+		return models.Bill{}, notFound(err, "bill")
+	}
+
+	return bill, nil
+}
+
+func (br *BillRepositoryImpl) Create(ctx context.Context, billInput models.BillInput, userID uint, meta models.AuditMeta) (models.Bill, error) {
+	db := br.db.WithContext(ctx)
+
+	ledgerID, err := targetLedger(db, billInput.LedgerID, userID)
+	if err != nil {
+		return models.Bill{}, err
+	}
+
+	var category models.Category
+	if err := db.Where("id = ? AND (ledger_id IS NULL OR ledger_id = ?)", billInput.CategoryID, ledgerID).First(&category).Error; err != nil {
+		return models.Bill{}, notFound(err, "category")
+	}
+
+	var createdBill models.Bill = models.Bill{
+		Name:        billInput.Name,
+		Amount:      billInput.Amount,
+		DueDay:      billInput.DueDay,
+		Recurrence:  billInput.Recurrence,
+		LeadDays:    billInput.LeadDays,
+		NextDueDate: models.FirstDueDate(billInput.DueDay, time.Now()),
+		Active:      true,
+		CategoryID:  category.ID,
+		Category:    category,
+		UserID:      userID,
+		LedgerID:    &ledgerID,
+	}
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdBill).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "bill", createdBill.ID, nil, createdBill)
+	})
+
+	if err != nil {
+		return models.Bill{}, err
+	}
+
+	return createdBill, nil
+}
+
+func (br *BillRepositoryImpl) Update(ctx context.Context, billInput models.BillInput, id string, userID uint, meta models.AuditMeta) (models.Bill, error) {
+	db := br.db.WithContext(ctx)
+
+	bill, err := br.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.Bill{}, err
+	}
+
+	if err := requireLedgerWrite(db, bill.LedgerID, userID); err != nil {
+		return models.Bill{}, err
+	}
+
+	var category models.Category
+	if err := db.Where("id = ? AND (ledger_id IS NULL OR ledger_id = ?)", billInput.CategoryID, bill.LedgerID).First(&category).Error; err != nil {
+		return models.Bill{}, notFound(err, "category")
+	}
+
+	before := bill
+
+	// a different schedule starts over from today
+	if billInput.DueDay != bill.DueDay || billInput.Recurrence != bill.Recurrence {
+		bill.NextDueDate = models.FirstDueDate(billInput.DueDay, time.Now())
+		bill.RemindedFor = nil
+		bill.Active = true
+	}
+
+	bill.Name = billInput.Name
+	bill.Amount = billInput.Amount
+	bill.DueDay = billInput.DueDay
+	bill.Recurrence = billInput.Recurrence
+	bill.LeadDays = billInput.LeadDays
+	bill.CategoryID = category.ID
+	bill.Category = category
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&bill).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, bill.UserID, models.AuditActionUpdate, "bill", bill.ID, before, bill)
+	})
+
+	if err != nil {
+		return models.Bill{}, err
+	}
+
+	return bill, nil
+}
+
+func (br *BillRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := br.db.WithContext(ctx)
+
+	bill, err := br.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireLedgerWrite(db, bill.LedgerID, userID); err != nil {
+		return err
+	}
+
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&bill).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, bill.UserID, models.AuditActionDelete, "bill", bill.ID, bill, nil)
+	})
+}
+
+func (br *BillRepositoryImpl) Pay(ctx context.Context, paymentInput models.BillPayment, id string, userID uint, meta models.AuditMeta) (models.Finance, error) {
+	db := br.db.WithContext(ctx)
+
+	bill, err := br.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.Finance{}, err
+	}
+
+	if err := requireLedgerWrite(db, bill.LedgerID, userID); err != nil {
+		return models.Finance{}, err
+	}
+
+	if !bill.Active {
+		return models.Finance{}, models.ConflictError("bill_already_paid", fmt.Sprintf("%s is already paid", bill.Name))
+	}
+
+	before := bill
+
+	finance := models.Finance{
+		Name:       bill.Name,
+		Type:       2,
+		Money:      bill.Amount,
+		UserID:     userID,
+		CategoryID: bill.CategoryID,
+		LedgerID:   bill.LedgerID,
+	}
+
+	if !paymentInput.Money.IsZero() {
+		finance.Money = paymentInput.Money
+	}
+
+	if next, ok := bill.FollowingDueDate(); ok {
+		bill.NextDueDate = next
+	} else {
+		bill.Active = false
+	}
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&finance).Error; err != nil {
+			return err
+		}
+
+		// the period is only paid when nobody paid it in the meantime
+		result := tx.Model(&models.Bill{}).Where("id = ? AND next_due_date = ? AND active = ?", bill.ID, before.NextDueDate, true).Updates(map[string]interface{}{
+			"next_due_date": bill.NextDueDate,
+			"active":        bill.Active,
+		})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return models.ConflictError("bill_changed", "the bill was paid or changed by someone else, try again")
+		}
+
+		// reminders of the paid period are no longer relevant
+		if err := tx.Model(&models.Notification{}).
+			Where("entity_type = ? AND entity_id = ? AND read_at IS NULL", "bill", bill.ID).
+			Update("read_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, userID, models.AuditActionCreate, "finance", finance.ID, nil, finance); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, bill.UserID, models.AuditActionUpdate, "bill", bill.ID, before, bill)
+	})
+
+	if err != nil {
+		return models.Finance{}, err
+	}
+
+	emitEvent(db, finance.LedgerID, models.WebhookEventFinanceCreated, finance)
+	emitEvent(db, bill.LedgerID, models.WebhookEventBillPaid, bill)
+
+	return finance, nil
+}
+
+// GenerateReminders sends a notification to every member of the ledger of
+// each bill whose reminder is due and was not sent for the current period
+// yet, a bill without a ledger notifies its own user. It returns the number
+// of bills reminded about. Instances running it at the same time send every
+// reminder once.
+func (br *BillRepositoryImpl) GenerateReminders(ctx context.Context, now time.Time) (int, error) {
+	db := br.db.WithContext(ctx)
+
+	var bills []models.Bill
+
+	// lead times are at most 60 days, the exact moment is checked per bill.
+	// times are compared in UTC because SQLite compares them as text
+	if err := db.Where("active = ? AND next_due_date <= ? AND (reminded_for IS NULL OR reminded_for < next_due_date)", true, now.AddDate(0, 0, 60).UTC()).Find(&bills).Error; err != nil {
+		return 0, err
+	}
+
+	reminded := 0
+
+	for _, bill := range bills {
+		if now.Before(bill.RemindAt()) {
+			continue
+		}
+
+		memberIDs := []uint{bill.UserID}
+		if bill.LedgerID != nil {
+			if err := db.Model(&models.LedgerMember{}).Where("ledger_id = ?", *bill.LedgerID).Pluck("user_id", &memberIDs).Error; err != nil {
+				return reminded, err
+			}
+		}
+
+		message := fmt.Sprintf("%s of %s is due on %s.", bill.Name, bill.Amount.Format(""), bill.NextDueDate.Format("2006-01-02"))
+		if bill.NextDueDate.Before(now) {
+			message = fmt.Sprintf("%s of %s was due on %s.", bill.Name, bill.Amount.Format(""), bill.NextDueDate.Format("2006-01-02"))
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// the bill is claimed first, another instance reminding about the
+			// same period or a payment in the meantime leaves it alone
+			result := tx.Model(&models.Bill{}).
+				Where("id = ? AND next_due_date = ? AND (reminded_for IS NULL OR reminded_for < next_due_date)", bill.ID, bill.NextDueDate).
+				Update("reminded_for", bill.NextDueDate)
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return errBillClaimed
+			}
+
+			for _, memberID := range memberIDs {
+				notification := models.Notification{
+					UserID:     memberID,
+					Type:       models.NotificationTypeBillReminder,
+					Title:      fmt.Sprintf("%s is due soon", bill.Name),
+					Message:    message,
+					EntityType: "bill",
+					EntityID:   bill.ID,
+				}
+
+				if err := tx.Create(&notification).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		if errors.Is(err, errBillClaimed) {
+			continue
+		}
+
+		if err != nil {
+			slog.ErrorContext(db.Statement.Context, "error when sending the bill reminder", "bill_id", bill.ID, "error", err)
+			continue
+		}
+
+		reminded++
+	}
+
+	return reminded, nil
+}
@@ -1,20 +1,27 @@
 package repositories
 
 import (
-	"keuangan-pribadi/config"
+	"context"
 	"keuangan-pribadi/models"
+
+	"gorm.io/gorm"
 )
 
-type CategoryRepositoryImpl struct{}
+type CategoryRepositoryImpl struct {
+	db *gorm.DB
+}
 
-func InitCategoryRepository() CategoryRepository {
-	return &CategoryRepositoryImpl{}
+func InitCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &CategoryRepositoryImpl{db: db}
 }
 
-func (cr *CategoryRepositoryImpl) GetAll() ([]models.Category, error) {
+func (cr *CategoryRepositoryImpl) GetAll(ctx context.Context) ([]models.Category, error) {
+	db := cr.db.WithContext(ctx)
+
 	var categories []models.Category
 
-	err := config.DB.Find(&categories).Error
+	// ledger specific categories are listed through their ledger
+	err := db.Where("ledger_id IS NULL").Find(&categories).Error
 
 	if err != nil {
 		return nil, err
@@ -23,30 +30,38 @@ func (cr *CategoryRepositoryImpl) GetAll() ([]models.Category, error) {
 	return categories, nil
 }
 
-func (cr *CategoryRepositoryImpl) GetByID(id string) (models.Category, error) {
+func (cr *CategoryRepositoryImpl) GetByID(ctx context.Context, id string) (models.Category, error) {
+	db := cr.db.WithContext(ctx)
+
 	var category models.Category
 
-	err := config.DB.First(&category, "id = ?", id).Error
+	err := db.First(&category, "id = ? AND ledger_id IS NULL", id).Error
 
 	if err != nil {
-		return models.Category{}, err
+		return models.Category{}, notFound(err, "category")
 	}
 
 	return category, nil
 }
 
-func (cr *CategoryRepositoryImpl) Create(categoryInput models.CategoryInput) (models.Category, error) {
+func (cr *CategoryRepositoryImpl) Create(ctx context.Context, categoryInput models.CategoryInput, meta models.AuditMeta) (models.Category, error) {
+	db := cr.db.WithContext(ctx)
+
 	var createdCategory models.Category = models.Category{
 		Name:       categoryInput.Name,
 	}
 
-	result := config.DB.Create(&createdCategory)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdCategory).Error; err != nil {
+			return err
+		}
 
-	if err := result.Error; err != nil {
-		return models.Category{}, err
-	}
+		if err := tx.Last(&createdCategory).Error; err != nil {
+			return err
+		}
 
-	err := config.DB.Last(&createdCategory).Error
+		return recordAudit(tx, meta, 0, models.AuditActionCreate, "category", createdCategory.ID, nil, createdCategory)
+	})
 
 	if err != nil {
 		return models.Category{}, err
@@ -55,16 +70,26 @@ func (cr *CategoryRepositoryImpl) Create(categoryInput models.CategoryInput) (mo
 	return createdCategory, nil
 }
 
-func (cr *CategoryRepositoryImpl) Update(categoryInput models.CategoryInput, id string) (models.Category, error) {
-	category, err := cr.GetByID(id)
+func (cr *CategoryRepositoryImpl) Update(ctx context.Context, categoryInput models.CategoryInput, id string, meta models.AuditMeta) (models.Category, error) {
+	db := cr.db.WithContext(ctx)
+
+	category, err := cr.GetByID(ctx, id)
 
 	if err != nil {
 		return models.Category{}, err
 	}
 
+	before := category
+
 	category.Name = categoryInput.Name
 
-	err = config.DB.Save(&category).Error
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&category).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, 0, models.AuditActionUpdate, "category", category.ID, before, category)
+	})
 
 	if err != nil {
 		return models.Category{}, err
@@ -73,18 +98,20 @@ func (cr *CategoryRepositoryImpl) Update(categoryInput models.CategoryInput, id
 	return category, nil
 }
 
-func (cr *CategoryRepositoryImpl) Delete(id string) error {
-	category, err := cr.GetByID(id)
-
-	if err != nil {
-		return err
-	}
+func (cr *CategoryRepositoryImpl) Delete(ctx context.Context, id string, meta models.AuditMeta) error {
+	db := cr.db.WithContext(ctx)
 
-	err = config.DB.Delete(&category).Error
+	category, err := cr.GetByID(ctx, id)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&category).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, 0, models.AuditActionDelete, "category", category.ID, category, nil)
+	})
 }
\ No newline at end of file
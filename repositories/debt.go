@@ -0,0 +1,377 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DebtRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitDebtRepository(db *gorm.DB) DebtRepository {
+	return &DebtRepositoryImpl{db: db}
+}
+
+func (dr *DebtRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.Debt, error) {
+	db := dr.db.WithContext(ctx)
+
+	var debts []models.Debt
+
+	if err := db.Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).Order("due_date").Find(&debts).Error; err != nil {
+		return nil, err
+	}
+
+	return debts, nil
+}
+
+func (dr *DebtRepositoryImpl) GetOverdue(ctx context.Context, userID uint) ([]models.Debt, error) {
+	db := dr.db.WithContext(ctx)
+
+	var debts []models.Debt
+
+	if err := db.Where("ledger_id IN (?) AND settled_at IS NULL AND due_date < ?", memberLedgerIDs(db, userID), time.Now().UTC()).Order("due_date").Find(&debts).Error; err != nil {
+		return nil, err
+	}
+
+	return debts, nil
+}
+
+func (dr *DebtRepositoryImpl) GetSummary(ctx context.Context, userID uint) (models.DebtSummary, error) {
+	db := dr.db.WithContext(ctx)
+
+	var debts []models.Debt
+
+	if err := db.Where("ledger_id IN (?) AND settled_at IS NULL", memberLedgerIDs(db, userID)).Find(&debts).Error; err != nil {
+		return models.DebtSummary{}, err
+	}
+
+	var err error
+
+	summary := models.DebtSummary{Counterparties: []models.DebtCounterpartySummary{}}
+	counterparties := map[string]*models.DebtCounterpartySummary{}
+
+	for _, debt := range debts {
+		counterparty, ok := counterparties[debt.Counterparty]
+		if !ok {
+			counterparty = &models.DebtCounterpartySummary{Counterparty: debt.Counterparty}
+			counterparties[debt.Counterparty] = counterparty
+		}
+
+		// what is owed to the user counts positive in the net balance
+		net := debt.Outstanding
+		if debt.Direction == models.DebtDirectionPayable {
+			net = money.Zero(debt.Outstanding.Currency)
+			if net, err = net.Sub(debt.Outstanding); err != nil {
+				return models.DebtSummary{}, err
+			}
+
+			if counterparty.Payable, err = counterparty.Payable.Add(debt.Outstanding); err != nil {
+				return models.DebtSummary{}, err
+			}
+			if summary.Payable, err = summary.Payable.Add(debt.Outstanding); err != nil {
+				return models.DebtSummary{}, err
+			}
+		} else {
+			if counterparty.Receivable, err = counterparty.Receivable.Add(debt.Outstanding); err != nil {
+				return models.DebtSummary{}, err
+			}
+			if summary.Receivable, err = summary.Receivable.Add(debt.Outstanding); err != nil {
+				return models.DebtSummary{}, err
+			}
+		}
+
+		if counterparty.Net, err = counterparty.Net.Add(net); err != nil {
+			return models.DebtSummary{}, err
+		}
+		if summary.Net, err = summary.Net.Add(net); err != nil {
+			return models.DebtSummary{}, err
+		}
+	}
+
+	for _, counterparty := range counterparties {
+		summary.Counterparties = append(summary.Counterparties, *counterparty)
+	}
+
+	sort.Slice(summary.Counterparties, func(i, j int) bool {
+		return summary.Counterparties[i].Counterparty < summary.Counterparties[j].Counterparty
+	})
+
+	return summary, nil
+}
+
+func (dr *DebtRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.Debt, error) {
+	db := dr.db.WithContext(ctx)
+
+	var debt models.Debt
+
+	if err := db.Preload("Repayments.Finance").Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).First(&debt, "id = ?", id).Error; err != nil {
+		return models.Debt{}, notFound(err, "debt")
+	}
+
+	return debt, nil
+}
+
+func (dr *DebtRepositoryImpl) Create(ctx context.Context, debtInput models.DebtInput, userID uint, meta models.AuditMeta) (models.Debt, error) {
+	db := dr.db.WithContext(ctx)
+
+	ledgerID, err := targetLedger(db, debtInput.LedgerID, userID)
+	if err != nil {
+		return models.Debt{}, err
+	}
+
+	var createdDebt models.Debt = models.Debt{
+		Counterparty: debtInput.Counterparty,
+		Direction:    debtInput.Direction,
+		Principal:    debtInput.Principal,
+		Paid:         money.Zero(debtInput.Principal.Currency),
+		Outstanding:  debtInput.Principal,
+		Note:         debtInput.Note,
+		DueDate:      debtInput.DueDate,
+		UserID:       userID,
+		LedgerID:     &ledgerID,
+	}
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdDebt).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "debt", createdDebt.ID, nil, createdDebt)
+	})
+
+	if err != nil {
+		return models.Debt{}, err
+	}
+
+	emitEvent(db, createdDebt.LedgerID, models.WebhookEventDebtCreated, createdDebt)
+
+	return createdDebt, nil
+}
+
+func (dr *DebtRepositoryImpl) Update(ctx context.Context, debtInput models.DebtInput, id string, userID uint, meta models.AuditMeta) (models.Debt, error) {
+	db := dr.db.WithContext(ctx)
+
+	debt, err := dr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.Debt{}, err
+	}
+
+	// repayments are audited on their own
+	debt.Repayments = nil
+
+	if err := requireLedgerWrite(db, debt.LedgerID, userID); err != nil {
+		return models.Debt{}, err
+	}
+
+	outstanding, err := debtInput.Principal.Sub(debt.Paid)
+	if err != nil {
+		return models.Debt{}, err
+	}
+
+	if outstanding.IsNegative() {
+		return models.Debt{}, models.ValidationError("principal_below_paid", fmt.Sprintf("principal cannot be lower than the %s already repaid", debt.Paid.Format("")))
+	}
+
+	before := debt
+
+	debt.Counterparty = debtInput.Counterparty
+	debt.Direction = debtInput.Direction
+	debt.Principal = debtInput.Principal
+	debt.Outstanding = outstanding
+	debt.Note = debtInput.Note
+	debt.DueDate = debtInput.DueDate
+	debt.SettledAt = settledAt(debt)
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// the paid amount is left to Repay, the debt is only changed when
+		// nobody repaid it in the meantime
+		result := tx.Model(&models.Debt{}).Where("id = ? AND paid_minor = ?", debt.ID, before.Paid.Minor).Updates(map[string]interface{}{
+			"counterparty":       debt.Counterparty,
+			"direction":          debt.Direction,
+			"principal_minor":    debt.Principal.Minor,
+			"principal_currency": debt.Principal.Currency,
+			"note":               debt.Note,
+			"due_date":           debt.DueDate,
+			"settled_at":         debt.SettledAt,
+		})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return models.ConflictError("debt_changed", "the debt was changed by someone else, try again")
+		}
+
+		return recordAudit(tx, meta, debt.UserID, models.AuditActionUpdate, "debt", debt.ID, before, debt)
+	})
+
+	if err != nil {
+		return models.Debt{}, err
+	}
+
+	return debt, nil
+}
+
+func (dr *DebtRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := dr.db.WithContext(ctx)
+
+	debt, err := dr.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	// repayments are audited on their own
+	debt.Repayments = nil
+
+	if err := requireLedgerWrite(db, debt.LedgerID, userID); err != nil {
+		return err
+	}
+
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// the finance entries of the repayments stay, the money did move
+		if err := tx.Delete(&debt).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, debt.UserID, models.AuditActionDelete, "debt", debt.ID, debt, nil)
+	})
+}
+
+func (dr *DebtRepositoryImpl) Repay(ctx context.Context, repaymentInput models.DebtRepaymentInput, id string, userID uint, meta models.AuditMeta) (models.DebtRepayment, error) {
+	db := dr.db.WithContext(ctx)
+
+	debt, err := dr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.DebtRepayment{}, err
+	}
+
+	// repayments are audited on their own
+	debt.Repayments = nil
+
+	if err := requireLedgerWrite(db, debt.LedgerID, userID); err != nil {
+		return models.DebtRepayment{}, err
+	}
+
+	if cmp, err := repaymentInput.Amount.Cmp(debt.Outstanding); err != nil {
+		return models.DebtRepayment{}, err
+	} else if cmp > 0 {
+		return models.DebtRepayment{}, models.ValidationError("amount_exceeds_outstanding", fmt.Sprintf("amount exceeds the outstanding balance of %s", debt.Outstanding.Format("")))
+	}
+
+	var category models.Category
+	if err := db.Where("id = ? AND (ledger_id IS NULL OR ledger_id = ?)", repaymentInput.CategoryID, debt.LedgerID).First(&category).Error; err != nil {
+		return models.DebtRepayment{}, notFound(err, "category")
+	}
+
+	before := debt
+
+	finance := models.Finance{
+		Name:       fmt.Sprintf("Repayment from %s", debt.Counterparty),
+		Type:       1,
+		Money:      repaymentInput.Amount,
+		UserID:     userID,
+		CategoryID: category.ID,
+		LedgerID:   debt.LedgerID,
+	}
+
+	if debt.Direction == models.DebtDirectionPayable {
+		finance.Name = fmt.Sprintf("Repayment to %s", debt.Counterparty)
+		finance.Type = 2
+	}
+
+	var repayment models.DebtRepayment
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&finance).Error; err != nil {
+			return err
+		}
+
+		repayment = models.DebtRepayment{
+			DebtID:    debt.ID,
+			Amount:    repaymentInput.Amount,
+			FinanceID: finance.ID,
+			UserID:    userID,
+		}
+
+		if err := tx.Create(&repayment).Error; err != nil {
+			return err
+		}
+
+		if debt.Paid, err = debt.Paid.Add(repaymentInput.Amount); err != nil {
+			return err
+		}
+		if debt.Outstanding, err = debt.Principal.Sub(debt.Paid); err != nil {
+			return err
+		}
+		debt.SettledAt = settledAt(debt)
+
+		// the balance is only changed when nobody repaid in the meantime
+		result := tx.Model(&models.Debt{}).Where("id = ? AND paid_minor = ?", debt.ID, before.Paid.Minor).Updates(map[string]interface{}{
+			"paid_minor":    debt.Paid.Minor,
+			"paid_currency": debt.Paid.Currency,
+			"settled_at":    debt.SettledAt,
+		})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return models.ConflictError("debt_changed", "the debt was changed by someone else, try again")
+		}
+
+		if err := recordAudit(tx, meta, userID, models.AuditActionCreate, "finance", finance.ID, nil, finance); err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, userID, models.AuditActionCreate, "debt_repayment", repayment.ID, nil, repayment); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, debt.UserID, models.AuditActionUpdate, "debt", debt.ID, before, debt)
+	})
+
+	if err != nil {
+		return models.DebtRepayment{}, err
+	}
+
+	repayment.Finance = finance
+
+	emitEvent(db, finance.LedgerID, models.WebhookEventFinanceCreated, finance)
+
+	if before.SettledAt == nil && debt.SettledAt != nil {
+		emitEvent(db, debt.LedgerID, models.WebhookEventDebtSettled, debt)
+	}
+
+	return repayment, nil
+}
+
+// settledAt keeps the settlement time of a debt in line with its balance.
+func settledAt(debt models.Debt) *time.Time {
+	if debt.Outstanding.IsPositive() {
+		return nil
+	}
+
+	if debt.SettledAt != nil {
+		return debt.SettledAt
+	}
+
+	now := time.Now()
+	return &now
+}
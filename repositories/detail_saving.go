@@ -1,217 +1,311 @@
 package repositories
 
 import (
-	"keuangan-pribadi/config"
-	m "keuangan-pribadi/middleware"
+	"context"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+
+	"gorm.io/gorm"
 )
 
-type DetailSavingRepositoryImpl struct{}
+type DetailSavingRepositoryImpl struct {
+	db *gorm.DB
+}
 
-func InitDetailSavingRepository() DetailSavingRepository {
-	return &DetailSavingRepositoryImpl{}
+func InitDetailSavingRepository(db *gorm.DB) DetailSavingRepository {
+	return &DetailSavingRepositoryImpl{db: db}
 }
 
-func (dsr *DetailSavingRepositoryImpl) GetAll(token string) ([]models.DetailSaving, error) {
-	var detailSavings []models.DetailSaving
+func (dsr *DetailSavingRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.DetailSaving, error) {
+	db := dsr.db.WithContext(ctx)
 
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return []models.DetailSaving{}, err
-    }
+	var detailSavings []models.DetailSaving
 
-	if err := config.DB.Preload("User").Preload("Saving.User").Where("user_id = ?", user.ID).Find(&detailSavings).Error; err != nil {
+	if err := db.Preload("User").Preload("Saving.User").Where("saving_id IN (?)", memberSavingIDs(db, userID)).Find(&detailSavings).Error; err != nil {
 		return nil, err
 	}
 
 	return detailSavings, nil
 }
 
-func (dsr *DetailSavingRepositoryImpl) GetByID(id, token string) (models.DetailSaving, error) {
-	var detailSaving models.DetailSaving
+func (dsr *DetailSavingRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.DetailSaving, error) {
+	db := dsr.db.WithContext(ctx)
 
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.DetailSaving{}, err
-    }
+	var detailSaving models.DetailSaving
 
-	if err := config.DB.Preload("User").Preload("Saving.User").First(&detailSaving, "id = ? AND user_id = ?", id, user.ID).Error; err != nil {
-		return models.DetailSaving{}, err
+	if err := db.Preload("User").Preload("Saving.User").Where("saving_id IN (?)", memberSavingIDs(db, userID)).First(&detailSaving, "id = ?", id).Error; err != nil {
+		return models.DetailSaving{}, notFound(err, "detail_saving")
 	}
 
 	return detailSaving, nil
 }
 
-func (dsr *DetailSavingRepositoryImpl) Create(savingInput models.DetailSavingInput, token string) (models.DetailSaving, error) {
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.DetailSaving{}, err
-    }
+func (dsr *DetailSavingRepositoryImpl) Create(ctx context.Context, savingInput models.DetailSavingInput, userID uint, meta models.AuditMeta) (models.DetailSaving, error) {
+	db := dsr.db.WithContext(ctx)
 
 	var User models.User
-	if err := config.DB.Where("id = ?", user.ID).First(&User).Error; err != nil {
+	if err := db.Where("id = ?", userID).First(&User).Error; err != nil {
 		return models.DetailSaving{}, err
 	}
 
 	var Saving models.Saving
-	if err := config.DB.Preload("User").Where("id = ?", savingInput.SavingID).First(&Saving).Error; err != nil {
+	if err := db.Preload("User").Where("id = ? AND ledger_id IN (?)", savingInput.SavingID, memberLedgerIDs(db, userID)).First(&Saving).Error; err != nil {
+		return models.DetailSaving{}, notFound(err, "saving")
+	}
+
+	if err := requireLedgerWrite(db, Saving.LedgerID, userID); err != nil {
 		return models.DetailSaving{}, err
 	}
 
 	var DetailSaving models.DetailSaving
-	if err := config.DB.Preload("User").Preload("Saving").Where("saving_id = ?", savingInput.SavingID).First(&DetailSaving).Error; err != nil {
+	if err := db.Preload("User").Preload("Saving").Where("saving_id = ?", savingInput.SavingID).First(&DetailSaving).Error; err != nil {
 		return models.DetailSaving{}, err
 	}
 
-	total := Saving.Value + savingInput.Value
-	if err := config.DB.Model(&Saving).Update("value", total).Error; err != nil {
+	savingBefore := Saving
+
+	total, err := Saving.Value.Add(savingInput.Value)
+	if err != nil {
 		return models.DetailSaving{}, err
 	}
 
-	if total >= Saving.Goal && DetailSaving.Status == 1 {
-		exp := 10 + User.Exp
-		if err := config.DB.Model(&DetailSaving).Update("status", 2).Error; err != nil {
-			return models.DetailSaving{}, err
+	goalReached := false
+
+	var createdDetailSaving models.DetailSaving
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Saving).Updates(moneyColumns("value", total)).Error; err != nil {
+			return err
 		}
-		if err := config.DB.Model(&User).Update("exp", exp).Error; err != nil {
-			return models.DetailSaving{}, err
+
+		reached, err := total.Cmp(Saving.Goal)
+		if err != nil {
+			return err
 		}
-	}
-	
-	if err := config.DB.Preload("User").Where("id = ?", savingInput.SavingID).First(&Saving).Error; err != nil {
-		return models.DetailSaving{}, err
-	}
 
-	var createdDetailSaving models.DetailSaving = models.DetailSaving{
-		Value: 			savingInput.Value,
-		Status: 		1,
-		UserID:    		user.ID,
-		SavingID:    	savingInput.SavingID,
-		User: 			User,
-		Saving: 		Saving,
-	}
+		if reached >= 0 && DetailSaving.Status == 1 {
+			goalReached = true
+			exp := 10 + User.Exp
+			if err := tx.Model(&DetailSaving).Update("status", 2).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&User).Update("exp", exp).Error; err != nil {
+				return err
+			}
+		}
 
-	result := config.DB.Create(&createdDetailSaving)
+		if err := tx.Preload("User").Where("id = ?", savingInput.SavingID).First(&Saving).Error; err != nil {
+			return err
+		}
+
+		createdDetailSaving = models.DetailSaving{
+			Value: 			savingInput.Value,
+			Status: 		1,
+			UserID:    		userID,
+			SavingID:    	savingInput.SavingID,
+			User: 			User,
+			Saving: 		Saving,
+		}
+
+		result := tx.Create(&createdDetailSaving)
+
+		if err := result.Error; err != nil {
+			return err
+		}
+
+		if err := tx.Last(&createdDetailSaving).Error; err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, userID, models.AuditActionCreate, "detail_saving", createdDetailSaving.ID, nil, createdDetailSaving); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, Saving.UserID, models.AuditActionUpdate, "saving", Saving.ID, savingBefore, Saving)
+	})
 
-	if err := result.Error; err != nil {
+	if err != nil {
 		return models.DetailSaving{}, err
 	}
 
-	if err := config.DB.Last(&createdDetailSaving).Error; err != nil {
-		return models.DetailSaving{}, err
+	if goalReached {
+		emitEvent(db, Saving.LedgerID, models.WebhookEventSavingGoalReached, Saving)
 	}
 
 	return createdDetailSaving, nil
 }
 
-func (dsr *DetailSavingRepositoryImpl) Update(savingInput models.DetailSavingInput, id, token string) (models.DetailSaving, error) {
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.DetailSaving{}, err
-    }
+func (dsr *DetailSavingRepositoryImpl) Update(ctx context.Context, savingInput models.DetailSavingInput, id string, userID uint, meta models.AuditMeta) (models.DetailSaving, error) {
+	db := dsr.db.WithContext(ctx)
 
-	detailSaving, err := dsr.GetByID(id, token)
+	detailSaving, err := dsr.GetByID(ctx, id, userID)
 	if err != nil {
 		return models.DetailSaving{}, err
 	}
 
+	before := detailSaving
+
 	var User models.User
-	if err := config.DB.Where("id = ?", user.ID).First(&User).Error; err != nil {
+	if err := db.Where("id = ?", userID).First(&User).Error; err != nil {
 		return models.DetailSaving{}, err
 	}
 
 	var Saving models.Saving
-	if err := config.DB.Preload("User").Where("id = ?", savingInput.SavingID).First(&Saving).Error; err != nil {
+	if err := db.Preload("User").Where("id = ? AND ledger_id IN (?)", savingInput.SavingID, memberLedgerIDs(db, userID)).First(&Saving).Error; err != nil {
+		return models.DetailSaving{}, notFound(err, "saving")
+	}
+
+	if err := requireLedgerWrite(db, Saving.LedgerID, userID); err != nil {
 		return models.DetailSaving{}, err
 	}
 
-	kurang := Saving.Value - detailSaving.Value
-	if err := config.DB.Model(&Saving).Update("value", kurang).Error; err != nil {
+	savingBefore := Saving
+
+	kurang, err := Saving.Value.Sub(detailSaving.Value)
+	if err != nil {
 		return models.DetailSaving{}, err
 	}
 
-	total := Saving.Value + savingInput.Value
-	if err := config.DB.Model(&Saving).Update("value", total).Error; err != nil {
+	total, err := kurang.Add(savingInput.Value)
+	if err != nil {
 		return models.DetailSaving{}, err
 	}
 
-	if total >= Saving.Goal && detailSaving.Status == 1 {
-		exp := 10 + User.Exp
-		if err := config.DB.Model(&detailSaving).Update("status", 2).Error; err != nil {
-			return models.DetailSaving{}, err
+	goalReached := false
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Saving).Updates(moneyColumns("value", total)).Error; err != nil {
+			return err
 		}
-		if err := config.DB.Model(&User).Update("exp", exp).Error; err != nil {
-			return models.DetailSaving{}, err
+
+		reached, err := total.Cmp(Saving.Goal)
+		if err != nil {
+			return err
 		}
-	} else if total <= Saving.Goal && detailSaving.Status == 2 {
-		exp := User.Exp - 10
-		if err := config.DB.Model(&detailSaving).Update("status", 1).Error; err != nil {
-			return models.DetailSaving{}, err
+
+		if reached >= 0 && detailSaving.Status == 1 {
+			goalReached = true
+			exp := 10 + User.Exp
+			if err := tx.Model(&detailSaving).Update("status", 2).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&User).Update("exp", exp).Error; err != nil {
+				return err
+			}
+		} else if reached <= 0 && detailSaving.Status == 2 {
+			exp := User.Exp - 10
+			if err := tx.Model(&detailSaving).Update("status", 1).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&User).Update("exp", exp).Error; err != nil {
+				return err
+			}
 		}
-		if err := config.DB.Model(&User).Update("exp", exp).Error; err != nil {
-			return models.DetailSaving{}, err
+
+		if err := tx.Preload("User").Where("id = ?", savingInput.SavingID).First(&Saving).Error; err != nil {
+			return err
 		}
-	}
 
-	if err := config.DB.Preload("User").Where("id = ?", savingInput.SavingID).First(&Saving).Error; err != nil {
-		return models.DetailSaving{}, err
-	}
+		detailSaving.Value = savingInput.Value
+		detailSaving.SavingID = savingInput.SavingID
+		detailSaving.User = User
+		detailSaving.Saving = Saving
 
-	detailSaving.Value = savingInput.Value
-	detailSaving.UserID = user.ID
-	detailSaving.SavingID = savingInput.SavingID
-	detailSaving.User = User
-	detailSaving.Saving = Saving
+		if err := tx.Save(&detailSaving).Error; err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, detailSaving.UserID, models.AuditActionUpdate, "detail_saving", detailSaving.ID, before, detailSaving); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, Saving.UserID, models.AuditActionUpdate, "saving", Saving.ID, savingBefore, Saving)
+	})
 
-	if err := config.DB.Save(&detailSaving).Error; err != nil {
+	if err != nil {
 		return models.DetailSaving{}, err
 	}
 
+	if goalReached {
+		emitEvent(db, Saving.LedgerID, models.WebhookEventSavingGoalReached, Saving)
+	}
+
 	return detailSaving, nil
 }
 
-func (dsr *DetailSavingRepositoryImpl) Delete(id, token string) error {
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return err
-    }
+func (dsr *DetailSavingRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := dsr.db.WithContext(ctx)
 
 	var User models.User
-	if err := config.DB.Where("id = ?", user.ID).First(&User).Error; err != nil {
+	if err := db.Where("id = ?", userID).First(&User).Error; err != nil {
 		return err
 	}
 
-	detailSaving, err := dsr.GetByID(id, token)
+	detailSaving, err := dsr.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
 
 	var Saving models.Saving
-	if err := config.DB.Preload("User").Where("id = ?", detailSaving.SavingID).First(&Saving).Error; err != nil {
-		return err
+	if err := db.Preload("User").Where("id = ?", detailSaving.SavingID).First(&Saving).Error; err != nil {
+		return notFound(err, "saving")
 	}
 
-	kurang := Saving.Value - detailSaving.Value
-	if err := config.DB.Model(&Saving).Update("value", kurang).Error; err != nil {
+	if err := requireLedgerWrite(db, Saving.LedgerID, userID); err != nil {
 		return err
 	}
 
-	if kurang >= Saving.Goal {
-		exp := 10 + User.Exp
-		if err := config.DB.Model(&User).Update("exp", exp).Error; err != nil {
-			return err
-		}
-	} else if kurang <= Saving.Goal {
-		exp := User.Exp - 10
-		if err := config.DB.Model(&User).Update("exp", exp).Error; err != nil {
-			return err
-		}
-	}
+	savingBefore := Saving
 
+	kurang, err := Saving.Value.Sub(detailSaving.Value)
 	if err != nil {
 		return err
 	}
 
-	if err := config.DB.Delete(&detailSaving).Error; err != nil {
+	reached, err := kurang.Cmp(Saving.Goal)
+	if err != nil {
 		return err
 	}
 
-	return nil
-}
\ No newline at end of file
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Saving).Updates(moneyColumns("value", kurang)).Error; err != nil {
+			return err
+		}
+
+		if reached >= 0 {
+			exp := 10 + User.Exp
+			if err := tx.Model(&User).Update("exp", exp).Error; err != nil {
+				return err
+			}
+		} else if reached <= 0 {
+			exp := User.Exp - 10
+			if err := tx.Model(&User).Update("exp", exp).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(&detailSaving).Error; err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, detailSaving.UserID, models.AuditActionDelete, "detail_saving", detailSaving.ID, detailSaving, nil); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, Saving.UserID, models.AuditActionUpdate, "saving", Saving.ID, savingBefore, Saving)
+	})
+}
+// moneyColumns updates the columns of an amount embedded with the given
+// prefix, like value_minor and value_currency.
+func moneyColumns(prefix string, amount money.Money) map[string]interface{} {
+	return map[string]interface{}{
+		prefix + "_minor":    amount.Minor,
+		prefix + "_currency": amount.Currency,
+	}
+}
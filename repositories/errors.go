@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"errors"
+	"keuangan-pribadi/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errEmailInUse        = models.ConflictError("email_in_use", "email is already in use")
+	errIncorrectPassword = models.ValidationError("incorrect_password", "password is incorrect")
+)
+
+// notFound turns a missing row into the not found error of the entity, like
+// "finance_not_found". Any other error is returned as it is.
+func notFound(err error, entity string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.NotFoundError(entity+"_not_found", strings.ReplaceAll(entity, "_", " ")+" not found")
+	}
+
+	return err
+}
@@ -1,153 +1,181 @@
 package repositories
 
 import (
-	"keuangan-pribadi/config"
-	m "keuangan-pribadi/middleware"
+	"context"
 	"keuangan-pribadi/models"
 	"time"
+
+	"gorm.io/gorm"
 )
 
-type FinanceRepositoryImpl struct{}
+type FinanceRepositoryImpl struct {
+	db *gorm.DB
+}
 
-func InitFinanceRepository() FinanceRepository {
-	return &FinanceRepositoryImpl{}
+func InitFinanceRepository(db *gorm.DB) FinanceRepository {
+	return &FinanceRepositoryImpl{db: db}
 }
 
-func (fr *FinanceRepositoryImpl) GetAll(token string) ([]models.Finance, error) {
-	var finances []models.Finance
+func (fr *FinanceRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.Finance, error) {
+	db := fr.db.WithContext(ctx)
 
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return []models.Finance{}, err
-    }
+	var finances []models.Finance
 
-	if err := config.DB.Where("user_id = ?", user.ID).Preload("User").Preload("Category").Find(&finances).Error; err != nil {
+	if err := db.Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).Preload("User").Preload("Category").Find(&finances).Error; err != nil {
 		return nil, err
 	}
 
 	return finances, nil
 }
 
-func (fr *FinanceRepositoryImpl) GetByID(id, token string) (models.Finance, error) {
-	var finance models.Finance
+func (fr *FinanceRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.Finance, error) {
+	db := fr.db.WithContext(ctx)
 
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.Finance{}, err
-    }
+	var finance models.Finance
 
-	if err := config.DB.Preload("User").Preload("Category").First(&finance, "id = ? AND user_id = ?", id, user.ID).Error; err != nil {
-		return models.Finance{}, err
+	if err := db.Preload("User").Preload("Category").Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).First(&finance, "id = ?", id).Error; err != nil {
+		return models.Finance{}, notFound(err, "finance")
 	}
 
 	return finance, nil
 }
 
-func (fr *FinanceRepositoryImpl) Search(from, to time.Time, token string) ([]models.Finance, error) {
-	var finances []models.Finance
+func (fr *FinanceRepositoryImpl) Search(ctx context.Context, from, to time.Time, userID uint) ([]models.Finance, error) {
+	db := fr.db.WithContext(ctx)
 
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return []models.Finance{}, err
-    }
+	var finances []models.Finance
 
-	if err := config.DB.Where("created_at BETWEEN ? AND ? AND user_id = ?", from, to, user.ID).Preload("User").Preload("Category").Find(&finances).Error; err != nil {
+	if err := db.Where("created_at BETWEEN ? AND ? AND ledger_id IN (?)", from, to, memberLedgerIDs(db, userID)).Preload("User").Preload("Category").Find(&finances).Error; err != nil {
 		return nil, err
 	}
 
 	return finances, nil
 }
 
-func (fr *FinanceRepositoryImpl) Create(financeInput models.FinanceInput, token string) (models.Finance, error) {
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.Finance{}, err
-    }
+func (fr *FinanceRepositoryImpl) Create(ctx context.Context, financeInput models.FinanceInput, userID uint, meta models.AuditMeta) (models.Finance, error) {
+	db := fr.db.WithContext(ctx)
 
 	var User models.User
-	if err := config.DB.Where("id = ?", user.ID).First(&User).Error; err != nil {
+	if err := db.Where("id = ?", userID).First(&User).Error; err != nil {
 		return models.Finance{}, err
 	}
 
-	var category models.Category
-	if err := config.DB.Where("id = ?", financeInput.CategoryID).First(&category).Error; err != nil {
+	ledgerID, err := targetLedger(db, financeInput.LedgerID, userID)
+	if err != nil {
 		return models.Finance{}, err
 	}
 
+	var category models.Category
+	if err := db.Where("id = ? AND (ledger_id IS NULL OR ledger_id = ?)", financeInput.CategoryID, ledgerID).First(&category).Error; err != nil {
+		return models.Finance{}, notFound(err, "category")
+	}
+
 	var createdFinance models.Finance = models.Finance{
 		Name:       	financeInput.Name,
 		Type: 			financeInput.Type,
 		Money: 			financeInput.Money,
-		UserID:    		user.ID,
+		UserID:    		userID,
 		CategoryID:    	financeInput.CategoryID,
+		LedgerID: 		&ledgerID,
 		User: 			User,
 		Category: 		category,
 	}
 
-	result := config.DB.Create(&createdFinance)
+	meta.ActorID = userID
 
-	if err := result.Error; err != nil {
-		return models.Finance{}, err
-	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdFinance).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Last(&createdFinance).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "finance", createdFinance.ID, nil, createdFinance)
+	})
 
-	if err := config.DB.Last(&createdFinance).Error; err != nil {
+	if err != nil {
 		return models.Finance{}, err
 	}
 
+	emitEvent(db, createdFinance.LedgerID, models.WebhookEventFinanceCreated, createdFinance)
+
 	return createdFinance, nil
 }
 
-func (fr *FinanceRepositoryImpl) Update(financeInput models.FinanceInput, id, token string) (models.Finance, error) {
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.Finance{}, err
-    }
+func (fr *FinanceRepositoryImpl) Update(ctx context.Context, financeInput models.FinanceInput, id string, userID uint, meta models.AuditMeta) (models.Finance, error) {
+	db := fr.db.WithContext(ctx)
 
-	finance, err := fr.GetByID(id, token)
+	finance, err := fr.GetByID(ctx, id, userID)
 	if err != nil {
 		return models.Finance{}, err
 	}
 
-	var User models.User
-	if err := config.DB.Where("id = ?", user.ID).First(&User).Error; err != nil {
+	if err := requireLedgerWrite(db, finance.LedgerID, userID); err != nil {
 		return models.Finance{}, err
 	}
 
+	before := finance
+
 	var category models.Category
-	if err := config.DB.Where("id = ?", financeInput.CategoryID).First(&category).Error; err != nil {
-		return models.Finance{}, err
+	if err := db.Where("id = ? AND (ledger_id IS NULL OR ledger_id = ?)", financeInput.CategoryID, finance.LedgerID).First(&category).Error; err != nil {
+		return models.Finance{}, notFound(err, "category")
 	}
 
 	finance.Name = financeInput.Name
 	finance.Type = financeInput.Type
 	finance.Money = financeInput.Money
-	finance.UserID = user.ID
 	finance.CategoryID = financeInput.CategoryID
-	finance.User = User
 	finance.Category = category
 
-	if err := config.DB.Save(&finance).Error; err != nil {
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&finance).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, finance.UserID, models.AuditActionUpdate, "finance", finance.ID, before, finance)
+	})
+
+	if err != nil {
 		return models.Finance{}, err
 	}
 
+	emitEvent(db, finance.LedgerID, models.WebhookEventFinanceUpdated, finance)
+
 	return finance, nil
 }
 
-func (fr *FinanceRepositoryImpl) Delete(id, token string) error {
-	_, err := m.VerifyToken(token)
-    if err != nil {
-        return err
-    }
+func (fr *FinanceRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := fr.db.WithContext(ctx)
 
-	finance, err := fr.GetByID(id, token)
+	finance, err := fr.GetByID(ctx, id, userID)
 
 	if err != nil {
 		return err
 	}
 
-	if err := config.DB.Delete(&finance).Error; err != nil {
+	if err := requireLedgerWrite(db, finance.LedgerID, userID); err != nil {
 		return err
 	}
 
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&finance).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, finance.UserID, models.AuditActionDelete, "finance", finance.ID, finance, nil)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	emitEvent(db, finance.LedgerID, models.WebhookEventFinanceDeleted, finance)
+
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GoldSavingRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitGoldSavingRepository(db *gorm.DB) GoldSavingRepository {
+	return &GoldSavingRepositoryImpl{db: db}
+}
+
+func (gr *GoldSavingRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.GoldSaving, error) {
+	db := gr.db.WithContext(ctx)
+
+	var goldSavings []models.GoldSaving
+
+	if err := db.Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).Find(&goldSavings).Error; err != nil {
+		return nil, err
+	}
+
+	return goldSavings, nil
+}
+
+func (gr *GoldSavingRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.GoldSaving, error) {
+	db := gr.db.WithContext(ctx)
+
+	var goldSaving models.GoldSaving
+
+	if err := db.Preload("Deposits", func(db *gorm.DB) *gorm.DB {
+		return db.Order("purchased_at, id")
+	}).Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).First(&goldSaving, "id = ?", id).Error; err != nil {
+		return models.GoldSaving{}, notFound(err, "gold_saving")
+	}
+
+	return goldSaving, nil
+}
+
+func (gr *GoldSavingRepositoryImpl) Create(ctx context.Context, goldSavingInput models.GoldSavingInput, userID uint, meta models.AuditMeta) (models.GoldSaving, error) {
+	db := gr.db.WithContext(ctx)
+
+	ledgerID, err := targetLedger(db, goldSavingInput.LedgerID, userID)
+	if err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	goldSaving := models.GoldSaving{
+		Name:     goldSavingInput.Name,
+		Goal:     goldSavingInput.Goal,
+		UserID:   userID,
+		LedgerID: &ledgerID,
+	}
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&goldSaving).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "gold_saving", goldSaving.ID, nil, goldSaving)
+	})
+
+	if err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	return goldSaving, nil
+}
+
+func (gr *GoldSavingRepositoryImpl) Update(ctx context.Context, goldSavingUpdate models.GoldSavingUpdate, id string, userID uint, meta models.AuditMeta) (models.GoldSaving, error) {
+	db := gr.db.WithContext(ctx)
+
+	goldSaving, err := gr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	if err := requireLedgerWrite(db, goldSaving.LedgerID, userID); err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	before := goldSaving
+
+	goldSaving.Name = goldSavingUpdate.Name
+	goldSaving.Goal = goldSavingUpdate.Goal
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Deposits").Save(&goldSaving).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, goldSaving.UserID, models.AuditActionUpdate, "gold_saving", goldSaving.ID, before, goldSaving)
+	})
+
+	if err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	return goldSaving, nil
+}
+
+func (gr *GoldSavingRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := gr.db.WithContext(ctx)
+
+	goldSaving, err := gr.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireLedgerWrite(db, goldSaving.LedgerID, userID); err != nil {
+		return err
+	}
+
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("gold_saving_id = ?", goldSaving.ID).Delete(&models.GoldDeposit{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&goldSaving).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, goldSaving.UserID, models.AuditActionDelete, "gold_saving", goldSaving.ID, goldSaving, nil)
+	})
+}
+
+// Deposit adds gold bought for the saving. Every deposit of a saving is paid
+// in the same currency, so the cost stays one amount.
+func (gr *GoldSavingRepositoryImpl) Deposit(ctx context.Context, depositInput models.GoldDepositInput, id string, userID uint, meta models.AuditMeta) (models.GoldDeposit, error) {
+	db := gr.db.WithContext(ctx)
+
+	goldSaving, err := gr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.GoldDeposit{}, err
+	}
+
+	if err := requireLedgerWrite(db, goldSaving.LedgerID, userID); err != nil {
+		return models.GoldDeposit{}, err
+	}
+
+	before := goldSaving
+	before.Deposits = nil
+
+	// checked again against the deposits inside the transaction
+	if _, err := goldSaving.Cost.Add(depositInput.Price); err != nil {
+		return models.GoldDeposit{}, err
+	}
+
+	purchasedAt := depositInput.PurchasedAt
+	if purchasedAt.IsZero() {
+		purchasedAt = time.Now()
+	}
+
+	deposit := models.GoldDeposit{
+		GoldSavingID: goldSaving.ID,
+		Grams:        depositInput.Grams,
+		Price:        depositInput.Price,
+		PurchasedAt:  purchasedAt.UTC(),
+		UserID:       userID,
+	}
+
+	goldSaving.Deposits = nil
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGoldSaving(tx, goldSaving.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&deposit).Error; err != nil {
+			return err
+		}
+
+		if err := updateGoldTotals(tx, &goldSaving); err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, goldSaving.UserID, models.AuditActionCreate, "gold_deposit", deposit.ID, nil, deposit); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, goldSaving.UserID, models.AuditActionUpdate, "gold_saving", goldSaving.ID, before, goldSaving)
+	})
+	if err != nil {
+		return models.GoldDeposit{}, err
+	}
+
+	// the totals were added up inside the transaction, so only the deposit
+	// crossing the goal reaches it
+	if goldSaving.Goal > 0 && goldSaving.Grams >= goldSaving.Goal && goldSaving.Grams-deposit.Grams < goldSaving.Goal {
+		emitEvent(db, goldSaving.LedgerID, models.EventGoldSavingGoalReached, goldSaving)
+	}
+
+	return deposit, nil
+}
+
+func (gr *GoldSavingRepositoryImpl) DeleteDeposit(ctx context.Context, id, depositID string, userID uint, meta models.AuditMeta) error {
+	db := gr.db.WithContext(ctx)
+
+	goldSaving, err := gr.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireLedgerWrite(db, goldSaving.LedgerID, userID); err != nil {
+		return err
+	}
+
+	var deposit models.GoldDeposit
+	if err := db.Where("gold_saving_id = ?", goldSaving.ID).First(&deposit, "id = ?", depositID).Error; err != nil {
+		return notFound(err, "gold_deposit")
+	}
+
+	before := goldSaving
+	before.Deposits = nil
+
+	goldSaving.Deposits = nil
+
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGoldSaving(tx, goldSaving.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&deposit).Error; err != nil {
+			return err
+		}
+
+		if err := updateGoldTotals(tx, &goldSaving); err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, goldSaving.UserID, models.AuditActionDelete, "gold_deposit", deposit.ID, deposit, nil); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, goldSaving.UserID, models.AuditActionUpdate, "gold_saving", goldSaving.ID, before, goldSaving)
+	})
+}
+
+// lockGoldSaving takes the row lock of a saving before its deposits change,
+// so a concurrent deposit waits and then counts this one in its totals.
+func lockGoldSaving(tx *gorm.DB, id uint) error {
+	return tx.Model(&models.GoldSaving{}).Where("id = ?", id).Update("updated_at", time.Now()).Error
+}
+
+// updateGoldTotals adds up the deposits of a saving and writes the totals,
+// instead of adjusting totals read before the transaction. A saving without
+// gold left has no cost currency and may be bought in another one.
+func updateGoldTotals(tx *gorm.DB, goldSaving *models.GoldSaving) error {
+	var deposits []models.GoldDeposit
+	if err := tx.Where("gold_saving_id = ?", goldSaving.ID).Find(&deposits).Error; err != nil {
+		return err
+	}
+
+	var grams models.Grams
+	var cost money.Money
+	for _, deposit := range deposits {
+		sum, err := cost.Add(deposit.Price)
+		if err != nil {
+			return err
+		}
+
+		grams += deposit.Grams
+		cost = sum
+	}
+
+	goldSaving.Grams = grams
+	goldSaving.Cost = cost
+
+	columns := moneyColumns("cost", cost)
+	columns["grams"] = grams
+
+	return tx.Model(&models.GoldSaving{}).Where("id = ?", goldSaving.ID).Updates(columns).Error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"keuangan-pribadi/migrations"
+
+	"gorm.io/gorm"
+)
+
+type HealthRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitHealthRepository(db *gorm.DB) HealthRepository {
+	return &HealthRepositoryImpl{db: db}
+}
+
+func (hr *HealthRepositoryImpl) Ping(ctx context.Context) error {
+	database, err := hr.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return database.PingContext(ctx)
+}
+
+func (hr *HealthRepositoryImpl) PendingMigrations(ctx context.Context) (int, error) {
+	return migrations.Pending(hr.db.WithContext(ctx))
+}
@@ -0,0 +1,508 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"keuangan-pribadi/config"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errNotLedgerMember = models.ForbiddenError("not_ledger_member", "you are not a member of this ledger")
+	errLedgerReadOnly  = models.ForbiddenError("ledger_read_only", "you can only view this ledger")
+	errNotLedgerOwner  = models.ForbiddenError("not_ledger_owner", "only the owner can manage this ledger")
+)
+
+type LedgerRepositoryImpl struct {
+	db     *gorm.DB
+	mailer utils.Mailer
+	auth   config.AuthConfig
+}
+
+func InitLedgerRepository(db *gorm.DB, mailer utils.Mailer, auth config.AuthConfig) LedgerRepository {
+	return &LedgerRepositoryImpl{db: db, mailer: mailer, auth: auth}
+}
+
+func (lr *LedgerRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.Ledger, error) {
+	db := lr.db.WithContext(ctx)
+
+	var ledgers []models.Ledger
+
+	// make sure users registered before ledgers existed have one too
+	if _, err := PersonalLedger(db, userID); err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("Members").Where("id IN (?)", memberLedgerIDs(db, userID)).Find(&ledgers).Error; err != nil {
+		return nil, err
+	}
+
+	return ledgers, nil
+}
+
+func (lr *LedgerRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.Ledger, error) {
+	db := lr.db.WithContext(ctx)
+
+	var ledger models.Ledger
+
+	if err := db.Preload("Members").Where("id IN (?)", memberLedgerIDs(db, userID)).First(&ledger, "id = ?", id).Error; err != nil {
+		return models.Ledger{}, notFound(err, "ledger")
+	}
+
+	return ledger, nil
+}
+
+func (lr *LedgerRepositoryImpl) Create(ctx context.Context, ledgerInput models.LedgerInput, userID uint, meta models.AuditMeta) (models.Ledger, error) {
+	db := lr.db.WithContext(ctx)
+
+	var createdLedger models.Ledger = models.Ledger{
+		Name:    ledgerInput.Name,
+		Members: []models.LedgerMember{{UserID: userID, Role: models.LedgerRoleOwner}},
+	}
+
+	meta.ActorID = userID
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdLedger).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "ledger", createdLedger.ID, nil, createdLedger)
+	})
+
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	return createdLedger, nil
+}
+
+func (lr *LedgerRepositoryImpl) Update(ctx context.Context, ledgerInput models.LedgerInput, id string, userID uint, meta models.AuditMeta) (models.Ledger, error) {
+	db := lr.db.WithContext(ctx)
+
+	ledger, err := lr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	if err := requireLedgerOwner(db, ledger.ID, userID); err != nil {
+		return models.Ledger{}, err
+	}
+
+	before := ledger
+	ledger.Name = ledgerInput.Name
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ledger).Update("name", ledger.Name).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionUpdate, "ledger", ledger.ID, before, ledger)
+	})
+
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	return ledger, nil
+}
+
+func (lr *LedgerRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := lr.db.WithContext(ctx)
+
+	ledger, err := lr.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireLedgerOwner(db, ledger.ID, userID); err != nil {
+		return err
+	}
+
+	if ledger.Personal {
+		return models.ForbiddenError("personal_ledger", "a personal ledger cannot be deleted")
+	}
+
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteLedgerData(tx, ledger.ID); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionDelete, "ledger", ledger.ID, ledger, nil)
+	})
+}
+
+func (lr *LedgerRepositoryImpl) UpdateMember(ctx context.Context, memberInput models.LedgerMemberInput, id, memberID string, userID uint, meta models.AuditMeta) (models.LedgerMember, error) {
+	db := lr.db.WithContext(ctx)
+
+	ledger, err := lr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.LedgerMember{}, err
+	}
+
+	if err := requireLedgerOwner(db, ledger.ID, userID); err != nil {
+		return models.LedgerMember{}, err
+	}
+
+	var member models.LedgerMember
+	if err := db.First(&member, "ledger_id = ? AND user_id = ?", ledger.ID, memberID).Error; err != nil {
+		return models.LedgerMember{}, notFound(err, "ledger_member")
+	}
+
+	if member.Role == models.LedgerRoleOwner {
+		return models.LedgerMember{}, models.ForbiddenError("owner_role", "the role of the owner cannot be changed")
+	}
+
+	before := member
+	member.Role = memberInput.Role
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&member).Update("role", member.Role).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionUpdate, "ledger_member", member.ID, before, member)
+	})
+
+	if err != nil {
+		return models.LedgerMember{}, err
+	}
+
+	return member, nil
+}
+
+func (lr *LedgerRepositoryImpl) RemoveMember(ctx context.Context, id, memberID string, userID uint, meta models.AuditMeta) error {
+	db := lr.db.WithContext(ctx)
+
+	ledger, err := lr.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	var member models.LedgerMember
+	if err := db.First(&member, "ledger_id = ? AND user_id = ?", ledger.ID, memberID).Error; err != nil {
+		return notFound(err, "ledger_member")
+	}
+
+	// members may leave on their own, everybody else needs the owner
+	if member.UserID != userID {
+		if err := requireLedgerOwner(db, ledger.ID, userID); err != nil {
+			return err
+		}
+	}
+
+	if member.Role == models.LedgerRoleOwner {
+		return models.ConflictError("owner_cannot_leave", "the owner cannot leave the ledger, delete it instead")
+	}
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionDelete, "ledger_member", member.ID, member, nil)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (lr *LedgerRepositoryImpl) Invite(ctx context.Context, invitationInput models.LedgerInvitationInput, id string, userID uint, meta models.AuditMeta) (models.LedgerInvitation, error) {
+	db := lr.db.WithContext(ctx)
+
+	ledger, err := lr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.LedgerInvitation{}, err
+	}
+
+	user, err := findUser(db, userID)
+	if err != nil {
+		return models.LedgerInvitation{}, err
+	}
+
+	if err := requireLedgerOwner(db, ledger.ID, user.ID); err != nil {
+		return models.LedgerInvitation{}, err
+	}
+
+	invitationToken, err := utils.RandomToken(32)
+	if err != nil {
+		return models.LedgerInvitation{}, err
+	}
+
+	var invitation models.LedgerInvitation = models.LedgerInvitation{
+		LedgerID:    ledger.ID,
+		Email:       strings.ToLower(invitationInput.Email),
+		Role:        invitationInput.Role,
+		TokenHash:   utils.HashToken(invitationToken),
+		InvitedByID: user.ID,
+		ExpiresAt:   time.Now().Add(lr.auth.InvitationTTL),
+	}
+
+	meta.ActorID = user.ID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, user.ID, models.AuditActionCreate, "ledger_invitation", invitation.ID, nil, invitation)
+	})
+
+	if err != nil {
+		return models.LedgerInvitation{}, err
+	}
+
+	body := fmt.Sprintf("Hi,\n\n%s invited you to the ledger %q as %s. Sign in and use the following token to join:\n\n%s\n\nThe invitation expires in %s.", user.Name, ledger.Name, invitation.Role, invitationToken, utils.HumanDuration(lr.auth.InvitationTTL))
+
+	if err := lr.mailer.Send(invitation.Email, "You are invited to a shared ledger", body); err != nil {
+		return models.LedgerInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+func (lr *LedgerRepositoryImpl) AcceptInvitation(ctx context.Context, acceptInput models.LedgerInvitationAccept, userID uint, meta models.AuditMeta) (models.LedgerMember, error) {
+	db := lr.db.WithContext(ctx)
+
+	user, err := findUser(db, userID)
+	if err != nil {
+		return models.LedgerMember{}, err
+	}
+
+	var invitation models.LedgerInvitation
+	if err := db.First(&invitation, "token_hash = ? AND accepted_at IS NULL AND expires_at > ?", utils.HashToken(acceptInput.Token), time.Now()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LedgerMember{}, models.NotFoundError("invitation_not_found", "invalid or expired invitation")
+		}
+
+		return models.LedgerMember{}, err
+	}
+
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return models.LedgerMember{}, models.ForbiddenError("invitation_for_another_email", "this invitation was sent to another email address")
+	}
+
+	var member models.LedgerMember
+
+	meta.ActorID = user.ID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&member, "ledger_id = ? AND user_id = ?", invitation.LedgerID, user.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			member = models.LedgerMember{LedgerID: invitation.LedgerID, UserID: user.ID, Role: invitation.Role}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&invitation).Update("accepted_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, user.ID, models.AuditActionCreate, "ledger_member", member.ID, nil, member)
+	})
+
+	if err != nil {
+		return models.LedgerMember{}, err
+	}
+
+	return member, nil
+}
+
+func (lr *LedgerRepositoryImpl) GetCategories(ctx context.Context, id string, userID uint) ([]models.Category, error) {
+	db := lr.db.WithContext(ctx)
+
+	var categories []models.Category
+
+	ledger, err := lr.GetByID(ctx, id, userID)
+	if err != nil {
+		return []models.Category{}, err
+	}
+
+	if err := db.Where("ledger_id IS NULL OR ledger_id = ?", ledger.ID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (lr *LedgerRepositoryImpl) CreateCategory(ctx context.Context, categoryInput models.CategoryInput, id string, userID uint, meta models.AuditMeta) (models.Category, error) {
+	db := lr.db.WithContext(ctx)
+
+	ledger, err := lr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	user, err := findUser(db, userID)
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	if err := requireLedgerWrite(db, &ledger.ID, user.ID); err != nil {
+		return models.Category{}, err
+	}
+
+	var createdCategory models.Category = models.Category{
+		Name:     categoryInput.Name,
+		LedgerID: &ledger.ID,
+	}
+
+	meta.ActorID = user.ID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdCategory).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, user.ID, models.AuditActionCreate, "category", createdCategory.ID, nil, createdCategory)
+	})
+
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	return createdCategory, nil
+}
+
+// PersonalLedger returns the personal ledger of the user and creates it when
+// the user does not have one yet.
+func PersonalLedger(db *gorm.DB, userID uint) (models.Ledger, error) {
+	var ledger models.Ledger
+
+	owned := db.Model(&models.LedgerMember{}).Select("ledger_id").Where("user_id = ? AND role = ?", userID, models.LedgerRoleOwner)
+
+	err := db.Where("personal = ? AND id IN (?)", true, owned).First(&ledger).Error
+	if err == nil {
+		return ledger, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Ledger{}, err
+	}
+
+	ledger = models.Ledger{
+		Name:     "Personal",
+		Personal: true,
+		Members:  []models.LedgerMember{{UserID: userID, Role: models.LedgerRoleOwner}},
+	}
+
+	if err := db.Create(&ledger).Error; err != nil {
+		return models.Ledger{}, err
+	}
+
+	return ledger, nil
+}
+
+// memberLedgerIDs is a subquery selecting the IDs of every ledger the user
+// is a member of.
+func memberLedgerIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.LedgerMember{}).Select("ledger_id").Where("user_id = ?", userID)
+}
+
+// targetLedger resolves the ledger a new row is written to: the requested
+// one when given, otherwise the user's personal ledger.
+func targetLedger(db *gorm.DB, ledgerID, userID uint) (uint, error) {
+	if ledgerID == 0 {
+		ledger, err := PersonalLedger(db, userID)
+		if err != nil {
+			return 0, err
+		}
+
+		return ledger.ID, nil
+	}
+
+	if err := requireLedgerWrite(db, &ledgerID, userID); err != nil {
+		return 0, err
+	}
+
+	return ledgerID, nil
+}
+
+func requireLedgerWrite(db *gorm.DB, ledgerID *uint, userID uint) error {
+	if ledgerID == nil {
+		return errNotLedgerMember
+	}
+
+	var member models.LedgerMember
+	if err := db.First(&member, "ledger_id = ? AND user_id = ?", *ledgerID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNotLedgerMember
+		}
+
+		return err
+	}
+
+	if !member.CanWrite() {
+		return errLedgerReadOnly
+	}
+
+	return nil
+}
+
+func requireLedgerOwner(db *gorm.DB, ledgerID, userID uint) error {
+	var member models.LedgerMember
+	if err := db.First(&member, "ledger_id = ? AND user_id = ? AND role = ?", ledgerID, userID, models.LedgerRoleOwner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNotLedgerOwner
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// deleteLedgerData removes a ledger together with everything stored in it.
+func deleteLedgerData(tx *gorm.DB, ledgerID uint) error {
+	savings := tx.Model(&models.Saving{}).Select("id").Where("ledger_id = ?", ledgerID)
+
+	if err := tx.Where("saving_id IN (?)", savings).Delete(&models.DetailSaving{}).Error; err != nil {
+		return err
+	}
+
+	goldSavings := tx.Model(&models.GoldSaving{}).Select("id").Where("ledger_id = ?", ledgerID)
+
+	if err := tx.Where("gold_saving_id IN (?)", goldSavings).Delete(&models.GoldDeposit{}).Error; err != nil {
+		return err
+	}
+
+	debts := tx.Model(&models.Debt{}).Select("id").Where("ledger_id = ?", ledgerID)
+
+	if err := tx.Where("debt_id IN (?)", debts).Delete(&models.DebtRepayment{}).Error; err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{&models.Debt{}, &models.Bill{}, &models.Finance{}, &models.Saving{}, &models.GoldSaving{}, &models.Category{}, &models.LedgerMember{}, &models.LedgerInvitation{}} {
+		if err := tx.Where("ledger_id = ?", ledgerID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Delete(&models.Ledger{}, ledgerID).Error
+}
+
+// memberSavingIDs is a subquery selecting the IDs of every saving stored in a
+// ledger the user is a member of.
+func memberSavingIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.Saving{}).Select("id").Where("ledger_id IN (?)", memberLedgerIDs(db, userID))
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &NotificationRepositoryImpl{db: db}
+}
+
+func (nr *NotificationRepositoryImpl) GetAll(ctx context.Context, filter models.NotificationFilter, userID uint) ([]models.Notification, error) {
+	db := nr.db.WithContext(ctx)
+
+	var notifications []models.Notification
+
+	query := db.Where("user_id = ?", userID)
+
+	if filter.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Order("created_at DESC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (nr *NotificationRepositoryImpl) MarkRead(ctx context.Context, id string, userID uint) (models.Notification, error) {
+	db := nr.db.WithContext(ctx)
+
+	var notification models.Notification
+
+	if err := db.First(&notification, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return models.Notification{}, notFound(err, "notification")
+	}
+
+	if notification.ReadAt == nil {
+		readAt := time.Now()
+		notification.ReadAt = &readAt
+
+		if err := db.Model(&notification).Update("read_at", readAt).Error; err != nil {
+			return models.Notification{}, err
+		}
+	}
+
+	return notification, nil
+}
+
+func (nr *NotificationRepositoryImpl) MarkAllRead(ctx context.Context, userID uint) error {
+	db := nr.db.WithContext(ctx)
+
+	return db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now()).Error
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitPersonalAccessTokenRepository(db *gorm.DB) PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepositoryImpl{db: db}
+}
+
+func (pr *PersonalAccessTokenRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error) {
+	db := pr.db.WithContext(ctx)
+
+	var tokens []models.PersonalAccessToken
+
+	if err := db.Where("user_id = ?", userID).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (pr *PersonalAccessTokenRepositoryImpl) Create(ctx context.Context, tokenInput models.PersonalAccessTokenInput, userID uint, meta models.AuditMeta) (models.PersonalAccessTokenResponse, error) {
+	db := pr.db.WithContext(ctx)
+
+	if err := validateScopes(tokenInput.Scopes); err != nil {
+		return models.PersonalAccessTokenResponse{}, err
+	}
+
+	random, err := utils.RandomToken(20)
+	if err != nil {
+		return models.PersonalAccessTokenResponse{}, err
+	}
+
+	plainToken := models.PersonalAccessTokenPrefix + random
+
+	var createdToken models.PersonalAccessToken = models.PersonalAccessToken{
+		Name:      tokenInput.Name,
+		TokenHash: utils.HashToken(plainToken),
+		Prefix:    plainToken[:len(models.PersonalAccessTokenPrefix)+6],
+		Scopes:    tokenInput.Scopes,
+		UserID:    userID,
+	}
+
+	if tokenInput.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, tokenInput.ExpiresInDays)
+		createdToken.ExpiresAt = &expiresAt
+	}
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdToken).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "personal_access_token", createdToken.ID, nil, createdToken)
+	})
+
+	if err != nil {
+		return models.PersonalAccessTokenResponse{}, err
+	}
+
+	// the plain token is only returned once, only its hash is stored
+	return models.PersonalAccessTokenResponse{
+		PersonalAccessToken: createdToken,
+		Token:               plainToken,
+	}, nil
+}
+
+func (pr *PersonalAccessTokenRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := pr.db.WithContext(ctx)
+
+	var pat models.PersonalAccessToken
+
+	if err := db.First(&pat, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return notFound(err, "personal_access_token")
+	}
+
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&pat).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionDelete, "personal_access_token", pat.ID, pat, nil)
+	})
+}
+
+func validateScopes(scopes []string) error {
+	allowed := map[string]bool{}
+	for _, resource := range models.PersonalAccessTokenResources {
+		allowed[resource+":read"] = true
+		allowed[resource+":write"] = true
+	}
+
+	for _, scope := range scopes {
+		if !allowed[scope] {
+			return models.ValidationError("unknown_scope", "unknown scope: "+scope)
+		}
+	}
+
+	return nil
+}
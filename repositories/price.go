@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PriceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitPriceRepository(db *gorm.DB) PriceRepository {
+	return &PriceRepositoryImpl{db: db}
+}
+
+// pricePoint are the columns a price is stored under, a price fetched again
+// replaces the stored one.
+var pricePoint = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "symbol"}, {Name: "interval"}, {Name: "date"}},
+	DoUpdates: clause.AssignmentColumns([]string{"value", "name", "unit", "currency", "updated_at"}),
+}
+
+func (pr *PriceRepositoryImpl) SaveSeries(ctx context.Context, series models.PriceSeries) error {
+	db := pr.db.WithContext(ctx)
+
+	if len(series.Points) == 0 {
+		return nil
+	}
+
+	var prices []models.MarketPrice
+
+	for _, point := range series.Points {
+		prices = append(prices, models.MarketPrice{
+			Symbol:   series.Symbol,
+			Interval: series.Interval,
+			Date:     point.Date.UTC(),
+			Value:    point.Value,
+			Name:     series.Name,
+			Unit:     series.Unit,
+			Currency: series.Currency,
+		})
+	}
+
+	return db.Clauses(pricePoint).CreateInBatches(&prices, 100).Error
+}
+
+func (pr *PriceRepositoryImpl) GetSeries(ctx context.Context, symbol, interval string) (models.PriceSeries, error) {
+	db := pr.db.WithContext(ctx)
+
+	var prices []models.MarketPrice
+
+	// interval is a reserved word of MySQL, the conditions are structs so
+	// the columns get quoted
+	if err := db.Where(&models.MarketPrice{Symbol: symbol, Interval: interval}).Order("date").Find(&prices).Error; err != nil {
+		return models.PriceSeries{}, err
+	}
+
+	if len(prices) == 0 {
+		return models.PriceSeries{}, models.NotFoundError("prices_not_found", "no prices are stored")
+	}
+
+	last := prices[len(prices)-1]
+
+	series := models.PriceSeries{
+		Symbol:   symbol,
+		Name:     last.Name,
+		Interval: interval,
+		Unit:     last.Unit,
+		Currency: last.Currency,
+	}
+
+	for _, price := range prices {
+		series.Points = append(series.Points, models.PricePoint{Date: price.Date, Value: price.Value})
+
+		if price.UpdatedAt.After(series.FetchedAt) {
+			series.FetchedAt = price.UpdatedAt
+		}
+	}
+
+	return series, nil
+}
+
+func (pr *PriceRepositoryImpl) SaveRate(ctx context.Context, rate models.ExchangeRate) error {
+	db := pr.db.WithContext(ctx)
+
+	price := models.MarketPrice{
+		Symbol:   rate.From + "/" + rate.To,
+		Interval: models.MarketPriceRateInterval,
+		Date:     rate.FetchedAt.UTC().Truncate(24 * time.Hour),
+		Value:    rate.Rate,
+		Currency: rate.To,
+	}
+
+	return db.Clauses(pricePoint).Create(&price).Error
+}
+
+func (pr *PriceRepositoryImpl) GetRate(ctx context.Context, from, to string) (models.ExchangeRate, error) {
+	db := pr.db.WithContext(ctx)
+
+	var price models.MarketPrice
+
+	if err := db.Where(&models.MarketPrice{Symbol: from + "/" + to, Interval: models.MarketPriceRateInterval}).Order("date DESC").First(&price).Error; err != nil {
+		return models.ExchangeRate{}, notFound(err, "exchange_rate")
+	}
+
+	return models.ExchangeRate{From: from, To: to, Rate: price.Value, FetchedAt: price.UpdatedAt}, nil
+}
@@ -1,46 +1,149 @@
 package repositories
 
 import (
+	"context"
 	"keuangan-pribadi/models"
 	"time"
 )
 
 type UserRepository interface {
-	Register(UserInput models.UserInput) (models.User, error)
-	GetByEmail(email string) (models.User, error)
-	Login(UserInput models.UserAuth) (models.UserResponse, error)
-	Update(UserInput models.UserInput, token string) (models.User, error)
+	Register(ctx context.Context, UserInput models.UserInput, meta models.AuditMeta) (models.User, error)
+	GetAll(ctx context.Context) ([]models.User, error)
+	GetByEmail(ctx context.Context, email string) (models.User, error)
+	Login(ctx context.Context, UserInput models.UserAuth) (models.UserResponse, error)
+	Update(ctx context.Context, UserUpdate models.UserUpdate, userID uint, meta models.AuditMeta) (models.User, error)
+	ChangePassword(ctx context.Context, PasswordInput models.UserChangePassword, userID uint, meta models.AuditMeta) (models.UserResponse, error)
+	ChangeEmail(ctx context.Context, EmailInput models.UserChangeEmail, userID uint) error
+	VerifyEmail(ctx context.Context, VerifyInput models.UserVerifyEmail, meta models.AuditMeta) (models.User, error)
+	UpdateRole(ctx context.Context, RoleInput models.UserRole, id string, meta models.AuditMeta) (models.User, error)
+	Delete(ctx context.Context, id string, adminID uint, meta models.AuditMeta) error
+	Export(ctx context.Context, userID uint) (models.UserExport, error)
+	DeleteAccount(ctx context.Context, DeleteInput models.UserDeleteAccount, userID uint) error
+}
+
+type PersonalAccessTokenRepository interface {
+	GetAll(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error)
+	Create(ctx context.Context, TokenInput models.PersonalAccessTokenInput, userID uint, meta models.AuditMeta) (models.PersonalAccessTokenResponse, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
+}
+
+type BackupRepository interface {
+	Create(ctx context.Context, userID uint) (models.Backup, error)
+	Restore(ctx context.Context, backup models.Backup, mode string, userID uint, meta models.AuditMeta) (models.RestoreResult, error)
+}
+
+type LedgerRepository interface {
+	GetAll(ctx context.Context, userID uint) ([]models.Ledger, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.Ledger, error)
+	Create(ctx context.Context, LedgerInput models.LedgerInput, userID uint, meta models.AuditMeta) (models.Ledger, error)
+	Update(ctx context.Context, LedgerInput models.LedgerInput, id string, userID uint, meta models.AuditMeta) (models.Ledger, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
+	UpdateMember(ctx context.Context, MemberInput models.LedgerMemberInput, id, memberID string, userID uint, meta models.AuditMeta) (models.LedgerMember, error)
+	RemoveMember(ctx context.Context, id, memberID string, userID uint, meta models.AuditMeta) error
+	Invite(ctx context.Context, InvitationInput models.LedgerInvitationInput, id string, userID uint, meta models.AuditMeta) (models.LedgerInvitation, error)
+	AcceptInvitation(ctx context.Context, AcceptInput models.LedgerInvitationAccept, userID uint, meta models.AuditMeta) (models.LedgerMember, error)
+	GetCategories(ctx context.Context, id string, userID uint) ([]models.Category, error)
+	CreateCategory(ctx context.Context, CategoryInput models.CategoryInput, id string, userID uint, meta models.AuditMeta) (models.Category, error)
+}
+
+type DebtRepository interface {
+	GetAll(ctx context.Context, userID uint) ([]models.Debt, error)
+	GetOverdue(ctx context.Context, userID uint) ([]models.Debt, error)
+	GetSummary(ctx context.Context, userID uint) (models.DebtSummary, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.Debt, error)
+	Create(ctx context.Context, DebtInput models.DebtInput, userID uint, meta models.AuditMeta) (models.Debt, error)
+	Update(ctx context.Context, DebtInput models.DebtInput, id string, userID uint, meta models.AuditMeta) (models.Debt, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
+	Repay(ctx context.Context, RepaymentInput models.DebtRepaymentInput, id string, userID uint, meta models.AuditMeta) (models.DebtRepayment, error)
+}
+
+type BillRepository interface {
+	GetAll(ctx context.Context, userID uint) ([]models.Bill, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.Bill, error)
+	Create(ctx context.Context, BillInput models.BillInput, userID uint, meta models.AuditMeta) (models.Bill, error)
+	Update(ctx context.Context, BillInput models.BillInput, id string, userID uint, meta models.AuditMeta) (models.Bill, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
+	Pay(ctx context.Context, PaymentInput models.BillPayment, id string, userID uint, meta models.AuditMeta) (models.Finance, error)
+	GenerateReminders(ctx context.Context, now time.Time) (int, error)
+}
+
+type NotificationRepository interface {
+	GetAll(ctx context.Context, filter models.NotificationFilter, userID uint) ([]models.Notification, error)
+	MarkRead(ctx context.Context, id string, userID uint) (models.Notification, error)
+	MarkAllRead(ctx context.Context, userID uint) error
+}
+
+type WebhookRepository interface {
+	GetAll(ctx context.Context, userID uint) ([]models.Webhook, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.Webhook, error)
+	Create(ctx context.Context, WebhookInput models.WebhookInput, userID uint, meta models.AuditMeta) (models.WebhookResponse, error)
+	Update(ctx context.Context, WebhookInput models.WebhookInput, id string, userID uint, meta models.AuditMeta) (models.Webhook, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
+	GetDeliveries(ctx context.Context, id string, userID uint) ([]models.WebhookDelivery, error)
+	CreateTestDelivery(ctx context.Context, id string, userID uint) (models.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+type AuditLogRepository interface {
+	GetAll(ctx context.Context, filter models.AuditLogFilter, userID uint) ([]models.AuditLog, error)
+}
+
+type GoldSavingRepository interface {
+	GetAll(ctx context.Context, userID uint) ([]models.GoldSaving, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.GoldSaving, error)
+	Create(ctx context.Context, GoldSavingInput models.GoldSavingInput, userID uint, meta models.AuditMeta) (models.GoldSaving, error)
+	Update(ctx context.Context, GoldSavingUpdate models.GoldSavingUpdate, id string, userID uint, meta models.AuditMeta) (models.GoldSaving, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
+	Deposit(ctx context.Context, DepositInput models.GoldDepositInput, id string, userID uint, meta models.AuditMeta) (models.GoldDeposit, error)
+	DeleteDeposit(ctx context.Context, id, depositID string, userID uint, meta models.AuditMeta) error
+}
+
+type PriceRepository interface {
+	SaveSeries(ctx context.Context, series models.PriceSeries) error
+	GetSeries(ctx context.Context, symbol, interval string) (models.PriceSeries, error)
+	SaveRate(ctx context.Context, rate models.ExchangeRate) error
+	GetRate(ctx context.Context, from, to string) (models.ExchangeRate, error)
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	PendingMigrations(ctx context.Context) (int, error)
+}
+
+type StatRepository interface {
+	GetSummary(ctx context.Context) (models.Stat, error)
 }
 
 type CategoryRepository interface {
-	GetAll() ([]models.Category, error)
-	GetByID(id string) (models.Category, error)
-	Create(CategoryInput models.CategoryInput) (models.Category, error)
-	Update(CategoryInput models.CategoryInput, id string) (models.Category, error)
-	Delete(id string) error
+	GetAll(ctx context.Context) ([]models.Category, error)
+	GetByID(ctx context.Context, id string) (models.Category, error)
+	Create(ctx context.Context, CategoryInput models.CategoryInput, meta models.AuditMeta) (models.Category, error)
+	Update(ctx context.Context, CategoryInput models.CategoryInput, id string, meta models.AuditMeta) (models.Category, error)
+	Delete(ctx context.Context, id string, meta models.AuditMeta) error
 }
 
 type FinanceRepository interface {
-	GetAll(token string) ([]models.Finance, error)
-	GetByID(id, token string) (models.Finance, error)
-	Search(from, to time.Time, token string) ([]models.Finance, error)
-	Create(FinanceInput models.FinanceInput, token string) (models.Finance, error)
-	Update(FinanceInput models.FinanceInput, id, token string) (models.Finance, error)
-	Delete(id, token string) error
+	GetAll(ctx context.Context, userID uint) ([]models.Finance, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.Finance, error)
+	Search(ctx context.Context, from, to time.Time, userID uint) ([]models.Finance, error)
+	Create(ctx context.Context, FinanceInput models.FinanceInput, userID uint, meta models.AuditMeta) (models.Finance, error)
+	Update(ctx context.Context, FinanceInput models.FinanceInput, id string, userID uint, meta models.AuditMeta) (models.Finance, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
 }
 
 type SavingRepository interface {
-	GetAll(token string) ([]models.Saving, error)
-	GetByID(id, token string) (models.Saving, error)
-	Create(SavingInput models.SavingInput, token string) (models.Saving, error)
-	Update(SavingUpdate models.SavingUpdate, id, token string) (models.Saving, error)
-	Delete(id, token string) error
+	GetAll(ctx context.Context, userID uint) ([]models.Saving, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.Saving, error)
+	Create(ctx context.Context, SavingInput models.SavingInput, userID uint, meta models.AuditMeta) (models.Saving, error)
+	Update(ctx context.Context, SavingUpdate models.SavingUpdate, id string, userID uint, meta models.AuditMeta) (models.Saving, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
 }
 
 type DetailSavingRepository interface {
-	GetAll(token string) ([]models.DetailSaving, error)
-	GetByID(id, token string) (models.DetailSaving, error)
-	Create(SavingInput models.DetailSavingInput, token string) (models.DetailSaving, error)
-	Update(SavingInput models.DetailSavingInput, id, token string) (models.DetailSaving, error)
-	Delete(id, token string) error
+	GetAll(ctx context.Context, userID uint) ([]models.DetailSaving, error)
+	GetByID(ctx context.Context, id string, userID uint) (models.DetailSaving, error)
+	Create(ctx context.Context, SavingInput models.DetailSavingInput, userID uint, meta models.AuditMeta) (models.DetailSaving, error)
+	Update(ctx context.Context, SavingInput models.DetailSavingInput, id string, userID uint, meta models.AuditMeta) (models.DetailSaving, error)
+	Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error
 }
\ No newline at end of file
@@ -1,141 +1,165 @@
 package repositories
 
 import (
-	"keuangan-pribadi/config"
-	m "keuangan-pribadi/middleware"
+	"context"
 	"keuangan-pribadi/models"
+
+	"gorm.io/gorm"
 )
 
-type SavingRepositoryImpl struct{}
+type SavingRepositoryImpl struct {
+	db *gorm.DB
+}
 
-func InitSavingRepository() SavingRepository {
-	return &SavingRepositoryImpl{}
+func InitSavingRepository(db *gorm.DB) SavingRepository {
+	return &SavingRepositoryImpl{db: db}
 }
 
-func (sr *SavingRepositoryImpl) GetAll(token string) ([]models.Saving, error) {
-	var savings []models.Saving
+func (sr *SavingRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.Saving, error) {
+	db := sr.db.WithContext(ctx)
 
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return []models.Saving{}, err
-    }
+	var savings []models.Saving
 
-	if err := config.DB.Preload("User").Where("user_id = ?", user.ID).Find(&savings).Error; err != nil {
+	if err := db.Preload("User").Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).Find(&savings).Error; err != nil {
 		return nil, err
 	}
 
 	return savings, nil
 }
 
-func (sr *SavingRepositoryImpl) GetByID(id, token string) (models.Saving, error) {
-	var saving models.Saving
+func (sr *SavingRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.Saving, error) {
+	db := sr.db.WithContext(ctx)
 
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.Saving{}, err
-    }
+	var saving models.Saving
 
-	if err := config.DB.Preload("User").First(&saving, "id = ? AND user_id = ?", id, user.ID).Error; err != nil {
-		return models.Saving{}, err
+	if err := db.Preload("User").Where("ledger_id IN (?)", memberLedgerIDs(db, userID)).First(&saving, "id = ?", id).Error; err != nil {
+		return models.Saving{}, notFound(err, "saving")
 	}
 
 	return saving, nil
 }
 
-func (sr *SavingRepositoryImpl) Create(savingInput models.SavingInput, token string) (models.Saving, error) {
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.Saving{}, err
-    }
+func (sr *SavingRepositoryImpl) Create(ctx context.Context, savingInput models.SavingInput, userID uint, meta models.AuditMeta) (models.Saving, error) {
+	db := sr.db.WithContext(ctx)
 
 	var User models.User
-	er := config.DB.Where("id = ?", user.ID).First(&User).Error
+	er := db.Where("id = ?", userID).First(&User).Error
 	if er != nil {
 		return models.Saving{}, er
 	}
 
+	ledgerID, err := targetLedger(db, savingInput.LedgerID, userID)
+	if err != nil {
+		return models.Saving{}, err
+	}
+
 	var createdSaving models.Saving = models.Saving{
 		Name:       	savingInput.Name,
 		Value: 			savingInput.Value,
 		Goal: 			savingInput.Goal,
-		UserID:    		user.ID,
+		UserID:    		userID,
 		User: 			User,
+		LedgerID: 		&ledgerID,
 	}
 
-	result := config.DB.Create(&createdSaving)
+	meta.ActorID = userID
 
-	if err := result.Error; err != nil {
-		return models.Saving{}, err
-	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdSaving).Error; err != nil {
+			return err
+		}
 
-	if err := config.DB.Last(&createdSaving).Error; err != nil {
-		return models.Saving{}, err
-	}
+		if err := tx.Last(&createdSaving).Error; err != nil {
+			return err
+		}
 
-	var createdDetailSaving models.DetailSaving = models.DetailSaving{
-		Value: 			savingInput.Value,
-		Status: 		1,
-		UserID:    		user.ID,
-		User: 			User,
-		SavingID: 		createdSaving.ID,
-		Saving: 		createdSaving,
-	}
+		var createdDetailSaving models.DetailSaving = models.DetailSaving{
+			Value: 			savingInput.Value,
+			Status: 		1,
+			UserID:    		userID,
+			User: 			User,
+			SavingID: 		createdSaving.ID,
+			Saving: 		createdSaving,
+		}
 
-	if err := config.DB.Create(&createdDetailSaving).Error; err != nil{
-		return models.Saving{}, err
-	}
+		if err := tx.Create(&createdDetailSaving).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Last(&createdDetailSaving).Error; err != nil {
+			return err
+		}
+
+		if err := recordAudit(tx, meta, userID, models.AuditActionCreate, "saving", createdSaving.ID, nil, createdSaving); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "detail_saving", createdDetailSaving.ID, nil, createdDetailSaving)
+	})
 
-	if err := config.DB.Last(&createdDetailSaving).Error; err != nil {
+	if err != nil {
 		return models.Saving{}, err
 	}
 
+	emitEvent(db, createdSaving.LedgerID, models.WebhookEventSavingCreated, createdSaving)
+
 	return createdSaving, nil
 }
 
-func (sr *SavingRepositoryImpl) Update(savingUpdate models.SavingUpdate, id, token string) (models.Saving, error) {
-	user, err := m.VerifyToken(token)
-    if err != nil {
-        return models.Saving{}, err
-    }
+func (sr *SavingRepositoryImpl) Update(ctx context.Context, savingUpdate models.SavingUpdate, id string, userID uint, meta models.AuditMeta) (models.Saving, error) {
+	db := sr.db.WithContext(ctx)
 
-	saving, err := sr.GetByID(id, token)
+	saving, err := sr.GetByID(ctx, id, userID)
 	if err != nil {
 		return models.Saving{}, err
 	}
 
-	var User models.User
-	er := config.DB.Where("id = ?", user.ID).First(&User).Error
-	if er != nil {
-		return models.Saving{}, er
+	if err := requireLedgerWrite(db, saving.LedgerID, userID); err != nil {
+		return models.Saving{}, err
 	}
 
+	before := saving
+
 	saving.Name = savingUpdate.Name
 	saving.Goal = savingUpdate.Goal
-	saving.UserID = user.ID
-	saving.User = User
 
-	if err := config.DB.Save(&saving).Error; err != nil {
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&saving).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, saving.UserID, models.AuditActionUpdate, "saving", saving.ID, before, saving)
+	})
+
+	if err != nil {
 		return models.Saving{}, err
 	}
 
 	return saving, nil
 }
 
-func (sr *SavingRepositoryImpl) Delete(id, token string) error {
-	_, err := m.VerifyToken(token)
-    if err != nil {
-        return err
-    }
+func (sr *SavingRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := sr.db.WithContext(ctx)
 
-	saving, err := sr.GetByID(id, token)
+	saving, err := sr.GetByID(ctx, id, userID)
 
 	if err != nil {
 		return err
 	}
 
-	if err := config.DB.Delete(&saving).Error; err != nil {
+	if err := requireLedgerWrite(db, saving.LedgerID, userID); err != nil {
 		return err
 	}
 
-	return nil
+	meta.ActorID = userID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&saving).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, saving.UserID, models.AuditActionDelete, "saving", saving.ID, saving, nil)
+	})
 }
\ No newline at end of file
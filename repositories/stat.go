@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+
+	"gorm.io/gorm"
+)
+
+type StatRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitStatRepository(db *gorm.DB) StatRepository {
+	return &StatRepositoryImpl{db: db}
+}
+
+func (sr *StatRepositoryImpl) GetSummary(ctx context.Context) (models.Stat, error) {
+	db := sr.db.WithContext(ctx)
+
+	var stat models.Stat
+
+	if err := db.Model(&models.User{}).Count(&stat.Users).Error; err != nil {
+		return models.Stat{}, err
+	}
+
+	if err := db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&stat.Admins).Error; err != nil {
+		return models.Stat{}, err
+	}
+
+	if err := db.Model(&models.Category{}).Count(&stat.Categories).Error; err != nil {
+		return models.Stat{}, err
+	}
+
+	if err := db.Model(&models.Finance{}).Count(&stat.Finances).Error; err != nil {
+		return models.Stat{}, err
+	}
+
+	if err := db.Model(&models.Saving{}).Count(&stat.Savings).Error; err != nil {
+		return models.Stat{}, err
+	}
+
+	if err := db.Model(&models.DetailSaving{}).Count(&stat.DetailSavings).Error; err != nil {
+		return models.Stat{}, err
+	}
+
+	return stat, nil
+}
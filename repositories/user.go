@@ -1,20 +1,34 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"keuangan-pribadi/config"
 	m "keuangan-pribadi/middleware"
 	"keuangan-pribadi/models"
+	"keuangan-pribadi/utils"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-type UserRepositoryImpl struct{}
+var errInvalidCredentials = models.UnauthorizedError("invalid_credentials", "authentication failed, invalid Email or Password")
 
-func InitUserRepository() UserRepository {
-	return &UserRepositoryImpl{}
+type UserRepositoryImpl struct {
+	db     *gorm.DB
+	mailer utils.Mailer
+	auth   config.AuthConfig
 }
 
-func (ur *UserRepositoryImpl) Register(userInput models.UserInput) (models.User, error) {
+func InitUserRepository(db *gorm.DB, mailer utils.Mailer, auth config.AuthConfig) UserRepository {
+	return &UserRepositoryImpl{db: db, mailer: mailer, auth: auth}
+}
+
+func (ur *UserRepositoryImpl) Register(ctx context.Context, userInput models.UserInput, meta models.AuditMeta) (models.User, error) {
+	db := ur.db.WithContext(ctx)
+
 	password, err := bcrypt.GenerateFromPassword([]byte(userInput.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return models.User{}, err
@@ -24,38 +38,76 @@ func (ur *UserRepositoryImpl) Register(userInput models.UserInput) (models.User,
 		Name:       userInput.Name,
 		Email: userInput.Email,
 		Password:    string(password),
+		Role: models.RoleUser,
 	}
 
-	result := config.DB.Create(&createdUser)
-
-	if err := result.Error; err != nil {
-		return models.User{}, err
+	// the account configured as ADMIN_EMAIL becomes the first administrator
+	if ur.auth.AdminEmail != "" && ur.auth.AdminEmail == userInput.Email {
+		createdUser.Role = models.RoleAdmin
 	}
 
-	if err := config.DB.Last(&createdUser).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdUser).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Last(&createdUser).Error; err != nil {
+			return err
+		}
+
+		if _, err := PersonalLedger(tx, createdUser.ID); err != nil {
+			return err
+		}
+
+		meta.ActorID = createdUser.ID
+
+		return recordAudit(tx, meta, createdUser.ID, models.AuditActionCreate, "user", createdUser.ID, nil, createdUser)
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.User{}, errEmailInUse
+	}
+	if err != nil {
 		return models.User{}, err
 	}
 
 	return createdUser, nil
 }
 
-func (ur *UserRepositoryImpl) GetByEmail(email string) (models.User, error) {
+func (ur *UserRepositoryImpl) GetAll(ctx context.Context) ([]models.User, error) {
+	db := ur.db.WithContext(ctx)
+
+	var users []models.User
+
+	if err := db.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (ur *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	db := ur.db.WithContext(ctx)
+
 	var user models.User
 
-	err := config.DB.First(&user, "email = ?", email).Error
+	err := db.First(&user, "email = ?", email).Error
 
 	if err != nil {
-		return models.User{}, err
+		return models.User{}, notFound(err, "user")
 	}
 
 	return user, nil
 }
 
-func (ur *UserRepositoryImpl) Login(userInput models.UserAuth) (models.UserResponse, error) {
+func (ur *UserRepositoryImpl) Login(ctx context.Context, userInput models.UserAuth) (models.UserResponse, error) {
 	var user models.User
 
-	user, err := ur.GetByEmail(userInput.Email)
+	user, err := ur.GetByEmail(ctx, userInput.Email)
 
+	if errors.Is(err, models.ErrNotFound) {
+		return models.UserResponse{}, errInvalidCredentials
+	}
 	if err != nil {
 		return models.UserResponse{}, err
 	}
@@ -63,10 +115,10 @@ func (ur *UserRepositoryImpl) Login(userInput models.UserAuth) (models.UserRespo
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(userInput.Password))
 
 	if err != nil {
-		return models.UserResponse{}, err
+		return models.UserResponse{}, errInvalidCredentials
 	}
 
-	token, err := m.CreateToken(user.ID, user.Name)
+	token, err := m.CreateToken(user.ID, user.Name, user.Role)
 	if err != nil {
 		return models.UserResponse{}, err
 	}
@@ -75,32 +127,504 @@ func (ur *UserRepositoryImpl) Login(userInput models.UserAuth) (models.UserRespo
 		ID:    user.ID,
 		Name:  user.Name,
 		Email: user.Email,
+		Role:  user.Role,
 		Token: token,
 	}
 
 	return userResponse, nil
 }
 
-func (ur *UserRepositoryImpl) Update(userInput models.UserInput, token string) (models.User, error) {
-	user, err := m.VerifyToken(token)
+func (ur *UserRepositoryImpl) Update(ctx context.Context, userUpdate models.UserUpdate, userID uint, meta models.AuditMeta) (models.User, error) {
+	db := ur.db.WithContext(ctx)
+
+	user, err := findUser(db, userID)
     if err != nil {
         return models.User{}, err
     }
 
-	password, err := bcrypt.GenerateFromPassword([]byte(userInput.Password), bcrypt.DefaultCost)
+	before := user
+
+	meta.ActorID = user.ID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// only the fields that were sent are changed, the rest of the row is kept
+		if err := tx.Model(&user).Updates(models.User{Name: userUpdate.Name}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, "id = ?", user.ID).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, user.ID, models.AuditActionUpdate, "user", user.ID, before, user)
+	})
+
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (ur *UserRepositoryImpl) ChangePassword(ctx context.Context, passwordInput models.UserChangePassword, userID uint, meta models.AuditMeta) (models.UserResponse, error) {
+	db := ur.db.WithContext(ctx)
+
+	user, err := findUser(db, userID)
+    if err != nil {
+        return models.UserResponse{}, err
+    }
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(passwordInput.CurrentPassword)); err != nil {
+		return models.UserResponse{}, models.ValidationError("incorrect_password", "current password is incorrect")
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(passwordInput.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+
+	// every token issued up to now is revoked, the caller gets a fresh one
+	// issued right after. Milliseconds are stored exactly by every driver
+	revokedAt := time.Now().Truncate(time.Millisecond)
+
+	previousRevokedAt := user.TokensRevokedAt
+
+	meta.ActorID = user.ID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(models.User{Password: string(password), TokensRevokedAt: &revokedAt}).Error; err != nil {
+			return err
+		}
+
+		// the password itself never ends up in the audit log, only the revocation
+		return recordAudit(tx, meta, user.ID, models.AuditActionUpdate, "user", user.ID,
+			map[string]interface{}{"tokens_revoked_at": previousRevokedAt},
+			map[string]interface{}{"tokens_revoked_at": revokedAt},
+		)
+	})
+
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+
+	newToken, err := m.CreateTokenAt(user.ID, user.Name, user.Role, revokedAt.Add(time.Millisecond))
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+
+	userResponse := models.UserResponse{
+		ID:    user.ID,
+		Name:  user.Name,
+		Email: user.Email,
+		Role:  user.Role,
+		Token: newToken,
+	}
+
+	return userResponse, nil
+}
+
+func (ur *UserRepositoryImpl) ChangeEmail(ctx context.Context, emailInput models.UserChangeEmail, userID uint) error {
+	db := ur.db.WithContext(ctx)
+
+	user, err := findUser(db, userID)
+    if err != nil {
+        return err
+    }
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(emailInput.Password)); err != nil {
+		return errIncorrectPassword
+	}
+
+	if _, err := ur.GetByEmail(ctx, emailInput.Email); err == nil {
+		return errEmailInUse
+	}
+
+	verificationToken, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(ur.auth.EmailVerificationTTL)
+
+	if err := db.Model(&user).Updates(models.User{
+		PendingEmail:               emailInput.Email,
+		EmailVerificationToken:     utils.HashToken(verificationToken),
+		EmailVerificationExpiresAt: &expiresAt,
+	}).Error; err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nuse the following token to confirm your new email address:\n\n%s\n\nThe token expires in %s.", user.Name, verificationToken, utils.HumanDuration(ur.auth.EmailVerificationTTL))
+
+	return ur.mailer.Send(emailInput.Email, "Confirm your new email address", body)
+}
+
+func (ur *UserRepositoryImpl) VerifyEmail(ctx context.Context, verifyInput models.UserVerifyEmail, meta models.AuditMeta) (models.User, error) {
+	db := ur.db.WithContext(ctx)
+
+	var user models.User
+
+	err := db.First(&user, "email_verification_token = ? AND email_verification_expires_at > ?", utils.HashToken(verifyInput.Token), time.Now()).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, models.ValidationError("invalid_verification_token", "invalid or expired verification token")
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if _, err := ur.GetByEmail(ctx, user.PendingEmail); err == nil {
+		return models.User{}, errEmailInUse
+	}
+
+	before := user
+
+	meta.ActorID = user.ID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"email":                         user.PendingEmail,
+			"pending_email":                 "",
+			"email_verification_token":      "",
+			"email_verification_expires_at": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, "id = ?", user.ID).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, user.ID, models.AuditActionUpdate, "user", user.ID, before, user)
+	})
+
+	// another account took the address since the check above
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.User{}, errEmailInUse
+	}
 	if err != nil {
 		return models.User{}, err
 	}
 
-	user.Name = userInput.Name
-	user.Email = userInput.Email
-	user.Password = string(password)
+	return user, nil
+}
+
+func (ur *UserRepositoryImpl) UpdateRole(ctx context.Context, roleInput models.UserRole, id string, meta models.AuditMeta) (models.User, error) {
+	db := ur.db.WithContext(ctx)
+
+	var user models.User
+
+	if err := db.First(&user, "id = ?", id).Error; err != nil {
+		return models.User{}, notFound(err, "user")
+	}
+
+	before := user
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", roleInput.Role).Error; err != nil {
+			return err
+		}
 
-	err = config.DB.Save(&user).Error
+		return recordAudit(tx, meta, user.ID, models.AuditActionUpdate, "user", user.ID, before, user)
+	})
 
 	if err != nil {
 		return models.User{}, err
 	}
 
 	return user, nil
-}
\ No newline at end of file
+}
+
+func (ur *UserRepositoryImpl) Delete(ctx context.Context, id string, adminID uint, meta models.AuditMeta) error {
+	db := ur.db.WithContext(ctx)
+
+	var user models.User
+
+	if err := db.First(&user, "id = ?", id).Error; err != nil {
+		return notFound(err, "user")
+	}
+
+	// the own account is deleted with the password, through DeleteAccount
+	if user.ID == adminID {
+		return models.ForbiddenError("cannot_delete_self", "admins cannot delete their own account here")
+	}
+
+	meta.ActorID = adminID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteUser(tx, user); err != nil {
+			return err
+		}
+
+		// the deletion is kept without a snapshot of the account, like the
+		// rest of its audit log
+		return recordAudit(tx, meta, 0, models.AuditActionDelete, "user", user.ID, nil, nil)
+	})
+}
+
+// Export is the content of the user's personal ledger, the one a backup is
+// restored into. Shared ledgers belong to their household and are left out.
+func (ur *UserRepositoryImpl) Export(ctx context.Context, userID uint) (models.UserExport, error) {
+	db := ur.db.WithContext(ctx)
+
+	user, err := findUser(db, userID)
+    if err != nil {
+        return models.UserExport{}, err
+    }
+
+	ledger, err := PersonalLedger(db, user.ID)
+	if err != nil {
+		return models.UserExport{}, err
+	}
+
+	export := models.UserExport{User: user}
+
+	if err := db.Where("ledger_id = ?", ledger.ID).Find(&export.Finances).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	// categories are shared, only the ones used in the ledger are exported
+	financeCategories := db.Model(&models.Finance{}).Select("category_id").Where("ledger_id = ?", ledger.ID)
+	billCategories := db.Model(&models.Bill{}).Select("category_id").Where("ledger_id = ?", ledger.ID)
+
+	if err := db.Where("id IN (?) OR id IN (?)", financeCategories, billCategories).Find(&export.Categories).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	if err := db.Where("ledger_id = ?", ledger.ID).Find(&export.Savings).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	if err := db.Where("saving_id IN (?)", db.Model(&models.Saving{}).Select("id").Where("ledger_id = ?", ledger.ID)).Find(&export.DetailSavings).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	if err := db.Where("ledger_id = ?", ledger.ID).Find(&export.Debts).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	if err := db.Where("debt_id IN (?)", db.Model(&models.Debt{}).Select("id").Where("ledger_id = ?", ledger.ID)).Find(&export.DebtRepayments).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	if err := db.Where("ledger_id = ?", ledger.ID).Find(&export.Bills).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	if err := db.Preload("Deposits").Where("ledger_id = ?", ledger.ID).Find(&export.GoldSavings).Error; err != nil {
+		return models.UserExport{}, err
+	}
+
+	return export, nil
+}
+
+func (ur *UserRepositoryImpl) DeleteAccount(ctx context.Context, deleteInput models.UserDeleteAccount, userID uint) error {
+	db := ur.db.WithContext(ctx)
+
+	user, err := findUser(db, userID)
+    if err != nil {
+        return err
+    }
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(deleteInput.Password)); err != nil {
+		return errIncorrectPassword
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return deleteUser(tx, user)
+	})
+}
+
+// deleteUser deletes an account and the data only it can reach. Its entries
+// in shared ledgers are handed over and the audit log no longer names it.
+func deleteUser(tx *gorm.DB, user models.User) error {
+	if err := leaveLedgers(tx, user.ID); err != nil {
+		return err
+	}
+
+	webhooks := tx.Model(&models.Webhook{}).Select("id").Where("user_id = ?", user.ID)
+
+	if err := tx.Where("webhook_id IN (?)", webhooks).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+
+	// every entry in a ledger that still exists was handed over above,
+	// what is left of the user's entries was in ledgers that are gone
+	ledgers := tx.Model(&models.Ledger{}).Select("id")
+
+	children := []struct {
+		model  interface{}
+		parent interface{}
+		column string
+	}{
+		{&models.DetailSaving{}, &models.Saving{}, "saving_id"},
+		{&models.GoldDeposit{}, &models.GoldSaving{}, "gold_saving_id"},
+		{&models.DebtRepayment{}, &models.Debt{}, "debt_id"},
+	}
+
+	for _, child := range children {
+		gone := tx.Unscoped().Model(child.parent).Select("id").Where("ledger_id IS NULL OR ledger_id NOT IN (?)", ledgers)
+		owned := tx.Unscoped().Model(child.parent).Select("id").Where("user_id = ? AND (ledger_id IS NULL OR ledger_id NOT IN (?))", user.ID, ledgers)
+
+		if err := tx.Unscoped().Where("(user_id = ? AND "+child.column+" IN (?)) OR "+child.column+" IN (?)", user.ID, gone, owned).Delete(child.model).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{&models.Debt{}, &models.Bill{}, &models.Finance{}, &models.Saving{}, &models.GoldSaving{}} {
+		if err := tx.Unscoped().Where("user_id = ? AND (ledger_id IS NULL OR ledger_id NOT IN (?))", user.ID, ledgers).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{&models.PersonalAccessToken{}, &models.Notification{}, &models.Webhook{}} {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := anonymizeAuditLogs(tx, user.ID); err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Delete(&user).Error
+}
+
+// anonymizeAuditLogs keeps the audit trail of a deleted account but cuts it
+// loose from the person. The rows stay so the history of data that outlives
+// the account, like entries handed over in a shared ledger, is complete;
+// they no longer belong to the user, the changes the user made no longer
+// name them nor say where they came from, and the snapshots of the account
+// itself, with its name and email, are emptied.
+func anonymizeAuditLogs(tx *gorm.DB, userID uint) error {
+	err := tx.Model(&models.AuditLog{}).Where("owner_id = ? AND entity_type = ? AND entity_id = ?", userID, "user", userID).Updates(map[string]interface{}{
+		"before":  nil,
+		"after":   nil,
+		"changes": nil,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.AuditLog{}).Where("owner_id = ?", userID).Update("owner_id", 0).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.AuditLog{}).Where("actor_id = ?", userID).Updates(map[string]interface{}{
+		"actor_id":   0,
+		"ip":         "",
+		"user_agent": "",
+	}).Error
+}
+
+// ledgerModels are the entries stored in a ledger, handed over as a whole.
+var ledgerModels = []interface{}{&models.Finance{}, &models.Saving{}, &models.GoldSaving{}, &models.Debt{}, &models.Bill{}}
+
+// leaveLedgers removes the user from every ledger. A ledger nobody else is
+// left in is deleted along with its data. In the others the entries the
+// user made stay for the household and are handed over to the owner, who is
+// the longest standing member when the user owned the ledger. Entries left
+// in ledgers the user was removed from earlier go to their owner as well.
+func leaveLedgers(tx *gorm.DB, userID uint) error {
+	var memberships []models.LedgerMember
+
+	if err := tx.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		return err
+	}
+
+	for _, membership := range memberships {
+		var owner models.LedgerMember
+
+		query := tx.Where("ledger_id = ? AND user_id <> ?", membership.LedgerID, userID)
+		if membership.Role != models.LedgerRoleOwner {
+			query = query.Where("role = ?", models.LedgerRoleOwner)
+		}
+
+		err := query.Order("created_at, id").First(&owner).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := deleteLedgerData(tx, membership.LedgerID); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&owner).Update("role", models.LedgerRoleOwner).Error; err != nil {
+			return err
+		}
+
+		if err := handOver(tx, userID, membership.LedgerID, owner.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.LedgerMember{}).Error; err != nil {
+		return err
+	}
+
+	for _, model := range ledgerModels {
+		var ledgerIDs []uint
+
+		if err := tx.Unscoped().Model(model).Distinct().Where("user_id = ? AND ledger_id IN (?)", userID, tx.Model(&models.Ledger{}).Select("id")).Pluck("ledger_id", &ledgerIDs).Error; err != nil {
+			return err
+		}
+
+		for _, ledgerID := range ledgerIDs {
+			var owner models.LedgerMember
+
+			if err := tx.First(&owner, "ledger_id = ? AND role = ?", ledgerID, models.LedgerRoleOwner).Error; err != nil {
+				return err
+			}
+
+			if err := handOver(tx, userID, ledgerID, owner.UserID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Where("invited_by_id = ?", userID).Delete(&models.LedgerInvitation{}).Error
+}
+
+// handOver gives the entries the user made in a ledger to another member,
+// deleted ones included so none of them still points at the user.
+func handOver(tx *gorm.DB, userID, ledgerID, ownerID uint) error {
+	savings := tx.Unscoped().Model(&models.Saving{}).Select("id").Where("ledger_id = ?", ledgerID)
+
+	if err := tx.Unscoped().Model(&models.DetailSaving{}).Where("user_id = ? AND saving_id IN (?)", userID, savings).Update("user_id", ownerID).Error; err != nil {
+		return err
+	}
+
+	goldSavings := tx.Unscoped().Model(&models.GoldSaving{}).Select("id").Where("ledger_id = ?", ledgerID)
+
+	if err := tx.Unscoped().Model(&models.GoldDeposit{}).Where("user_id = ? AND gold_saving_id IN (?)", userID, goldSavings).Update("user_id", ownerID).Error; err != nil {
+		return err
+	}
+
+	debts := tx.Unscoped().Model(&models.Debt{}).Select("id").Where("ledger_id = ?", ledgerID)
+
+	if err := tx.Unscoped().Model(&models.DebtRepayment{}).Where("user_id = ? AND debt_id IN (?)", userID, debts).Update("user_id", ownerID).Error; err != nil {
+		return err
+	}
+
+	for _, model := range ledgerModels {
+		if err := tx.Unscoped().Model(model).Where("user_id = ? AND ledger_id = ?", userID, ledgerID).Update("user_id", ownerID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findUser loads the user making the request, for the fields the principal
+// does not carry.
+func findUser(db *gorm.DB, userID uint) (models.User, error) {
+	var user models.User
+
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return models.User{}, notFound(err, "user")
+	}
+
+	return user, nil
+}
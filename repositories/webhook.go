@@ -0,0 +1,347 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"keuangan-pribadi/metrics"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/utils"
+	"log/slog"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func InitWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &WebhookRepositoryImpl{db: db}
+}
+
+func (wr *WebhookRepositoryImpl) GetAll(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	db := wr.db.WithContext(ctx)
+
+	var webhooks []models.Webhook
+
+	if err := db.Where("user_id = ?", userID).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (wr *WebhookRepositoryImpl) GetByID(ctx context.Context, id string, userID uint) (models.Webhook, error) {
+	db := wr.db.WithContext(ctx)
+
+	var webhook models.Webhook
+
+	if err := db.First(&webhook, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return models.Webhook{}, notFound(err, "webhook")
+	}
+
+	return webhook, nil
+}
+
+func (wr *WebhookRepositoryImpl) Create(ctx context.Context, webhookInput models.WebhookInput, userID uint, meta models.AuditMeta) (models.WebhookResponse, error) {
+	db := wr.db.WithContext(ctx)
+
+	if err := validateWebhookEvents(webhookInput.Events); err != nil {
+		return models.WebhookResponse{}, err
+	}
+
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+
+	var createdWebhook models.Webhook = models.Webhook{
+		URL:         webhookInput.URL,
+		Description: webhookInput.Description,
+		Secret:      secret,
+		Events:      webhookInput.Events,
+		Active:      true,
+		UserID:      userID,
+	}
+
+	if webhookInput.Active != nil {
+		createdWebhook.Active = *webhookInput.Active
+	}
+
+	meta.ActorID = userID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createdWebhook).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, userID, models.AuditActionCreate, "webhook", createdWebhook.ID, nil, createdWebhook)
+	})
+
+	if err != nil {
+		return models.WebhookResponse{}, err
+	}
+
+	// the secret is needed to verify signatures, it is only shown once
+	return models.WebhookResponse{
+		Webhook: createdWebhook,
+		Secret:  secret,
+	}, nil
+}
+
+func (wr *WebhookRepositoryImpl) Update(ctx context.Context, webhookInput models.WebhookInput, id string, userID uint, meta models.AuditMeta) (models.Webhook, error) {
+	db := wr.db.WithContext(ctx)
+
+	webhook, err := wr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	if err := validateWebhookEvents(webhookInput.Events); err != nil {
+		return models.Webhook{}, err
+	}
+
+	before := webhook
+
+	webhook.URL = webhookInput.URL
+	webhook.Description = webhookInput.Description
+	webhook.Events = webhookInput.Events
+
+	if webhookInput.Active != nil {
+		webhook.Active = *webhookInput.Active
+	}
+
+	meta.ActorID = webhook.UserID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&webhook).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, webhook.UserID, models.AuditActionUpdate, "webhook", webhook.ID, before, webhook)
+	})
+
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (wr *WebhookRepositoryImpl) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	db := wr.db.WithContext(ctx)
+
+	webhook, err := wr.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	meta.ActorID = webhook.UserID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&webhook).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(tx, meta, webhook.UserID, models.AuditActionDelete, "webhook", webhook.ID, webhook, nil)
+	})
+}
+
+func (wr *WebhookRepositoryImpl) GetDeliveries(ctx context.Context, id string, userID uint) ([]models.WebhookDelivery, error) {
+	db := wr.db.WithContext(ctx)
+
+	var deliveries []models.WebhookDelivery
+
+	webhook, err := wr.GetByID(ctx, id, userID)
+	if err != nil {
+		return []models.WebhookDelivery{}, err
+	}
+
+	if err := db.Where("webhook_id = ?", webhook.ID).Order("created_at DESC").Limit(100).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (wr *WebhookRepositoryImpl) CreateTestDelivery(ctx context.Context, id string, userID uint) (models.WebhookDelivery, error) {
+	db := wr.db.WithContext(ctx)
+
+	webhook, err := wr.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery, err := newWebhookDelivery(webhook, models.WebhookEventTest, map[string]interface{}{"webhook_id": webhook.ID})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	if err := db.Create(&delivery).Error; err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	delivery.Webhook = webhook
+
+	return delivery, nil
+}
+
+// ClaimDueDeliveries returns the pending deliveries whose next attempt is due
+// and claims them by moving their next attempt to the end of the lease, so
+// an instance polling at the same time skips them. A claimed delivery that
+// is never attempted, because its instance stopped, is due again once the
+// lease is over.
+func (wr *WebhookRepositoryImpl) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	db := wr.db.WithContext(ctx)
+
+	var due []models.WebhookDelivery
+
+	if err := db.Preload("Webhook").Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now.UTC()).Order("next_attempt_at").Limit(limit).Find(&due).Error; err != nil {
+		return nil, err
+	}
+
+	leaseEnd := now.Add(lease).UTC()
+
+	var deliveries []models.WebhookDelivery
+
+	for _, delivery := range due {
+		// only one instance still finds the delivery due
+		result := db.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, models.WebhookDeliveryPending, now.UTC()).
+			Update("next_attempt_at", leaseEnd)
+
+		if result.Error != nil {
+			return deliveries, result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		delivery.NextAttemptAt = &leaseEnd
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// SaveAttempt stores the outcome of a delivery attempt.
+func (wr *WebhookRepositoryImpl) SaveAttempt(ctx context.Context, delivery models.WebhookDelivery) error {
+	db := wr.db.WithContext(ctx)
+
+	return db.Model(&delivery).Select("status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "error").Updates(&delivery).Error
+}
+
+func validateWebhookEvents(events []string) error {
+	for _, event := range events {
+		if event == "*" {
+			continue
+		}
+
+		known := false
+		for _, webhookEvent := range models.WebhookEvents {
+			if event == webhookEvent {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return models.ValidationError("unknown_event", fmt.Sprintf("unknown event %q", event))
+		}
+	}
+
+	return nil
+}
+
+func newWebhookDelivery(webhook models.Webhook, event string, data interface{}) (models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+
+	payload, err := json.Marshal(models.WebhookPayload{Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		Event:         event,
+		Payload:       payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+// emitEvent announces a domain event once its change is committed: it is
+// counted in the metrics and, when webhooks can subscribe to it, queued for
+// them.
+func emitEvent(db *gorm.DB, ledgerID *uint, event string, data interface{}) {
+	metrics.RecordEvent(event)
+
+	if slices.Contains(models.WebhookEvents, event) {
+		queueWebhookEvent(db, ledgerID, event, data)
+	}
+}
+
+// queueWebhookEvent queues a delivery for every active webhook of the members
+// of the ledger that is subscribed to the event. Unlike the audit log, a
+// failure is logged and never fails the change itself.
+func queueWebhookEvent(db *gorm.DB, ledgerID *uint, event string, data interface{}) {
+	if ledgerID == nil {
+		return
+	}
+
+	var webhooks []models.Webhook
+
+	if err := db.Where("active = ? AND user_id IN (?)", true, db.Model(&models.LedgerMember{}).Select("user_id").Where("ledger_id = ?", *ledgerID)).Find(&webhooks).Error; err != nil {
+		slog.ErrorContext(db.Statement.Context, "error when queueing webhook event", "event", event, "error", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(event) {
+			continue
+		}
+
+		delivery, err := newWebhookDelivery(webhook, event, webhookData(data))
+		if err != nil {
+			slog.ErrorContext(db.Statement.Context, "error when queueing webhook event", "event", event, "error", err)
+			continue
+		}
+
+		if err := db.Create(&delivery).Error; err != nil {
+			slog.ErrorContext(db.Statement.Context, "error when queueing webhook event", "event", event, "error", err)
+		}
+	}
+}
+
+// webhookData flattens an entity for a payload. Related records, the nested
+// objects with an id, are left out so nothing like the password hash of a
+// preloaded user leaves the server. Amounts of money stay.
+func webhookData(entity interface{}) map[string]interface{} {
+	raw, err := json.Marshal(entity)
+	if err != nil {
+		return nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil
+	}
+
+	for key, value := range data {
+		if nested, ok := value.(map[string]interface{}); ok && nested["id"] != nil {
+			delete(data, key)
+		}
+	}
+
+	delete(data, "password")
+
+	return data
+}
@@ -1,68 +1,213 @@
 package route
 
 import (
+	"keuangan-pribadi/config"
 	"keuangan-pribadi/controllers"
+	"keuangan-pribadi/marketdata"
+	"keuangan-pribadi/metrics"
 	m "keuangan-pribadi/middleware"
-	"keuangan-pribadi/utils"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/services"
+	"time"
 
 	"github.com/labstack/echo/v4"
-	mid "github.com/labstack/echo/v4/middleware"
+	"gorm.io/gorm"
 )
 
-func New() *echo.Echo {
+func New(db *gorm.DB, cfg config.Config) *echo.Echo {
 	// create a new echo instance
 	e := echo.New()
 
-	loggerConfig := m.LoggerConfig{
-		Format: "[${time_rfc3339}] ${status} ${method} ${host} ${path} ${latency_human}" + "\n",
-	}
-	loggerMiddleware := loggerConfig.Init()
-	e.Use(loggerMiddleware)
+	// errors returned by handlers and middleware become failed responses
+	e.HTTPErrorHandler = controllers.HTTPErrorHandler
+
+	// the logs are structured, the banner would be the only plain line
+	e.HideBanner = true
+	e.HidePort = true
+
+	// the client IP address is only taken from X-Forwarded-For when the
+	// request came through a trusted proxy
+	e.IPExtractor = cfg.Server.IPExtractor()
+
+	e.Use(m.RequestID())
+	e.Use(m.AccessLog())
+	e.Use(m.Metrics())
+	e.Use(m.RequestTimeout(cfg.Server.RequestTimeout))
 
 	
+	// rate limits are configured per route group and share one store
+	rateLimitStore := m.NewMemoryStore()
+	authLimit := m.RateLimit(m.RateLimitConfig{
+		Store:  rateLimitStore,
+		Prefix: "auth",
+		Limit:  10,
+		Window: time.Minute,
+	})
+
 	v1 := e.Group("/api/v1")
 	eJwt := v1.Group("")
-	eJwt.Use(mid.JWT([]byte(utils.GetConfig("JWT_SECRET_KEY"))))
+	// authenticating costs a database lookup, so the addresses are limited
+	// before it, generously enough for a household behind one address
+	eJwt.Use(m.RateLimit(m.RateLimitConfig{
+		Store:  rateLimitStore,
+		Prefix: "api-ip",
+		Limit:  600,
+		Window: time.Minute,
+	}))
+	eJwt.Use(m.Authenticate(db))
+	eJwt.Use(m.RateLimit(m.RateLimitConfig{
+		Store:   rateLimitStore,
+		Prefix:  "api",
+		Limit:   300,
+		Window:  time.Minute,
+		KeyFunc: m.KeyByUser,
+	}))
+	isAdmin := m.RequireRole(models.RoleAdmin)
+	eAdmin := eJwt.Group("/admin")
+	eAdmin.Use(isAdmin)
+
+	// probes and metrics for the deployment, outside of the versioned API
+	health := controllers.InitHealthController(services.InitHealthService(repositories.InitHealthRepository(db)))
+	e.GET("/healthz", health.Live)
+	e.GET("/readyz", health.Ready)
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	e.GET("/api/docs", controllers.GetDocs)
+	e.GET("/api/docs/openapi.json", controllers.GetOpenAPI)
 
-	coffe := controllers.GetCoffeePrice
-	v1.GET("/coffee", coffe)
+	// one cache for every price, the providers allow only a few calls a day
+	marketData := marketdata.NewCache(cfg.MarketData.NewProvider(), repositories.InitPriceRepository(db), cfg.MarketData.CacheTTL)
+
+	price := controllers.InitPriceController(services.InitPriceService(marketData))
+	v1.GET("/coffee", price.GetCoffeePrice)
+	v1.GET("/commodities/:symbol", price.GetCommodity)
 
 	// Route / to handler function
-	user := controllers.InitUserController()
-	v1.POST("/users/login", user.Login)
-	v1.POST("/users/register", user.Register)
-	eJwt.GET("/users/:email", user.GetByEmail)
-	eJwt.PUT("/users", user.Update)
-
-	category := controllers.InitCategoryController()
-	eJwt.GET("/categories", category.GetAll)
-	eJwt.GET("/categories/:id", category.GetByID)
-	eJwt.POST("/categories", category.Create)
-	eJwt.PUT("/categories/:id", category.Update)
-	eJwt.DELETE("/categories/:id", category.Delete)
+	user := controllers.InitUserController(services.InitUserService(repositories.InitUserRepository(db, cfg.Mail.Mailer(), cfg.Auth)), cfg.Login.Throttle(rateLimitStore))
+	userScope := m.RequireScope("users")
+	v1.POST("/users/login", user.Login, authLimit)
+	v1.POST("/users/register", user.Register, authLimit)
+	eJwt.GET("/users/:email", user.GetByEmail, userScope)
+	eJwt.PUT("/users", user.Update, userScope)
+	eJwt.PUT("/users/password", user.ChangePassword, userScope)
+	eJwt.PUT("/users/email", user.ChangeEmail, userScope)
+	eJwt.GET("/users/me/export", user.Export, userScope)
+	eJwt.DELETE("/users/me", user.DeleteAccount, userScope)
+	v1.POST("/users/email/verify", user.VerifyEmail, authLimit)
+	eAdmin.GET("/users", user.GetAll, userScope)
+	eAdmin.PUT("/users/:id/role", user.UpdateRole, userScope)
+	eAdmin.DELETE("/users/:id", user.Delete, userScope)
 
-	
-	finance := controllers.InitFinanceController()
-	eJwt.GET("/finances", finance.GetAll)
-	eJwt.GET("/finances/:id", finance.GetByID)
-	eJwt.GET("/finances/search", finance.Search)
-	eJwt.POST("/finances", finance.Create)
-	eJwt.PUT("/finances/:id", finance.Update)
-	eJwt.DELETE("/finances/:id", finance.Delete)
-
-	saving := controllers.InitSavingController()
-	eJwt.GET("/savings", saving.GetAll)
-	eJwt.GET("/savings/:id", saving.GetByID)
-	eJwt.POST("/savings", saving.Create)
-	eJwt.PUT("/savings/:id", saving.Update)
-	eJwt.DELETE("/savings/:id", saving.Delete)
-
-	detailSaving := controllers.InitDetailSavingController()
-	eJwt.GET("/detail-savings", detailSaving.GetAll)
-	eJwt.GET("/detail-savings/:id", detailSaving.GetByID)
-	eJwt.POST("/detail-savings", detailSaving.Create)
-	eJwt.PUT("/detail-savings/:id", detailSaving.Update)
-	eJwt.DELETE("/detail-savings/:id", detailSaving.Delete)
+	stat := controllers.InitStatController(services.InitStatService(repositories.InitStatRepository(db)))
+	eAdmin.GET("/stats", stat.GetSummary, userScope)
+
+	personalAccessToken := controllers.InitPersonalAccessTokenController(services.InitPersonalAccessTokenService(repositories.InitPersonalAccessTokenRepository(db)))
+	tokenScope := m.RequireScope("tokens")
+	eJwt.GET("/tokens", personalAccessToken.GetAll, tokenScope)
+	eJwt.POST("/tokens", personalAccessToken.Create, tokenScope)
+	eJwt.DELETE("/tokens/:id", personalAccessToken.Delete, tokenScope)
+
+	backup := controllers.InitBackupController(services.InitBackupService(repositories.InitBackupRepository(db)))
+	backupScope := m.RequireScope("backups")
+	eJwt.GET("/backups", backup.Create, backupScope)
+	eJwt.POST("/backups/restore", backup.Restore, backupScope)
+
+	category := controllers.InitCategoryController(services.InitCategoryService(repositories.InitCategoryRepository(db)))
+	categoryScope := m.RequireScope("categories")
+	eJwt.GET("/categories", category.GetAll, categoryScope)
+	eJwt.GET("/categories/:id", category.GetByID, categoryScope)
+	eJwt.POST("/categories", category.Create, isAdmin, categoryScope)
+	eJwt.PUT("/categories/:id", category.Update, isAdmin, categoryScope)
+	eJwt.DELETE("/categories/:id", category.Delete, isAdmin, categoryScope)
+
+	ledger := controllers.InitLedgerController(services.InitLedgerService(repositories.InitLedgerRepository(db, cfg.Mail.Mailer(), cfg.Auth)))
+	ledgerScope := m.RequireScope("ledgers")
+	eJwt.GET("/ledgers", ledger.GetAll, ledgerScope)
+	eJwt.POST("/ledgers", ledger.Create, ledgerScope)
+	eJwt.POST("/ledgers/invitations/accept", ledger.AcceptInvitation, ledgerScope)
+	eJwt.GET("/ledgers/:id", ledger.GetByID, ledgerScope)
+	eJwt.PUT("/ledgers/:id", ledger.Update, ledgerScope)
+	eJwt.DELETE("/ledgers/:id", ledger.Delete, ledgerScope)
+	eJwt.PUT("/ledgers/:id/members/:user_id", ledger.UpdateMember, ledgerScope)
+	eJwt.DELETE("/ledgers/:id/members/:user_id", ledger.RemoveMember, ledgerScope)
+	eJwt.POST("/ledgers/:id/invitations", ledger.Invite, ledgerScope)
+	eJwt.GET("/ledgers/:id/categories", ledger.GetCategories, ledgerScope, categoryScope)
+	eJwt.POST("/ledgers/:id/categories", ledger.CreateCategory, ledgerScope, categoryScope)
+
+	auditLog := controllers.InitAuditLogController(services.InitAuditLogService(repositories.InitAuditLogRepository(db)))
+	eJwt.GET("/audit", auditLog.GetAll, m.RequireScope("audit"))
+
+	finance := controllers.InitFinanceController(services.InitFinanceService(repositories.InitFinanceRepository(db)))
+	financeScope := m.RequireScope("finances")
+	eJwt.GET("/finances", finance.GetAll, financeScope)
+	eJwt.GET("/finances/:id", finance.GetByID, financeScope)
+	eJwt.GET("/finances/search", finance.Search, financeScope)
+	eJwt.POST("/finances", finance.Create, financeScope)
+	eJwt.PUT("/finances/:id", finance.Update, financeScope)
+	eJwt.DELETE("/finances/:id", finance.Delete, financeScope)
+
+	saving := controllers.InitSavingController(services.InitSavingService(repositories.InitSavingRepository(db)))
+	savingScope := m.RequireScope("savings")
+	eJwt.GET("/savings", saving.GetAll, savingScope)
+	eJwt.GET("/savings/:id", saving.GetByID, savingScope)
+	eJwt.POST("/savings", saving.Create, savingScope)
+	eJwt.PUT("/savings/:id", saving.Update, savingScope)
+	eJwt.DELETE("/savings/:id", saving.Delete, savingScope)
+
+	detailSaving := controllers.InitDetailSavingController(services.InitDetailSavingService(repositories.InitDetailSavingRepository(db)))
+	detailSavingScope := m.RequireScope("detail-savings")
+	eJwt.GET("/detail-savings", detailSaving.GetAll, detailSavingScope)
+	eJwt.GET("/detail-savings/:id", detailSaving.GetByID, detailSavingScope)
+	eJwt.POST("/detail-savings", detailSaving.Create, detailSavingScope)
+	eJwt.PUT("/detail-savings/:id", detailSaving.Update, detailSavingScope)
+	eJwt.DELETE("/detail-savings/:id", detailSaving.Delete, detailSavingScope)
+
+	goldSaving := controllers.InitGoldSavingController(services.InitGoldSavingService(repositories.InitGoldSavingRepository(db), marketData))
+	goldSavingScope := m.RequireScope("gold-savings")
+	eJwt.GET("/gold-savings", goldSaving.GetAll, goldSavingScope)
+	eJwt.GET("/gold-savings/:id", goldSaving.GetByID, goldSavingScope)
+	eJwt.POST("/gold-savings", goldSaving.Create, goldSavingScope)
+	eJwt.PUT("/gold-savings/:id", goldSaving.Update, goldSavingScope)
+	eJwt.DELETE("/gold-savings/:id", goldSaving.Delete, goldSavingScope)
+	eJwt.POST("/gold-savings/:id/deposits", goldSaving.Deposit, goldSavingScope)
+	eJwt.DELETE("/gold-savings/:id/deposits/:deposit_id", goldSaving.DeleteDeposit, goldSavingScope)
+	eJwt.GET("/gold-savings/:id/valuation", goldSaving.GetValuation, goldSavingScope)
+
+	debt := controllers.InitDebtController(services.InitDebtService(repositories.InitDebtRepository(db)))
+	debtScope := m.RequireScope("debts")
+	eJwt.GET("/debts", debt.GetAll, debtScope)
+	eJwt.GET("/debts/overdue", debt.GetOverdue, debtScope)
+	eJwt.GET("/debts/summary", debt.GetSummary, debtScope)
+	eJwt.GET("/debts/:id", debt.GetByID, debtScope)
+	eJwt.POST("/debts", debt.Create, debtScope)
+	eJwt.PUT("/debts/:id", debt.Update, debtScope)
+	eJwt.DELETE("/debts/:id", debt.Delete, debtScope)
+	eJwt.POST("/debts/:id/repayments", debt.Repay, debtScope, financeScope)
+
+	bill := controllers.InitBillController(services.InitBillService(repositories.InitBillRepository(db)))
+	billScope := m.RequireScope("bills")
+	eJwt.GET("/bills", bill.GetAll, billScope)
+	eJwt.GET("/bills/:id", bill.GetByID, billScope)
+	eJwt.POST("/bills", bill.Create, billScope)
+	eJwt.PUT("/bills/:id", bill.Update, billScope)
+	eJwt.DELETE("/bills/:id", bill.Delete, billScope)
+	eJwt.POST("/bills/:id/pay", bill.Pay, billScope, financeScope)
+
+	notification := controllers.InitNotificationController(services.InitNotificationService(repositories.InitNotificationRepository(db)))
+	notificationScope := m.RequireScope("notifications")
+	eJwt.GET("/notifications", notification.GetAll, notificationScope)
+	eJwt.PUT("/notifications/read", notification.MarkAllRead, notificationScope)
+	eJwt.PUT("/notifications/:id/read", notification.MarkRead, notificationScope)
+
+	webhook := controllers.InitWebhookController(services.InitWebhookService(repositories.InitWebhookRepository(db), cfg.Webhook.AllowPrivateTargets))
+	webhookScope := m.RequireScope("webhooks")
+	eJwt.GET("/webhooks", webhook.GetAll, webhookScope)
+	eJwt.POST("/webhooks", webhook.Create, webhookScope)
+	eJwt.PUT("/webhooks/:id", webhook.Update, webhookScope)
+	eJwt.DELETE("/webhooks/:id", webhook.Delete, webhookScope)
+	eJwt.GET("/webhooks/:id/deliveries", webhook.GetDeliveries, webhookScope)
+	eJwt.POST("/webhooks/:id/test", webhook.Test, webhookScope)
 
 	return e
 }
\ No newline at end of file
@@ -0,0 +1,133 @@
+package route
+
+import (
+	"fmt"
+	"keuangan-pribadi/config"
+	"keuangan-pribadi/docs"
+	"keuangan-pribadi/migrations"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// registeredRoutes are the routes of route.New as "METHOD /path" in the
+// OpenAPI form. The catch-all routes echo adds for group middleware are left
+// out.
+func registeredRoutes(t *testing.T) map[string]bool {
+	db, err := config.OpenDB(config.DriverSQLite, "file::memory:", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notFound := runtime.FuncForPC(reflect.ValueOf(echo.NotFoundHandler).Pointer()).Name()
+
+	routes := map[string]bool{}
+
+	for _, route := range New(db, config.Config{}).Routes() {
+		if route.Name == notFound {
+			continue
+		}
+
+		routes[route.Method+" "+docs.OpenAPIPath(route.Path)] = true
+	}
+
+	return routes
+}
+
+// documentedRoutes are the operations of the OpenAPI document in the same
+// form.
+func documentedRoutes() map[string]bool {
+	routes := map[string]bool{}
+
+	for path, item := range docs.Spec().Paths {
+		for method := range item {
+			routes[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	return routes
+}
+
+func TestOpenAPIRoutes_Success(t *testing.T) {
+	documented := documentedRoutes()
+
+	for route := range registeredRoutes(t) {
+		assert.True(t, documented[route], "%s is missing from the OpenAPI document", route)
+	}
+}
+
+func TestOpenAPIRoutes_Failed(t *testing.T) {
+	registered := registeredRoutes(t)
+
+	// an operation without a route means the document is out of date
+	for route := range documentedRoutes() {
+		assert.True(t, registered[route], "%s is documented but not registered", route)
+	}
+}
+
+// loginServer is route.New on a migrated database with a login lockout per
+// IP address after two failures.
+func loginServer(t *testing.T, trustedProxies ...string) *echo.Echo {
+	db, err := config.OpenDB(config.DriverSQLite, "file::memory:", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{
+		Server: config.ServerConfig{RequestTimeout: time.Minute, TrustedProxies: trustedProxies},
+		Login: config.LoginConfig{
+			MaxAccountAttempts: 10,
+			MaxIPAttempts:      2,
+			BaseLockout:        time.Minute,
+			MaxLockout:         time.Hour,
+			Window:             time.Hour,
+		},
+	}
+
+	return New(db, cfg)
+}
+
+// login fails a login for email from remoteAddr, claiming to be forwardedFor.
+func login(e *echo.Echo, email, remoteAddr, forwardedFor string) int {
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", strings.NewReader(fmt.Sprintf(`{"email":%q,"password":"wrongsecret"}`, email)))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+	request.RemoteAddr = remoteAddr
+
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+func TestLoginThrottle_SpoofedForwardedFor(t *testing.T) {
+	e := loginServer(t)
+
+	// a client cannot pick a fresh address for every attempt
+	assert.Equal(t, http.StatusUnauthorized, login(e, "first@gmail.com", "203.0.113.7:4000", "198.51.100.1"))
+	assert.Equal(t, http.StatusUnauthorized, login(e, "second@gmail.com", "203.0.113.7:4000", "198.51.100.2"))
+	assert.Equal(t, http.StatusTooManyRequests, login(e, "third@gmail.com", "203.0.113.7:4000", "198.51.100.3"))
+}
+
+func TestLoginThrottle_TrustedProxy(t *testing.T) {
+	e := loginServer(t, "10.0.0.0/8")
+
+	// behind a trusted proxy every client is counted on its own
+	assert.Equal(t, http.StatusUnauthorized, login(e, "first@gmail.com", "10.0.0.2:4000", "198.51.100.1"))
+	assert.Equal(t, http.StatusUnauthorized, login(e, "second@gmail.com", "10.0.0.2:4000", "198.51.100.2"))
+	assert.Equal(t, http.StatusUnauthorized, login(e, "third@gmail.com", "10.0.0.2:4000", "198.51.100.3"))
+
+	assert.Equal(t, http.StatusUnauthorized, login(e, "fourth@gmail.com", "10.0.0.2:4000", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, login(e, "fifth@gmail.com", "10.0.0.2:4000", "198.51.100.1"))
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type AuditLogService struct {
+	repository repositories.AuditLogRepository
+}
+
+func InitAuditLogService(repository repositories.AuditLogRepository) AuditLogService {
+	return AuditLogService{
+		repository: repository,
+	}
+}
+
+func (as *AuditLogService) GetAll(ctx context.Context, filter models.AuditLogFilter, userID uint) ([]models.AuditLog, error) {
+	return as.repository.GetAll(ctx, filter, userID)
+}
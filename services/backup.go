@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+)
+
+type BackupService struct {
+	repository repositories.BackupRepository
+}
+
+func InitBackupService(repository repositories.BackupRepository) BackupService {
+	return BackupService{
+		repository: repository,
+	}
+}
+
+func (bs *BackupService) Create(ctx context.Context, userID uint) (models.Backup, error) {
+	return bs.repository.Create(ctx, userID)
+}
+
+func (bs *BackupService) Restore(ctx context.Context, backup models.Backup, mode string, userID uint, meta models.AuditMeta) (models.RestoreResult, error) {
+	if err := validateBackup(backup, mode); err != nil {
+		return models.RestoreResult{}, models.ValidationError("invalid_backup", err.Error())
+	}
+
+	return bs.repository.Restore(ctx, backup, mode, userID, meta)
+}
+
+// validateBackup checks the backup before anything is written, so a broken
+// archive never leaves a half restored dataset behind.
+func validateBackup(backup models.Backup, mode string) error {
+	if mode != models.RestoreModeReplace && mode != models.RestoreModeMerge {
+		return fmt.Errorf("unknown restore mode %q, expected %q or %q", mode, models.RestoreModeReplace, models.RestoreModeMerge)
+	}
+
+	if backup.Version < 1 || backup.Version > models.BackupVersion {
+		return fmt.Errorf("unsupported backup version %d, this server supports up to version %d", backup.Version, models.BackupVersion)
+	}
+
+	categories := map[uint]bool{}
+	for _, category := range backup.Categories {
+		if category.Name == "" {
+			return fmt.Errorf("category %d has no name", category.ID)
+		}
+		if categories[category.ID] {
+			return fmt.Errorf("category %d appears more than once", category.ID)
+		}
+		categories[category.ID] = true
+	}
+
+	finances := map[uint]bool{}
+	for _, finance := range backup.Finances {
+		if finances[finance.ID] {
+			return fmt.Errorf("finance %d appears more than once", finance.ID)
+		}
+		if finance.Type != 1 && finance.Type != 2 {
+			return fmt.Errorf("finance %d has an invalid type %d", finance.ID, finance.Type)
+		}
+		if !categories[finance.CategoryID] {
+			return fmt.Errorf("finance %d references unknown category %d", finance.ID, finance.CategoryID)
+		}
+		finances[finance.ID] = true
+	}
+
+	savings := map[uint]bool{}
+	for _, saving := range backup.Savings {
+		if saving.Name == "" {
+			return fmt.Errorf("saving %d has no name", saving.ID)
+		}
+		if savings[saving.ID] {
+			return fmt.Errorf("saving %d appears more than once", saving.ID)
+		}
+		savings[saving.ID] = true
+	}
+
+	detailSavings := map[uint]bool{}
+	for _, detailSaving := range backup.DetailSavings {
+		if detailSavings[detailSaving.ID] {
+			return fmt.Errorf("detail saving %d appears more than once", detailSaving.ID)
+		}
+		if detailSaving.Status != 1 && detailSaving.Status != 2 {
+			return fmt.Errorf("detail saving %d has an invalid status %d", detailSaving.ID, detailSaving.Status)
+		}
+		if !savings[detailSaving.SavingID] {
+			return fmt.Errorf("detail saving %d references unknown saving %d", detailSaving.ID, detailSaving.SavingID)
+		}
+		detailSavings[detailSaving.ID] = true
+	}
+
+	debts := map[uint]bool{}
+	for _, debt := range backup.Debts {
+		if debts[debt.ID] {
+			return fmt.Errorf("debt %d appears more than once", debt.ID)
+		}
+		if debt.Direction != models.DebtDirectionPayable && debt.Direction != models.DebtDirectionReceivable {
+			return fmt.Errorf("debt %d has an invalid direction %q", debt.ID, debt.Direction)
+		}
+		if _, err := debt.Principal.Sub(debt.Paid); err != nil {
+			return fmt.Errorf("debt %d: %w", debt.ID, err)
+		}
+		debts[debt.ID] = true
+	}
+
+	debtRepayments := map[uint]bool{}
+	for _, repayment := range backup.DebtRepayments {
+		if debtRepayments[repayment.ID] {
+			return fmt.Errorf("debt repayment %d appears more than once", repayment.ID)
+		}
+		if !debts[repayment.DebtID] {
+			return fmt.Errorf("debt repayment %d references unknown debt %d", repayment.ID, repayment.DebtID)
+		}
+		if !finances[repayment.FinanceID] {
+			return fmt.Errorf("debt repayment %d references unknown finance %d", repayment.ID, repayment.FinanceID)
+		}
+		debtRepayments[repayment.ID] = true
+	}
+
+	bills := map[uint]bool{}
+	for _, bill := range backup.Bills {
+		if bill.Name == "" {
+			return fmt.Errorf("bill %d has no name", bill.ID)
+		}
+		if bills[bill.ID] {
+			return fmt.Errorf("bill %d appears more than once", bill.ID)
+		}
+		if bill.DueDay < 1 || bill.DueDay > 31 {
+			return fmt.Errorf("bill %d has an invalid due day %d", bill.ID, bill.DueDay)
+		}
+		switch bill.Recurrence {
+		case models.BillRecurrenceOnce, models.BillRecurrenceMonthly, models.BillRecurrenceQuarterly, models.BillRecurrenceYearly:
+		default:
+			return fmt.Errorf("bill %d has an invalid recurrence %q", bill.ID, bill.Recurrence)
+		}
+		if !categories[bill.CategoryID] {
+			return fmt.Errorf("bill %d references unknown category %d", bill.ID, bill.CategoryID)
+		}
+		bills[bill.ID] = true
+	}
+
+	goldSavings := map[uint]bool{}
+	for _, goldSaving := range backup.GoldSavings {
+		if goldSaving.Name == "" {
+			return fmt.Errorf("gold saving %d has no name", goldSaving.ID)
+		}
+		if goldSavings[goldSaving.ID] {
+			return fmt.Errorf("gold saving %d appears more than once", goldSaving.ID)
+		}
+		goldSavings[goldSaving.ID] = true
+	}
+
+	goldDeposits := map[uint]bool{}
+	goldCurrencies := map[uint]string{}
+	for _, deposit := range backup.GoldDeposits {
+		if goldDeposits[deposit.ID] {
+			return fmt.Errorf("gold deposit %d appears more than once", deposit.ID)
+		}
+		if !goldSavings[deposit.GoldSavingID] {
+			return fmt.Errorf("gold deposit %d references unknown gold saving %d", deposit.ID, deposit.GoldSavingID)
+		}
+		if deposit.Grams <= 0 {
+			return fmt.Errorf("gold deposit %d has an invalid weight %s", deposit.ID, deposit.Grams)
+		}
+		// the cost of a saving is added up from its deposits
+		if currency, ok := goldCurrencies[deposit.GoldSavingID]; ok && currency != deposit.Price.Currency {
+			return fmt.Errorf("gold deposit %d: %w", deposit.ID, money.ErrCurrencyMismatch)
+		}
+		goldCurrencies[deposit.GoldSavingID] = deposit.Price.Currency
+		goldDeposits[deposit.ID] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type BillService struct {
+	repository repositories.BillRepository
+}
+
+func InitBillService(repository repositories.BillRepository) BillService {
+	return BillService{
+		repository: repository,
+	}
+}
+
+func (bs *BillService) GetAll(ctx context.Context, userID uint) ([]models.Bill, error) {
+	return bs.repository.GetAll(ctx, userID)
+}
+
+func (bs *BillService) GetByID(ctx context.Context, id string, userID uint) (models.Bill, error) {
+	return bs.repository.GetByID(ctx, id, userID)
+}
+
+func (bs *BillService) Create(ctx context.Context, billInput models.BillInput, userID uint, meta models.AuditMeta) (models.Bill, error) {
+	return bs.repository.Create(ctx, billInput, userID, meta)
+}
+
+func (bs *BillService) Update(ctx context.Context, billInput models.BillInput, id string, userID uint, meta models.AuditMeta) (models.Bill, error) {
+	return bs.repository.Update(ctx, billInput, id, userID, meta)
+}
+
+func (bs *BillService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return bs.repository.Delete(ctx, id, userID, meta)
+}
+
+func (bs *BillService) Pay(ctx context.Context, paymentInput models.BillPayment, id string, userID uint, meta models.AuditMeta) (models.Finance, error) {
+	return bs.repository.Pay(ctx, paymentInput, id, userID, meta)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/repositories"
+	"log/slog"
+	"time"
+)
+
+// BillReminderScheduler periodically turns bills that are about to become
+// due into notifications.
+type BillReminderScheduler struct {
+	repository repositories.BillRepository
+	interval   time.Duration
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func InitBillReminderScheduler(repository repositories.BillRepository, interval time.Duration) *BillReminderScheduler {
+	return &BillReminderScheduler{
+		repository: repository,
+		interval:   interval,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background, the first run is right away.
+func (bs *BillReminderScheduler) Start() {
+	go func() {
+		defer close(bs.done)
+
+		ticker := time.NewTicker(bs.interval)
+		defer ticker.Stop()
+
+		for {
+			bs.Run(context.Background(), time.Now())
+
+			select {
+			case <-ticker.C:
+			case <-bs.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Run generates the reminders that are due at now.
+func (bs *BillReminderScheduler) Run(ctx context.Context, now time.Time) {
+	reminded, err := bs.repository.GenerateReminders(ctx, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "error when generating bill reminders", "error", err)
+	}
+
+	if reminded > 0 {
+		slog.InfoContext(ctx, "sent bill reminders", "bills", reminded)
+	}
+}
+
+// Stop waits for a running batch to finish before returning.
+func (bs *BillReminderScheduler) Stop(ctx context.Context) error {
+	close(bs.stop)
+
+	select {
+	case <-bs.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/repositories"
 )
@@ -9,28 +10,28 @@ type CategoryService struct {
 	repository repositories.CategoryRepository
 }
 
-func InitCategoryService() CategoryService {
+func InitCategoryService(repository repositories.CategoryRepository) CategoryService {
 	return CategoryService{
-		repository: &repositories.CategoryRepositoryImpl{},
+		repository: repository,
 	}
 }
 
-func (cs *CategoryService) GetAll() ([]models.Category, error) {
-	return cs.repository.GetAll()
+func (cs *CategoryService) GetAll(ctx context.Context) ([]models.Category, error) {
+	return cs.repository.GetAll(ctx)
 }
 
-func (cs *CategoryService) GetByID(id string) (models.Category, error) {
-	return cs.repository.GetByID(id)
+func (cs *CategoryService) GetByID(ctx context.Context, id string) (models.Category, error) {
+	return cs.repository.GetByID(ctx, id)
 }
 
-func (cs *CategoryService) Create(categoryInput models.CategoryInput) (models.Category, error) {
-	return cs.repository.Create(categoryInput)
+func (cs *CategoryService) Create(ctx context.Context, categoryInput models.CategoryInput, meta models.AuditMeta) (models.Category, error) {
+	return cs.repository.Create(ctx, categoryInput, meta)
 }
 
-func (cs *CategoryService) Update(categoryInput models.CategoryInput, id string) (models.Category, error) {
-	return cs.repository.Update(categoryInput, id)
+func (cs *CategoryService) Update(ctx context.Context, categoryInput models.CategoryInput, id string, meta models.AuditMeta) (models.Category, error) {
+	return cs.repository.Update(ctx, categoryInput, id, meta)
 }
 
-func (cs *CategoryService) Delete(id string) error {
-	return cs.repository.Delete(id)
+func (cs *CategoryService) Delete(ctx context.Context, id string, meta models.AuditMeta) error {
+	return cs.repository.Delete(ctx, id, meta)
 }
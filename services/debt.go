@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type DebtService struct {
+	repository repositories.DebtRepository
+}
+
+func InitDebtService(repository repositories.DebtRepository) DebtService {
+	return DebtService{
+		repository: repository,
+	}
+}
+
+func (ds *DebtService) GetAll(ctx context.Context, userID uint) ([]models.Debt, error) {
+	return ds.repository.GetAll(ctx, userID)
+}
+
+func (ds *DebtService) GetOverdue(ctx context.Context, userID uint) ([]models.Debt, error) {
+	return ds.repository.GetOverdue(ctx, userID)
+}
+
+func (ds *DebtService) GetSummary(ctx context.Context, userID uint) (models.DebtSummary, error) {
+	return ds.repository.GetSummary(ctx, userID)
+}
+
+func (ds *DebtService) GetByID(ctx context.Context, id string, userID uint) (models.Debt, error) {
+	return ds.repository.GetByID(ctx, id, userID)
+}
+
+func (ds *DebtService) Create(ctx context.Context, debtInput models.DebtInput, userID uint, meta models.AuditMeta) (models.Debt, error) {
+	return ds.repository.Create(ctx, debtInput, userID, meta)
+}
+
+func (ds *DebtService) Update(ctx context.Context, debtInput models.DebtInput, id string, userID uint, meta models.AuditMeta) (models.Debt, error) {
+	return ds.repository.Update(ctx, debtInput, id, userID, meta)
+}
+
+func (ds *DebtService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return ds.repository.Delete(ctx, id, userID, meta)
+}
+
+func (ds *DebtService) Repay(ctx context.Context, repaymentInput models.DebtRepaymentInput, id string, userID uint, meta models.AuditMeta) (models.DebtRepayment, error) {
+	return ds.repository.Repay(ctx, repaymentInput, id, userID, meta)
+}
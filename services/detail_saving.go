@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/repositories"
 )
@@ -9,28 +10,28 @@ type DetailSavingService struct {
 	repository repositories.DetailSavingRepository
 }
 
-func InitDetailSavingService() DetailSavingService {
+func InitDetailSavingService(repository repositories.DetailSavingRepository) DetailSavingService {
 	return DetailSavingService{
-		repository: &repositories.DetailSavingRepositoryImpl{},
+		repository: repository,
 	}
 }
 
-func (dss *DetailSavingService) GetAll(token string) ([]models.DetailSaving, error) {
-	return dss.repository.GetAll(token)
+func (dss *DetailSavingService) GetAll(ctx context.Context, userID uint) ([]models.DetailSaving, error) {
+	return dss.repository.GetAll(ctx, userID)
 }
 
-func (dss *DetailSavingService) GetByID(id, token string) (models.DetailSaving, error) {
-	return dss.repository.GetByID(id, token)
+func (dss *DetailSavingService) GetByID(ctx context.Context, id string, userID uint) (models.DetailSaving, error) {
+	return dss.repository.GetByID(ctx, id, userID)
 }
 
-func (dss *DetailSavingService) Create(detailSavingInput models.DetailSavingInput, token string) (models.DetailSaving, error) {
-	return dss.repository.Create(detailSavingInput, token)
+func (dss *DetailSavingService) Create(ctx context.Context, detailSavingInput models.DetailSavingInput, userID uint, meta models.AuditMeta) (models.DetailSaving, error) {
+	return dss.repository.Create(ctx, detailSavingInput, userID, meta)
 }
 
-func (dss *DetailSavingService) Update(detailSavingInput models.DetailSavingInput, id, token string) (models.DetailSaving, error) {
-	return dss.repository.Update(detailSavingInput, id, token)
+func (dss *DetailSavingService) Update(ctx context.Context, detailSavingInput models.DetailSavingInput, id string, userID uint, meta models.AuditMeta) (models.DetailSaving, error) {
+	return dss.repository.Update(ctx, detailSavingInput, id, userID, meta)
 }
 
-func (dss *DetailSavingService) Delete(id, token string) error {
-	return dss.repository.Delete(id, token)
+func (dss *DetailSavingService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return dss.repository.Delete(ctx, id, userID, meta)
 }
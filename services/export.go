@@ -0,0 +1,184 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"keuangan-pribadi/models"
+	"strconv"
+	"time"
+)
+
+type exportProfile struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Exp       int       `json:"exp"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// buildExportArchive writes every dataset of the export as both a JSON and
+// a CSV file into a ZIP archive. The records are the same as in a backup.
+func buildExportArchive(export models.UserExport) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	backup := models.NewBackup(export)
+
+	user := export.User
+	profile := exportProfile{ID: user.ID, Name: user.Name, Email: user.Email, Role: user.Role, Exp: user.Exp, CreatedAt: user.CreatedAt}
+
+	profileRows := [][]string{
+		{"id", "name", "email", "role", "exp", "created_at"},
+		{formatUint(user.ID), user.Name, user.Email, user.Role, strconv.Itoa(user.Exp), formatTime(user.CreatedAt)},
+	}
+
+	categoryRows := [][]string{{"id", "name"}}
+	for _, category := range backup.Categories {
+		categoryRows = append(categoryRows, []string{formatUint(category.ID), category.Name})
+	}
+
+	financeRows := [][]string{{"id", "name", "type", "money", "currency", "category_id", "created_at", "updated_at"}}
+	for _, finance := range backup.Finances {
+		financeRows = append(financeRows, []string{
+			formatUint(finance.ID), finance.Name, strconv.Itoa(finance.Type), finance.Money.Decimal(), finance.Money.Currency,
+			formatUint(finance.CategoryID), formatTime(finance.CreatedAt), formatTime(finance.UpdatedAt),
+		})
+	}
+
+	savingRows := [][]string{{"id", "name", "value", "goal", "currency", "created_at", "updated_at"}}
+	for _, saving := range backup.Savings {
+		savingRows = append(savingRows, []string{
+			formatUint(saving.ID), saving.Name, saving.Value.Decimal(), saving.Goal.Decimal(), saving.Goal.Currency,
+			formatTime(saving.CreatedAt), formatTime(saving.UpdatedAt),
+		})
+	}
+
+	detailSavingRows := [][]string{{"id", "saving_id", "value", "currency", "status", "created_at", "updated_at"}}
+	for _, detailSaving := range backup.DetailSavings {
+		detailSavingRows = append(detailSavingRows, []string{
+			formatUint(detailSaving.ID), formatUint(detailSaving.SavingID), detailSaving.Value.Decimal(), detailSaving.Value.Currency,
+			strconv.Itoa(int(detailSaving.Status)), formatTime(detailSaving.CreatedAt), formatTime(detailSaving.UpdatedAt),
+		})
+	}
+
+	debtRows := [][]string{{"id", "counterparty", "direction", "principal", "paid", "outstanding", "currency", "due_date", "settled_at", "created_at"}}
+	for _, debt := range export.Debts {
+		dueDate, settledAt := "", ""
+		if debt.DueDate != nil {
+			dueDate = formatTime(*debt.DueDate)
+		}
+		if debt.SettledAt != nil {
+			settledAt = formatTime(*debt.SettledAt)
+		}
+
+		debtRows = append(debtRows, []string{
+			formatUint(debt.ID), debt.Counterparty, debt.Direction, debt.Principal.Decimal(), debt.Paid.Decimal(),
+			debt.Outstanding.Decimal(), debt.Principal.Currency, dueDate, settledAt, formatTime(debt.CreatedAt),
+		})
+	}
+
+	debtRepaymentRows := [][]string{{"id", "debt_id", "amount", "currency", "finance_id", "created_at"}}
+	for _, repayment := range backup.DebtRepayments {
+		debtRepaymentRows = append(debtRepaymentRows, []string{
+			formatUint(repayment.ID), formatUint(repayment.DebtID), repayment.Amount.Decimal(), repayment.Amount.Currency,
+			formatUint(repayment.FinanceID), formatTime(repayment.CreatedAt),
+		})
+	}
+
+	billRows := [][]string{{"id", "name", "amount", "currency", "due_day", "recurrence", "lead_days", "next_due_date", "active", "category_id", "created_at"}}
+	for _, bill := range backup.Bills {
+		billRows = append(billRows, []string{
+			formatUint(bill.ID), bill.Name, bill.Amount.Decimal(), bill.Amount.Currency, strconv.Itoa(bill.DueDay), bill.Recurrence,
+			strconv.Itoa(bill.LeadDays), formatTime(bill.NextDueDate), strconv.FormatBool(bill.Active), formatUint(bill.CategoryID),
+			formatTime(bill.CreatedAt),
+		})
+	}
+
+	goldSavingRows := [][]string{{"id", "name", "goal_grams", "grams", "cost", "currency", "created_at", "updated_at"}}
+	goldDeposits := []models.GoldDeposit{}
+	goldDepositRows := [][]string{{"id", "gold_saving_id", "grams", "price", "currency", "purchased_at", "created_at"}}
+	for _, goldSaving := range export.GoldSavings {
+		goldSavingRows = append(goldSavingRows, []string{
+			formatUint(goldSaving.ID), goldSaving.Name, goldSaving.Goal.String(), goldSaving.Grams.String(), goldSaving.Cost.Decimal(),
+			goldSaving.Cost.Currency, formatTime(goldSaving.CreatedAt), formatTime(goldSaving.UpdatedAt),
+		})
+
+		for _, deposit := range goldSaving.Deposits {
+			goldDeposits = append(goldDeposits, deposit)
+			goldDepositRows = append(goldDepositRows, []string{
+				formatUint(deposit.ID), formatUint(deposit.GoldSavingID), deposit.Grams.String(), deposit.Price.Decimal(),
+				deposit.Price.Currency, formatTime(deposit.PurchasedAt), formatTime(deposit.CreatedAt),
+			})
+		}
+	}
+
+	datasets := []struct {
+		name string
+		data interface{}
+		rows [][]string
+	}{
+		{"settings", profile, profileRows},
+		{"categories", backup.Categories, categoryRows},
+		{"finances", backup.Finances, financeRows},
+		{"savings", backup.Savings, savingRows},
+		{"detail_savings", backup.DetailSavings, detailSavingRows},
+		{"debts", export.Debts, debtRows},
+		{"debt_repayments", backup.DebtRepayments, debtRepaymentRows},
+		{"bills", backup.Bills, billRows},
+		{"gold_savings", export.GoldSavings, goldSavingRows},
+		{"gold_deposits", goldDeposits, goldDepositRows},
+	}
+
+	for _, dataset := range datasets {
+		if err := writeJSONFile(archive, dataset.name+".json", dataset.data); err != nil {
+			return nil, err
+		}
+
+		if err := writeCSVFile(archive, dataset.name+".csv", dataset.rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeJSONFile(archive *zip.Writer, name string, data interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(data)
+}
+
+func writeCSVFile(archive *zip.Writer, name string, rows [][]string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func formatUint(value uint) string {
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func formatTime(value time.Time) string {
+	return value.Format(time.RFC3339)
+}
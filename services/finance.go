@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/repositories"
 	"time"
@@ -10,32 +11,32 @@ type FinanceService struct {
 	repository repositories.FinanceRepository
 }
 
-func InitFinanceService() FinanceService {
+func InitFinanceService(repository repositories.FinanceRepository) FinanceService {
 	return FinanceService{
-		repository: &repositories.FinanceRepositoryImpl{},
+		repository: repository,
 	}
 }
 
-func (fs *FinanceService) GetAll(token string) ([]models.Finance, error) {
-	return fs.repository.GetAll(token)
+func (fs *FinanceService) GetAll(ctx context.Context, userID uint) ([]models.Finance, error) {
+	return fs.repository.GetAll(ctx, userID)
 }
 
-func (fs *FinanceService) GetByID(id, token string) (models.Finance, error) {
-	return fs.repository.GetByID(id, token)
+func (fs *FinanceService) GetByID(ctx context.Context, id string, userID uint) (models.Finance, error) {
+	return fs.repository.GetByID(ctx, id, userID)
 }
 
-func (fs *FinanceService) Search(from, to time.Time, token string) ([]models.Finance, error) {
-	return fs.repository.Search(from, to, token)
+func (fs *FinanceService) Search(ctx context.Context, from, to time.Time, userID uint) ([]models.Finance, error) {
+	return fs.repository.Search(ctx, from, to, userID)
 }
 
-func (fs *FinanceService) Create(financeInput models.FinanceInput, token string) (models.Finance, error) {
-	return fs.repository.Create(financeInput, token)
+func (fs *FinanceService) Create(ctx context.Context, financeInput models.FinanceInput, userID uint, meta models.AuditMeta) (models.Finance, error) {
+	return fs.repository.Create(ctx, financeInput, userID, meta)
 }
 
-func (fs *FinanceService) Update(financeInput models.FinanceInput, id, token string) (models.Finance, error) {
-	return fs.repository.Update(financeInput, id, token)
+func (fs *FinanceService) Update(ctx context.Context, financeInput models.FinanceInput, id string, userID uint, meta models.AuditMeta) (models.Finance, error) {
+	return fs.repository.Update(ctx, financeInput, id, userID, meta)
 }
 
-func (fs *FinanceService) Delete(id, token string) error {
-	return fs.repository.Delete(id, token)
+func (fs *FinanceService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return fs.repository.Delete(ctx, id, userID, meta)
 }
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/marketdata"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"math"
+)
+
+// goldSymbol is gold as a currency, the price of a troy ounce.
+const goldSymbol = "XAU"
+
+type GoldSavingService struct {
+	repository repositories.GoldSavingRepository
+	provider   marketdata.MarketDataProvider
+}
+
+func InitGoldSavingService(repository repositories.GoldSavingRepository, provider marketdata.MarketDataProvider) GoldSavingService {
+	return GoldSavingService{
+		repository: repository,
+		provider:   provider,
+	}
+}
+
+func (gs *GoldSavingService) GetAll(ctx context.Context, userID uint) ([]models.GoldSaving, error) {
+	return gs.repository.GetAll(ctx, userID)
+}
+
+func (gs *GoldSavingService) GetByID(ctx context.Context, id string, userID uint) (models.GoldSaving, error) {
+	return gs.repository.GetByID(ctx, id, userID)
+}
+
+func (gs *GoldSavingService) Create(ctx context.Context, goldSavingInput models.GoldSavingInput, userID uint, meta models.AuditMeta) (models.GoldSaving, error) {
+	return gs.repository.Create(ctx, goldSavingInput, userID, meta)
+}
+
+func (gs *GoldSavingService) Update(ctx context.Context, goldSavingUpdate models.GoldSavingUpdate, id string, userID uint, meta models.AuditMeta) (models.GoldSaving, error) {
+	return gs.repository.Update(ctx, goldSavingUpdate, id, userID, meta)
+}
+
+func (gs *GoldSavingService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return gs.repository.Delete(ctx, id, userID, meta)
+}
+
+func (gs *GoldSavingService) Deposit(ctx context.Context, depositInput models.GoldDepositInput, id string, userID uint, meta models.AuditMeta) (models.GoldDeposit, error) {
+	return gs.repository.Deposit(ctx, depositInput, id, userID, meta)
+}
+
+func (gs *GoldSavingService) DeleteDeposit(ctx context.Context, id, depositID string, userID uint, meta models.AuditMeta) error {
+	return gs.repository.DeleteDeposit(ctx, id, depositID, userID, meta)
+}
+
+// Valuation is what the gold of a saving is worth at the current gold price,
+// in the currency it was bought in. A saving without deposits is valued in
+// the default currency.
+func (gs *GoldSavingService) Valuation(ctx context.Context, id string, userID uint, locale string) (models.GoldValuation, error) {
+	goldSaving, err := gs.repository.GetByID(ctx, id, userID)
+	if err != nil {
+		return models.GoldValuation{}, err
+	}
+
+	cost := goldSaving.Cost
+	if cost.Currency == "" {
+		cost = money.Zero(money.DefaultCurrency())
+	}
+
+	rate, err := gs.provider.ExchangeRate(ctx, goldSymbol, cost.Currency)
+	if err != nil {
+		return models.GoldValuation{}, marketDataError(err)
+	}
+
+	perGram := rate.Rate / models.GramsPerTroyOunce
+
+	pricePerGram, err := money.FromFloat(perGram, cost.Currency)
+	if err != nil {
+		return models.GoldValuation{}, err
+	}
+
+	value, err := money.FromFloat(perGram*goldSaving.Grams.Float64(), cost.Currency)
+	if err != nil {
+		return models.GoldValuation{}, err
+	}
+
+	gain, err := value.Sub(cost)
+	if err != nil {
+		return models.GoldValuation{}, err
+	}
+
+	valuation := models.GoldValuation{
+		GoldSavingID:   goldSaving.ID,
+		Grams:          goldSaving.Grams,
+		Goal:           goldSaving.Goal,
+		PricePerGram:   pricePerGram,
+		Value:          value,
+		Cost:           cost,
+		UnrealizedGain: gain,
+		Formatted: models.GoldValuationFormatted{
+			PricePerGram:   pricePerGram.Format(locale),
+			Value:          value.Format(locale),
+			Cost:           cost.Format(locale),
+			UnrealizedGain: gain.Format(locale),
+		},
+		PricedAt: rate.FetchedAt,
+		Stale:    rate.Stale,
+	}
+
+	if goldSaving.Goal > 0 {
+		valuation.GoalPercent = math.Round(float64(goldSaving.Grams)/float64(goldSaving.Goal)*10000) / 100
+	}
+
+	if !cost.IsZero() {
+		valuation.UnrealizedGainPercent = math.Round(gain.Float64()/cost.Float64()*10000) / 100
+	}
+
+	return valuation, nil
+}
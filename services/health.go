@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"keuangan-pribadi/migrations"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type HealthService struct {
+	repository repositories.HealthRepository
+}
+
+func InitHealthService(repository repositories.HealthRepository) HealthService {
+	return HealthService{
+		repository: repository,
+	}
+}
+
+// Ready checks that the database answers and has the schema of this binary.
+func (hs *HealthService) Ready(ctx context.Context) (models.Health, error) {
+	if err := hs.repository.Ping(ctx); err != nil {
+		return models.Health{}, models.UnavailableError("database_unavailable", "the database is not reachable").Wrap(err)
+	}
+
+	pending, err := hs.repository.PendingMigrations(ctx)
+	if errors.Is(err, migrations.ErrSchemaTooNew) {
+		return models.Health{}, models.UnavailableError("schema_too_new", "the database schema is newer than this version").Wrap(err)
+	}
+	if err != nil {
+		return models.Health{}, err
+	}
+
+	if pending > 0 {
+		return models.Health{}, models.UnavailableError("migrations_pending", fmt.Sprintf("%d migrations are not applied yet", pending))
+	}
+
+	return models.Health{Database: "up", SchemaVersion: migrations.Latest()}, nil
+}
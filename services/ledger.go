@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type LedgerService struct {
+	repository repositories.LedgerRepository
+}
+
+func InitLedgerService(repository repositories.LedgerRepository) LedgerService {
+	return LedgerService{
+		repository: repository,
+	}
+}
+
+func (ls *LedgerService) GetAll(ctx context.Context, userID uint) ([]models.Ledger, error) {
+	return ls.repository.GetAll(ctx, userID)
+}
+
+func (ls *LedgerService) GetByID(ctx context.Context, id string, userID uint) (models.Ledger, error) {
+	return ls.repository.GetByID(ctx, id, userID)
+}
+
+func (ls *LedgerService) Create(ctx context.Context, ledgerInput models.LedgerInput, userID uint, meta models.AuditMeta) (models.Ledger, error) {
+	return ls.repository.Create(ctx, ledgerInput, userID, meta)
+}
+
+func (ls *LedgerService) Update(ctx context.Context, ledgerInput models.LedgerInput, id string, userID uint, meta models.AuditMeta) (models.Ledger, error) {
+	return ls.repository.Update(ctx, ledgerInput, id, userID, meta)
+}
+
+func (ls *LedgerService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return ls.repository.Delete(ctx, id, userID, meta)
+}
+
+func (ls *LedgerService) UpdateMember(ctx context.Context, memberInput models.LedgerMemberInput, id, memberID string, userID uint, meta models.AuditMeta) (models.LedgerMember, error) {
+	return ls.repository.UpdateMember(ctx, memberInput, id, memberID, userID, meta)
+}
+
+func (ls *LedgerService) RemoveMember(ctx context.Context, id, memberID string, userID uint, meta models.AuditMeta) error {
+	return ls.repository.RemoveMember(ctx, id, memberID, userID, meta)
+}
+
+func (ls *LedgerService) Invite(ctx context.Context, invitationInput models.LedgerInvitationInput, id string, userID uint, meta models.AuditMeta) (models.LedgerInvitation, error) {
+	return ls.repository.Invite(ctx, invitationInput, id, userID, meta)
+}
+
+func (ls *LedgerService) AcceptInvitation(ctx context.Context, acceptInput models.LedgerInvitationAccept, userID uint, meta models.AuditMeta) (models.LedgerMember, error) {
+	return ls.repository.AcceptInvitation(ctx, acceptInput, userID, meta)
+}
+
+func (ls *LedgerService) GetCategories(ctx context.Context, id string, userID uint) ([]models.Category, error) {
+	return ls.repository.GetCategories(ctx, id, userID)
+}
+
+func (ls *LedgerService) CreateCategory(ctx context.Context, categoryInput models.CategoryInput, id string, userID uint, meta models.AuditMeta) (models.Category, error) {
+	return ls.repository.CreateCategory(ctx, categoryInput, id, userID, meta)
+}
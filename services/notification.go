@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type NotificationService struct {
+	repository repositories.NotificationRepository
+}
+
+func InitNotificationService(repository repositories.NotificationRepository) NotificationService {
+	return NotificationService{
+		repository: repository,
+	}
+}
+
+func (ns *NotificationService) GetAll(ctx context.Context, filter models.NotificationFilter, userID uint) ([]models.Notification, error) {
+	return ns.repository.GetAll(ctx, filter, userID)
+}
+
+func (ns *NotificationService) MarkRead(ctx context.Context, id string, userID uint) (models.Notification, error) {
+	return ns.repository.MarkRead(ctx, id, userID)
+}
+
+func (ns *NotificationService) MarkAllRead(ctx context.Context, userID uint) error {
+	return ns.repository.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type PersonalAccessTokenService struct {
+	repository repositories.PersonalAccessTokenRepository
+}
+
+func InitPersonalAccessTokenService(repository repositories.PersonalAccessTokenRepository) PersonalAccessTokenService {
+	return PersonalAccessTokenService{
+		repository: repository,
+	}
+}
+
+func (ps *PersonalAccessTokenService) GetAll(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error) {
+	return ps.repository.GetAll(ctx, userID)
+}
+
+func (ps *PersonalAccessTokenService) Create(ctx context.Context, tokenInput models.PersonalAccessTokenInput, userID uint, meta models.AuditMeta) (models.PersonalAccessTokenResponse, error) {
+	return ps.repository.Create(ctx, tokenInput, userID, meta)
+}
+
+func (ps *PersonalAccessTokenService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return ps.repository.Delete(ctx, id, userID, meta)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"keuangan-pribadi/marketdata"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	errMarketDataUnavailable = models.UnavailableError("market_data_unavailable", "the prices cannot be fetched right now, try again later")
+	errCommodityNotFound     = models.NotFoundError("commodity_not_found", "commodity not found")
+)
+
+type PriceService struct {
+	provider marketdata.MarketDataProvider
+}
+
+func InitPriceService(provider marketdata.MarketDataProvider) PriceService {
+	return PriceService{
+		provider: provider,
+	}
+}
+
+// Commodity is the prices of a commodity like coffee in the window of the
+// filter, converted to the currency of the filter. Without one the prices
+// are monthly and in the default currency.
+func (ps *PriceService) Commodity(ctx context.Context, symbol string, filter models.CommodityPriceFilter) (models.CommodityPrices, error) {
+	symbol = strings.ToUpper(symbol)
+
+	intervals, ok := marketdata.Commodities[symbol]
+	if !ok {
+		return models.CommodityPrices{}, errCommodityNotFound
+	}
+
+	interval := filter.Interval
+	if interval == "" {
+		interval = marketdata.IntervalMonthly
+	}
+
+	if !slices.Contains(intervals, interval) {
+		return models.CommodityPrices{}, models.ValidationError("invalid_interval", fmt.Sprintf("the prices of %s are %s", strings.ToLower(symbol), strings.Join(intervals, ", ")))
+	}
+
+	currency := strings.ToUpper(filter.Currency)
+	if currency == "" {
+		currency = money.DefaultCurrency()
+	}
+
+	if _, err := money.LookupCurrency(currency); err != nil {
+		return models.CommodityPrices{}, err
+	}
+
+	from, to, err := priceWindow(filter)
+	if err != nil {
+		return models.CommodityPrices{}, err
+	}
+
+	series, err := ps.provider.Commodity(ctx, symbol, interval)
+	if err != nil {
+		return models.CommodityPrices{}, marketDataError(err)
+	}
+
+	rate, err := ps.provider.ExchangeRate(ctx, series.Currency, currency)
+	if err != nil {
+		return models.CommodityPrices{}, marketDataError(err)
+	}
+
+	prices := models.CommodityPrices{
+		Symbol:       symbol,
+		Name:         series.Name,
+		Interval:     interval,
+		Unit:         series.Unit,
+		Currency:     currency,
+		ExchangeRate: rate.Rate,
+		FetchedAt:    series.FetchedAt,
+		Stale:        series.Stale || rate.Stale,
+		Data:         []models.CommodityPrice{},
+	}
+
+	var sum float64
+
+	for _, point := range series.Points {
+		if !from.IsZero() && point.Date.Before(from) || !to.IsZero() && point.Date.After(to) {
+			continue
+		}
+
+		price, err := commodityPrice(point.Value*rate.Rate, currency, filter.Locale)
+		if err != nil {
+			return models.CommodityPrices{}, err
+		}
+
+		price.Date = point.Date.Format(time.DateOnly)
+		prices.Data = append(prices.Data, price)
+		sum += point.Value * rate.Rate
+	}
+
+	if len(prices.Data) == 0 {
+		return prices, nil
+	}
+
+	average, err := commodityPrice(sum/float64(len(prices.Data)), currency, filter.Locale)
+	if err != nil {
+		return models.CommodityPrices{}, err
+	}
+
+	stats := models.CommodityStats{Min: prices.Data[0], Max: prices.Data[0], Average: average}
+
+	for _, price := range prices.Data {
+		if price.Value < stats.Min.Value {
+			stats.Min = price
+		}
+
+		if price.Value > stats.Max.Value {
+			stats.Max = price
+		}
+	}
+
+	first, last := prices.Data[0].Value, prices.Data[len(prices.Data)-1].Value
+	if first != 0 {
+		stats.PercentChange = math.Round((last-first)/first*10000) / 100
+	}
+
+	prices.Stats = &stats
+
+	return prices, nil
+}
+
+// Coffee is the monthly price of coffee converted to the currency and
+// formatted for the locale.
+func (ps *PriceService) Coffee(ctx context.Context, currency, locale string) (models.CoffeePriceResponse, error) {
+	prices, err := ps.Commodity(ctx, "COFFEE", models.CommodityPriceFilter{Currency: currency, Locale: locale})
+	if err != nil {
+		return models.CoffeePriceResponse{}, err
+	}
+
+	response := models.CoffeePriceResponse{
+		Name:     prices.Name,
+		Interval: prices.Interval,
+		Unit:     prices.Unit,
+		Currency: prices.Currency,
+		Stale:    prices.Stale,
+		Data:     []models.CoffeePrice{},
+	}
+
+	for _, price := range prices.Data {
+		response.Data = append(response.Data, models.CoffeePrice{Date: price.Date, Value: price.Formatted})
+	}
+
+	return response, nil
+}
+
+// commodityPrice rounds a converted price to the minor unit of the currency.
+func commodityPrice(value float64, currency, locale string) (models.CommodityPrice, error) {
+	amount, err := money.FromFloat(value, currency)
+	if err != nil {
+		return models.CommodityPrice{}, err
+	}
+
+	return models.CommodityPrice{Value: amount.Float64(), Formatted: amount.Format(locale)}, nil
+}
+
+// priceWindow reads the dates of the filter, a missing date is zero.
+func priceWindow(filter models.CommodityPriceFilter) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+
+	if filter.From != "" {
+		if from, err = time.Parse(time.DateOnly, filter.From); err != nil {
+			return from, to, models.ValidationError("invalid_date", "invalid from date")
+		}
+	}
+
+	if filter.To != "" {
+		if to, err = time.Parse(time.DateOnly, filter.To); err != nil {
+			return from, to, models.ValidationError("invalid_date", "invalid to date")
+		}
+	}
+
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return from, to, models.ValidationError("invalid_date", "from is after to")
+	}
+
+	return from, to, nil
+}
+
+// marketDataError hides why the provider failed, the cause is only logged.
+func marketDataError(err error) error {
+	if errors.Is(err, marketdata.ErrUnavailable) {
+		return errMarketDataUnavailable.Wrap(err)
+	}
+
+	return err
+}
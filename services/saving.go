@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/repositories"
 )
@@ -9,28 +10,28 @@ type SavingService struct {
 	repository repositories.SavingRepository
 }
 
-func InitSavingService() SavingService {
+func InitSavingService(repository repositories.SavingRepository) SavingService {
 	return SavingService{
-		repository: &repositories.SavingRepositoryImpl{},
+		repository: repository,
 	}
 }
 
-func (ss *SavingService) GetAll(token string) ([]models.Saving, error) {
-	return ss.repository.GetAll(token)
+func (ss *SavingService) GetAll(ctx context.Context, userID uint) ([]models.Saving, error) {
+	return ss.repository.GetAll(ctx, userID)
 }
 
-func (ss *SavingService) GetByID(id, token string) (models.Saving, error) {
-	return ss.repository.GetByID(id, token)
+func (ss *SavingService) GetByID(ctx context.Context, id string, userID uint) (models.Saving, error) {
+	return ss.repository.GetByID(ctx, id, userID)
 }
 
-func (ss *SavingService) Create(savingInput models.SavingInput, token string) (models.Saving, error) {
-	return ss.repository.Create(savingInput, token)
+func (ss *SavingService) Create(ctx context.Context, savingInput models.SavingInput, userID uint, meta models.AuditMeta) (models.Saving, error) {
+	return ss.repository.Create(ctx, savingInput, userID, meta)
 }
 
-func (ss *SavingService) Update(savingUpdate models.SavingUpdate, id, token string) (models.Saving, error) {
-	return ss.repository.Update(savingUpdate, id, token)
+func (ss *SavingService) Update(ctx context.Context, savingUpdate models.SavingUpdate, id string, userID uint, meta models.AuditMeta) (models.Saving, error) {
+	return ss.repository.Update(ctx, savingUpdate, id, userID, meta)
 }
 
-func (ss *SavingService) Delete(id, token string) error {
-	return ss.repository.Delete(id, token)
+func (ss *SavingService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return ss.repository.Delete(ctx, id, userID, meta)
 }
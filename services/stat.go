@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+)
+
+type StatService struct {
+	repository repositories.StatRepository
+}
+
+func InitStatService(repository repositories.StatRepository) StatService {
+	return StatService{
+		repository: repository,
+	}
+}
+
+func (ss *StatService) GetSummary(ctx context.Context) (models.Stat, error) {
+	return ss.repository.GetSummary(ctx)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"keuangan-pribadi/models"
 	"keuangan-pribadi/repositories"
 )
@@ -9,24 +10,62 @@ type UserService struct {
 	repository repositories.UserRepository
 }
 
-func InitUserService() UserService {
+func InitUserService(repository repositories.UserRepository) UserService {
 	return UserService{
-		repository: &repositories.UserRepositoryImpl{},
+		repository: repository,
 	}
 }
 
-func (us *UserService) GetByEmail(email string) (models.User, error) {
-	return us.repository.GetByEmail(email)
+func (us *UserService) GetAll(ctx context.Context) ([]models.User, error) {
+	return us.repository.GetAll(ctx)
 }
 
-func (us *UserService) Register(userInput models.UserInput) (models.User, error) {
-	return us.repository.Register(userInput)
+func (us *UserService) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	return us.repository.GetByEmail(ctx, email)
 }
 
-func (us *UserService) Login(userInput models.UserAuth) (models.UserResponse, error) {
-	return us.repository.Login(userInput)
+func (us *UserService) Register(ctx context.Context, userInput models.UserInput, meta models.AuditMeta) (models.User, error) {
+	return us.repository.Register(ctx, userInput, meta)
 }
 
-func (us *UserService) Update(userInput models.UserInput, token string) (models.User, error) {
-	return us.repository.Update(userInput, token)
-}
\ No newline at end of file
+func (us *UserService) Login(ctx context.Context, userInput models.UserAuth) (models.UserResponse, error) {
+	return us.repository.Login(ctx, userInput)
+}
+
+func (us *UserService) Update(ctx context.Context, userUpdate models.UserUpdate, userID uint, meta models.AuditMeta) (models.User, error) {
+	return us.repository.Update(ctx, userUpdate, userID, meta)
+}
+
+func (us *UserService) ChangePassword(ctx context.Context, passwordInput models.UserChangePassword, userID uint, meta models.AuditMeta) (models.UserResponse, error) {
+	return us.repository.ChangePassword(ctx, passwordInput, userID, meta)
+}
+
+func (us *UserService) ChangeEmail(ctx context.Context, emailInput models.UserChangeEmail, userID uint) error {
+	return us.repository.ChangeEmail(ctx, emailInput, userID)
+}
+
+func (us *UserService) VerifyEmail(ctx context.Context, verifyInput models.UserVerifyEmail, meta models.AuditMeta) (models.User, error) {
+	return us.repository.VerifyEmail(ctx, verifyInput, meta)
+}
+
+func (us *UserService) UpdateRole(ctx context.Context, roleInput models.UserRole, id string, meta models.AuditMeta) (models.User, error) {
+	return us.repository.UpdateRole(ctx, roleInput, id, meta)
+}
+
+func (us *UserService) Delete(ctx context.Context, id string, adminID uint, meta models.AuditMeta) error {
+	return us.repository.Delete(ctx, id, adminID, meta)
+}
+
+// Export returns a ZIP archive with all of the user's data as JSON and CSV.
+func (us *UserService) Export(ctx context.Context, userID uint) ([]byte, error) {
+	export, err := us.repository.Export(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildExportArchive(export)
+}
+
+func (us *UserService) DeleteAccount(ctx context.Context, deleteInput models.UserDeleteAccount, userID uint) error {
+	return us.repository.DeleteAccount(ctx, deleteInput, userID)
+}
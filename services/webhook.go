@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type WebhookService struct {
+	repository          repositories.WebhookRepository
+	client              *http.Client
+	allowPrivateTargets bool
+}
+
+// InitWebhookService creates the service, webhooks may only point at public
+// addresses unless allowPrivateTargets is set.
+func InitWebhookService(repository repositories.WebhookRepository, allowPrivateTargets bool) WebhookService {
+	return WebhookService{
+		repository:          repository,
+		client:              webhookClient(allowPrivateTargets),
+		allowPrivateTargets: allowPrivateTargets,
+	}
+}
+
+// webhookTimeout is the deadline of posting one delivery.
+const webhookTimeout = 10 * time.Second
+
+// webhookClient is the client delivering webhooks, it refuses to connect to
+// addresses that are not public unless private targets are allowed.
+func webhookClient(allowPrivateTargets bool) *http.Client {
+	if allowPrivateTargets {
+		return &http.Client{Timeout: webhookTimeout}
+	}
+
+	return utils.PublicHTTPClient(webhookTimeout)
+}
+
+func (ws *WebhookService) GetAll(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	return ws.repository.GetAll(ctx, userID)
+}
+
+func (ws *WebhookService) Create(ctx context.Context, webhookInput models.WebhookInput, userID uint, meta models.AuditMeta) (models.WebhookResponse, error) {
+	if err := ws.checkTarget(ctx, webhookInput.URL); err != nil {
+		return models.WebhookResponse{}, err
+	}
+
+	return ws.repository.Create(ctx, webhookInput, userID, meta)
+}
+
+func (ws *WebhookService) Update(ctx context.Context, webhookInput models.WebhookInput, id string, userID uint, meta models.AuditMeta) (models.Webhook, error) {
+	if err := ws.checkTarget(ctx, webhookInput.URL); err != nil {
+		return models.Webhook{}, err
+	}
+
+	return ws.repository.Update(ctx, webhookInput, id, userID, meta)
+}
+
+// checkTarget refuses webhooks pointing into the network of the server, the
+// deliveries are checked again when they connect.
+func (ws *WebhookService) checkTarget(ctx context.Context, url string) error {
+	if ws.allowPrivateTargets {
+		return nil
+	}
+
+	if err := utils.CheckPublicURL(ctx, url); err != nil {
+		return models.ValidationError("webhook_url_not_public", "webhook URL must point at a public address").Wrap(err)
+	}
+
+	return nil
+}
+
+func (ws *WebhookService) Delete(ctx context.Context, id string, userID uint, meta models.AuditMeta) error {
+	return ws.repository.Delete(ctx, id, userID, meta)
+}
+
+func (ws *WebhookService) GetDeliveries(ctx context.Context, id string, userID uint) ([]models.WebhookDelivery, error) {
+	return ws.repository.GetDeliveries(ctx, id, userID)
+}
+
+// Test sends a webhook.test event right away instead of waiting for the
+// dispatcher, so the caller sees the result of the delivery.
+func (ws *WebhookService) Test(ctx context.Context, id string, userID uint) (models.WebhookDelivery, error) {
+	delivery, err := ws.repository.CreateTestDelivery(ctx, id, userID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return deliverWebhook(ctx, ws.client, ws.repository, delivery, time.Now())
+}
+
+// deliverWebhook makes one attempt to deliver a queued event and stores the
+// outcome. Failed attempts are retried with exponential backoff until
+// models.WebhookMaxAttempts is reached.
+func deliverWebhook(ctx context.Context, client *http.Client, repository repositories.WebhookRepository, delivery models.WebhookDelivery, now time.Time) (models.WebhookDelivery, error) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = 0
+	delivery.Error = ""
+
+	status, err := postWebhook(ctx, client, delivery, now)
+	delivery.ResponseStatus = status
+
+	if err == nil && status >= 200 && status < 300 {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.NextAttemptAt = nil
+	} else {
+		if err != nil {
+			delivery.Error = err.Error()
+		} else {
+			delivery.Error = fmt.Sprintf("unexpected status %d", status)
+		}
+
+		if delivery.Attempts >= models.WebhookMaxAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := now.Add(webhookBackoff(delivery.Attempts)).UTC()
+			delivery.Status = models.WebhookDeliveryPending
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := repository.SaveAttempt(ctx, delivery); err != nil {
+		return delivery, err
+	}
+
+	return delivery, nil
+}
+
+func postWebhook(ctx context.Context, client *http.Client, delivery models.WebhookDelivery, now time.Time) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := now.Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "keuangan-pribadi-webhook")
+	request.Header.Set("X-Webhook-Event", delivery.Event)
+	request.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", "sha256="+utils.SignPayload(delivery.Webhook.Secret, timestamp, delivery.Payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	// only the status is kept, the body is read a little for the connection
+	// to be reused and otherwise ignored
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1024))
+
+	return response.StatusCode, nil
+}
+
+// webhookBackoff is the wait before the next attempt: 30 seconds doubled for
+// every failed attempt, at most 6 hours.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := 30 * time.Second
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= 6*time.Hour {
+			return 6 * time.Hour
+		}
+	}
+
+	return backoff
+}
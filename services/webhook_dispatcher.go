@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/repositories"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// webhookBatchSize is the number of deliveries a run posts at most.
+const webhookBatchSize = 50
+
+// WebhookDispatcher periodically delivers the queued webhook events.
+type WebhookDispatcher struct {
+	repository repositories.WebhookRepository
+	client     *http.Client
+	interval   time.Duration
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func InitWebhookDispatcher(repository repositories.WebhookRepository, interval time.Duration, allowPrivateTargets bool) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repository: repository,
+		client:     webhookClient(allowPrivateTargets),
+		interval:   interval,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start runs the dispatcher in the background, the first run is right away.
+func (wd *WebhookDispatcher) Start() {
+	go func() {
+		defer close(wd.done)
+
+		ticker := time.NewTicker(wd.interval)
+		defer ticker.Stop()
+
+		for {
+			wd.Run(context.Background(), time.Now())
+
+			select {
+			case <-ticker.C:
+			case <-wd.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Run delivers a batch of the deliveries that are due at now. The batch is
+// claimed for as long as posting every delivery of it may take, so several
+// instances can run the dispatcher.
+func (wd *WebhookDispatcher) Run(ctx context.Context, now time.Time) {
+	deliveries, err := wd.repository.ClaimDueDeliveries(ctx, now, webhookBatchSize, webhookBatchSize*webhookTimeout+time.Minute)
+	if err != nil {
+		slog.ErrorContext(ctx, "error when loading webhook deliveries", "error", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		// events queued before a webhook was disabled or removed are dropped
+		if delivery.Webhook.ID == 0 || !delivery.Webhook.Active {
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.NextAttemptAt = nil
+			delivery.Error = "webhook is disabled"
+
+			if err := wd.repository.SaveAttempt(ctx, delivery); err != nil {
+				slog.ErrorContext(ctx, "error when saving webhook delivery", "delivery_id", delivery.ID, "error", err)
+			}
+			continue
+		}
+
+		if _, err := deliverWebhook(ctx, wd.client, wd.repository, delivery, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "error when saving webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+	}
+}
+
+// Stop waits for a running batch to finish before returning.
+func (wd *WebhookDispatcher) Stop(ctx context.Context) error {
+	close(wd.stop)
+
+	select {
+	case <-wd.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,350 @@
+// Package testutil seeds the database of the tests. It is only imported by
+// tests.
+package testutil
+
+import (
+	"fmt"
+	"keuangan-pribadi/models"
+	"keuangan-pribadi/money"
+	"keuangan-pribadi/repositories"
+	"keuangan-pribadi/utils"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func SeedUser(db *gorm.DB) (models.User, error) {
+	password, err := bcrypt.GenerateFromPassword([]byte("testsecret"), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var user models.User = models.User{
+		Name:     "test",
+		Email:    fmt.Sprintf("test%d@gmail.com", time.Now().UnixNano()),
+		Password: string(password),
+	}
+
+	result := db.Create(&user)
+
+	if err := result.Error; err != nil {
+		return models.User{}, err
+	}
+
+	if err := result.Last(&user).Error; err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func SeedCategory(db *gorm.DB) (models.Category, error) {
+	var category models.Category = models.Category{
+		Name: "seederform",
+	}
+
+	result := db.Create(&category)
+
+	if err := result.Error; err != nil {
+		return models.Category{}, err
+	}
+
+	if err := result.Last(&category).Error; err != nil {
+		return models.Category{}, err
+	}
+
+	return category, nil
+}
+
+func SeedFinance(db *gorm.DB) (models.Finance, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.Finance{}, err
+	}
+
+	category, err := SeedCategory(db)
+	if err != nil {
+		return models.Finance{}, err
+	}
+
+	ledger, err := repositories.PersonalLedger(db, user.ID)
+	if err != nil {
+		return models.Finance{}, err
+	}
+
+	var finance models.Finance = models.Finance{
+		Name:       "test",
+		Type:       1,
+		Money:      money.New(1000000, "IDR"),
+		UserID:     user.ID,
+		CategoryID: category.ID,
+		LedgerID:   &ledger.ID,
+		User:       user,
+		Category:   category,
+	}
+
+	result := db.Create(&finance)
+
+	if err := result.Error; err != nil {
+		return models.Finance{}, err
+	}
+
+	if err := result.Last(&finance).Error; err != nil {
+		return models.Finance{}, err
+	}
+
+	return finance, nil
+}
+
+func SeedSaving(db *gorm.DB) (models.Saving, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.Saving{}, err
+	}
+
+	ledger, err := repositories.PersonalLedger(db, user.ID)
+	if err != nil {
+		return models.Saving{}, err
+	}
+
+	var saving models.Saving = models.Saving{
+		Name:     "test",
+		Value:    money.New(100, "IDR"),
+		Goal:     money.New(1000000, "IDR"),
+		UserID:   user.ID,
+		LedgerID: &ledger.ID,
+		User:     user,
+	}
+
+	result := db.Create(&saving)
+
+	if err := result.Error; err != nil {
+		return models.Saving{}, err
+	}
+
+	if err := result.Last(&saving).Error; err != nil {
+		return models.Saving{}, err
+	}
+
+	return saving, nil
+}
+
+func SeedDetailSaving(db *gorm.DB) (models.DetailSaving, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.DetailSaving{}, err
+	}
+
+	saving, err := SeedSaving(db)
+	if err != nil {
+		return models.DetailSaving{}, err
+	}
+
+	var detailSaving models.DetailSaving = models.DetailSaving{
+		Value:    money.New(100, "IDR"),
+		Status:   1,
+		SavingID: saving.ID,
+		UserID:   user.ID,
+		User:     user,
+		Saving:   saving,
+	}
+
+	result := db.Create(&detailSaving)
+
+	if err := result.Error; err != nil {
+		return models.DetailSaving{}, err
+	}
+
+	if err := result.Last(&detailSaving).Error; err != nil {
+		return models.DetailSaving{}, err
+	}
+
+	return detailSaving, nil
+}
+
+func SeedPersonalAccessToken(db *gorm.DB) (models.PersonalAccessToken, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.PersonalAccessToken{}, err
+	}
+
+	token, err := utils.RandomToken(20)
+	if err != nil {
+		return models.PersonalAccessToken{}, err
+	}
+
+	var pat models.PersonalAccessToken = models.PersonalAccessToken{
+		Name:      "test",
+		TokenHash: utils.HashToken(models.PersonalAccessTokenPrefix + token),
+		Prefix:    models.PersonalAccessTokenPrefix,
+		Scopes:    []string{"finances:read"},
+		UserID:    user.ID,
+	}
+
+	if err := db.Create(&pat).Error; err != nil {
+		return models.PersonalAccessToken{}, err
+	}
+
+	return pat, nil
+}
+
+func SeedLedger(db *gorm.DB) (models.Ledger, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.Ledger{}, err
+	}
+
+	var ledger models.Ledger = models.Ledger{
+		Name:    "household",
+		Members: []models.LedgerMember{{UserID: user.ID, Role: models.LedgerRoleOwner}},
+	}
+
+	if err := db.Create(&ledger).Error; err != nil {
+		return models.Ledger{}, err
+	}
+
+	return ledger, nil
+}
+
+func SeedDebt(db *gorm.DB) (models.Debt, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.Debt{}, err
+	}
+
+	ledger, err := repositories.PersonalLedger(db, user.ID)
+	if err != nil {
+		return models.Debt{}, err
+	}
+
+	dueDate := time.Now().AddDate(0, 0, -1)
+
+	var debt models.Debt = models.Debt{
+		Counterparty: "budi",
+		Direction:    models.DebtDirectionReceivable,
+		Principal:    money.New(10000000, "IDR"),
+		DueDate:      &dueDate,
+		UserID:       user.ID,
+		LedgerID:     &ledger.ID,
+	}
+
+	if err := db.Create(&debt).Error; err != nil {
+		return models.Debt{}, err
+	}
+
+	return debt, nil
+}
+
+func SeedBill(db *gorm.DB) (models.Bill, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.Bill{}, err
+	}
+
+	category, err := SeedCategory(db)
+	if err != nil {
+		return models.Bill{}, err
+	}
+
+	ledger, err := repositories.PersonalLedger(db, user.ID)
+	if err != nil {
+		return models.Bill{}, err
+	}
+
+	var bill models.Bill = models.Bill{
+		Name:        "electricity",
+		Amount:      money.New(35000000, "IDR"),
+		DueDay:      20,
+		Recurrence:  models.BillRecurrenceMonthly,
+		LeadDays:    3,
+		NextDueDate: models.FirstDueDate(20, time.Now()),
+		Active:      true,
+		CategoryID:  category.ID,
+		UserID:      user.ID,
+		LedgerID:    &ledger.ID,
+	}
+
+	if err := db.Create(&bill).Error; err != nil {
+		return models.Bill{}, err
+	}
+
+	return bill, nil
+}
+
+func SeedNotification(db *gorm.DB) (models.Notification, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.Notification{}, err
+	}
+
+	var notification models.Notification = models.Notification{
+		UserID:  user.ID,
+		Type:    models.NotificationTypeBillReminder,
+		Title:   "electricity is due soon",
+		Message: "electricity of 350000 is due soon.",
+	}
+
+	if err := db.Create(&notification).Error; err != nil {
+		return models.Notification{}, err
+	}
+
+	return notification, nil
+}
+
+func SeedWebhook(db *gorm.DB, url string) (models.Webhook, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	var webhook models.Webhook = models.Webhook{
+		URL:    url,
+		Secret: "testsecret",
+		Events: []string{models.WebhookEventFinanceCreated},
+		Active: true,
+		UserID: user.ID,
+	}
+
+	if err := db.Create(&webhook).Error; err != nil {
+		return models.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func SeedGoldSaving(db *gorm.DB) (models.GoldSaving, error) {
+	user, err := SeedUser(db)
+	if err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	ledger, err := repositories.PersonalLedger(db, user.ID)
+	if err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	// 10 g of the 100 g goal, bought for 12.000.000 rupiah
+	var goldSaving models.GoldSaving = models.GoldSaving{
+		Name:     "emas",
+		Goal:     1000000,
+		Grams:    100000,
+		Cost:     money.New(1200000000, "IDR"),
+		UserID:   user.ID,
+		LedgerID: &ledger.ID,
+		Deposits: []models.GoldDeposit{
+			{
+				Grams:       100000,
+				Price:       money.New(1200000000, "IDR"),
+				PurchasedAt: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+				UserID:      user.ID,
+			},
+		},
+	}
+
+	if err := db.Create(&goldSaving).Error; err != nil {
+		return models.GoldSaving{}, err
+	}
+
+	return goldSaving, nil
+}
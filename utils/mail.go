@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"log/slog"
+	"net/smtp"
+	"time"
+)
+
+// Mailer sends plain text email over SMTP. When Host is empty the message
+// is only logged, without its body unless LogBody is set.
+type Mailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// LogBody logs the body of unsent messages, for development. Bodies
+	// carry verification and invitation links, they do not belong in the
+	// logs of a real deployment.
+	LogBody bool
+}
+
+func (m Mailer) Send(to, subject, body string) error {
+	if m.Host == "" {
+		attrs := []any{"to", to, "subject", subject}
+		if m.LogBody {
+			attrs = append(attrs, "body", body)
+		}
+
+		slog.Info("mail is not configured, the message is logged instead", attrs...)
+		return nil
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", m.From, to, subject, body)
+
+	addr := fmt.Sprintf("%s:%s", m.Host, m.Port)
+	auth := smtp.PlainAuth("", m.Username, m.Password, m.Host)
+
+	return smtp.SendMail(addr, auth, m.From, []string{to}, []byte(message))
+}
+
+// HumanDuration writes a duration the way it is read in an email, in whole
+// days, hours or minutes.
+func HumanDuration(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d >= time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/time.Minute), "minute")
+	}
+}